@@ -0,0 +1,79 @@
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// Bitcoin base58 and base58check encoding
+
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var radix = big.NewInt(58)
+
+// Encode leading zero bytes are encoded as '1'
+func Encode(in []byte) string {
+	x := new(big.Int).SetBytes(in)
+	var out []byte
+	mod := new(big.Int)
+	for x.Sign() > 0 {
+		x.DivMod(x, radix, mod)
+		out = append(out, alphabet[mod.Int64()])
+	}
+	for _, b := range in {
+		if b != 0 {
+			break
+		}
+		out = append(out, alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func Decode(in string) ([]byte, error) {
+	x := new(big.Int)
+	for i := 0; i < len(in); i++ {
+		v := bytes.IndexByte([]byte(alphabet), in[i])
+		if v < 0 {
+			return nil, fmt.Errorf("invalid base58 character")
+		}
+		x.Mul(x, radix)
+		x.Add(x, big.NewInt(int64(v)))
+	}
+	zeros := 0
+	for zeros < len(in) && in[zeros] == alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
+
+// CheckEncode base58(data | sha256d(data)[:4])
+func CheckEncode(data []byte) string {
+	return Encode(append(append([]byte{}, data...), checksum(data)...))
+}
+
+// CheckDecode verify and strip the checksum
+func CheckDecode(in string) ([]byte, error) {
+	decoded, err := Decode(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 4 {
+		return nil, fmt.Errorf("invalid base58check length")
+	}
+	data := decoded[:len(decoded)-4]
+	if !bytes.Equal(checksum(data), decoded[len(decoded)-4:]) {
+		return nil, fmt.Errorf("invalid base58check checksum")
+	}
+	return data, nil
+}
+
+func checksum(data []byte) []byte {
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
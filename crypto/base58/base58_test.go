@@ -0,0 +1,34 @@
+package base58
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase58(t *testing.T) {
+	vectors := map[string]string{
+		"":                     "",
+		"61":                   "2g",
+		"626262":               "a3gV",
+		"00000000000000000000": "1111111111",
+		"000111d38e5fc9071ffcd20b4a763cc9ae4f252bb4e48fd66a835e252ada93ff480d6dd43dc62a641155a5": "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz",
+	}
+	for in, out := range vectors {
+		data, _ := hex.DecodeString(in)
+		require.Equal(t, out, Encode(data))
+		decoded, err := Decode(out)
+		require.NoError(t, err)
+		require.Equal(t, in, hex.EncodeToString(decoded))
+	}
+
+	// p2pkh address of hash160 010966776006953d5567439e5e39f86a0d273bee
+	payload, _ := hex.DecodeString("00010966776006953d5567439e5e39f86a0d273bee")
+	require.Equal(t, "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM", CheckEncode(payload))
+	data, err := CheckDecode("16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM")
+	require.NoError(t, err)
+	require.Equal(t, payload, data)
+	_, err = CheckDecode("16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvN")
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package bls12381
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BLS signatures in the Ethereum consensus format: public key in G1 (48 bytes), signature in G2 (96 bytes),
+// proof of possession scheme of draft-irtf-cfrg-bls-signature-05.
+
+// DST domain separation tag of signing, used by Ethereum consensus
+const DST = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+
+// HashToPoint H(m) with the signing tag
+func HashToPoint(message []byte) (*G2Point, error) {
+	return HashToG2(message, []byte(DST))
+}
+
+// PublicKey sk*G1, compressed
+func PublicKey(sk *big.Int) ([]byte, error) {
+	if sk == nil || sk.Sign() <= 0 || sk.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("private key out of range")
+	}
+	x, y := g1.ScalarBaseMult(sk.Bytes())
+	return G1Compress(x, y), nil
+}
+
+// Sign sk*H(m), compressed
+func Sign(sk *big.Int, message []byte) ([]byte, error) {
+	if sk == nil || sk.Sign() <= 0 || sk.Cmp(order) >= 0 {
+		return nil, fmt.Errorf("private key out of range")
+	}
+	H, err := HashToPoint(message)
+	if err != nil {
+		return nil, err
+	}
+	return H.ScalarMult(sk).Bytes(), nil
+}
+
+// Verify e(pk, H(m)) == e(G1, sig)
+func Verify(publicKey, message, signature []byte) bool {
+	x, y, err := G1Decompress(publicKey)
+	if err != nil {
+		return false
+	}
+	sig, err := G2FromBytes(signature)
+	if err != nil {
+		return false
+	}
+	return VerifyPoint(&G1Point{x, y}, message, sig)
+}
+
+// VerifyPoint pk and sig must be checked in subgroup by caller
+func VerifyPoint(pk *G1Point, message []byte, sig *G2Point) bool {
+	H, err := HashToPoint(message)
+	if err != nil {
+		return false
+	}
+	negG := &G1Point{g1.params.Gx, new(big.Int).Sub(p, g1.params.Gy)}
+	return PairingCheck([]*G1Point{pk, negG}, []*G2Point{H, sig})
+}
@@ -0,0 +1,159 @@
+package bls12381
+
+import (
+	"encoding/hex"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// specDir layout of tests/general/phase0/bls of github.com/ethereum/consensus-spec-tests, BLS_SPEC_TESTS runs a full release
+func specDir() string {
+	if dir := os.Getenv("BLS_SPEC_TESTS"); dir != "" {
+		return dir
+	}
+	return filepath.Join("testdata", "bls")
+}
+
+// specCases data.yaml of every case of handler, release archives have one more bls directory
+func specCases(t *testing.T, handler string) map[string][]byte {
+	cases := make(map[string][]byte)
+	for _, pattern := range []string{"*/data.yaml", "bls/*/data.yaml"} {
+		files, err := filepath.Glob(filepath.Join(specDir(), handler, pattern))
+		require.NoError(t, err)
+		for _, file := range files {
+			bytes, err := os.ReadFile(file)
+			require.NoError(t, err)
+			cases[filepath.Base(filepath.Dir(file))] = bytes
+		}
+	}
+	return cases
+}
+
+func specHex(t *testing.T, s string) []byte {
+	bytes, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	require.NoError(t, err)
+	return bytes
+}
+
+func TestSpecSign(t *testing.T) {
+	cases := specCases(t, "sign")
+	require.NotEmpty(t, cases)
+	for name, data := range cases {
+		var test struct {
+			Input struct {
+				Privkey string `yaml:"privkey"`
+				Message string `yaml:"message"`
+			} `yaml:"input"`
+			Output *string `yaml:"output"`
+		}
+		require.NoError(t, yaml.Unmarshal(data, &test), name)
+		sk := new(big.Int).SetBytes(specHex(t, test.Input.Privkey))
+		signature, err := Sign(sk, specHex(t, test.Input.Message))
+		if test.Output == nil {
+			require.Error(t, err, name)
+			continue
+		}
+		require.NoError(t, err, name)
+		require.Equal(t, specHex(t, *test.Output), signature, name)
+	}
+}
+
+func TestSpecVerify(t *testing.T) {
+	for name, data := range specCases(t, "verify") {
+		var test struct {
+			Input struct {
+				Pubkey    string `yaml:"pubkey"`
+				Message   string `yaml:"message"`
+				Signature string `yaml:"signature"`
+			} `yaml:"input"`
+			Output bool `yaml:"output"`
+		}
+		require.NoError(t, yaml.Unmarshal(data, &test), name)
+		require.Equal(t, test.Output, Verify(specHex(t, test.Input.Pubkey), specHex(t, test.Input.Message), specHex(t, test.Input.Signature)), name)
+	}
+}
+
+func TestHashToG2(t *testing.T) {
+	// RFC 9380 appendix J.10.1, msg = ""
+	H, err := HashToG2([]byte(""), []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"))
+	require.NoError(t, err)
+	require.Equal(t, "141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a", H.p.X.A0.Text(16))
+	require.Equal(t, "5cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d", H.p.X.A1.Text(16))
+	require.Equal(t, "503921d7f6a12805e72940b963c0cf3471c7b2a524950ca195d11062ee75ec076daf2d4bc358c4b190c0c98064fdd92", H.p.Y.A0.Text(16))
+	require.Equal(t, "12424ac32561493f3fe3c260708a12b7c620e7be00099a974e259ddc7d1f6395c3c811cdd19f1e8dbf3e9ecfdcbab8d6", H.p.Y.A1.Text(16))
+	require.True(t, H.InSubgroup())
+}
+
+func TestSign(t *testing.T) {
+	// Ethereum consensus spec test sign_case_84d45c9c7cca6b92
+	sk, _ := new(big.Int).SetString("263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3", 16)
+	message := make([]byte, 32)
+	publicKey, err := PublicKey(sk)
+	require.NoError(t, err)
+	require.Equal(t, "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a", hex.EncodeToString(publicKey))
+	signature, err := Sign(sk, message)
+	require.NoError(t, err)
+
+	require.True(t, Verify(publicKey, message, signature))
+	message[0] = 1
+	require.False(t, Verify(publicKey, message, signature))
+	_, err = Sign(new(big.Int), message)
+	require.Error(t, err)
+
+	// infinity publicKey and signature are rejected
+	infinity := make([]byte, G2CompressedSize)
+	infinity[0] = 0xc0
+	require.False(t, Verify(infinity[:G1CompressedSize], message, infinity))
+}
+
+func TestPairing(t *testing.T) {
+	a, b := crypto.RandomNum(order), crypto.RandomNum(order)
+	ax, ay := g1.ScalarBaseMult(a.Bytes())
+	abx, aby := g1.ScalarBaseMult(new(big.Int).Mod(new(big.Int).Mul(a, b), order).Bytes())
+	G2 := G2Generator()
+	// e(a*G1, b*G2) * e(-ab*G1, G2) == 1
+	negAB := &G1Point{abx, new(big.Int).Sub(p, aby)}
+	require.True(t, PairingCheck([]*G1Point{{ax, ay}, negAB}, []*G2Point{G2.ScalarMult(b), G2}))
+	require.False(t, PairingCheck([]*G1Point{{ax, ay}, negAB}, []*G2Point{G2.ScalarMult(b), G2.Double()}))
+}
+
+func TestCurve(t *testing.T) {
+	k := crypto.RandomNum(order)
+	x, y := g1.ScalarBaseMult(k.Bytes())
+	require.True(t, g1.IsOnCurve(x, y))
+	// k*G + k*G = 2k*G, scalars are reduced mod r
+	x2, y2 := g1.Add(x, y, x, y)
+	x3, y3 := g1.Double(x, y)
+	x4, y4 := g1.ScalarBaseMult(new(big.Int).Add(new(big.Int).Lsh(k, 1), order).Bytes())
+	require.True(t, x2.Cmp(x3) == 0 && y2.Cmp(y3) == 0)
+	require.True(t, x2.Cmp(x4) == 0 && y2.Cmp(y4) == 0)
+	x5, y5 := g1.Add(x, y, x, new(big.Int).Sub(p, y))
+	require.True(t, x5.Sign() == 0 && y5.Sign() == 0)
+	require.False(t, g1.IsOnCurve(x5, y5))
+	// (0, 2) is on E1 but not in G1
+	require.False(t, g1.IsOnCurve(new(big.Int), big.NewInt(2)))
+}
+
+func TestEncoding(t *testing.T) {
+	k := crypto.RandomNum(order)
+	x, y := g1.ScalarBaseMult(k.Bytes())
+	x2, y2, err := G1Decompress(G1Compress(x, y))
+	require.NoError(t, err)
+	require.True(t, x.Cmp(x2) == 0 && y.Cmp(y2) == 0)
+
+	P := G2Generator().ScalarMult(k)
+	Q, err := G2FromBytes(P.Bytes())
+	require.NoError(t, err)
+	require.True(t, P.Equals(Q))
+	require.True(t, P.Neg().Equals(G2Generator().ScalarMult(new(big.Int).Neg(k))))
+
+	_, _, err = G1Decompress(G1Compress(new(big.Int), new(big.Int)))
+	require.Error(t, err)
+}
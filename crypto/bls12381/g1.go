@@ -0,0 +1,131 @@
+package bls12381
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+// G1 implements elliptic.Curve for the order r subgroup of E1 on top of gnark-crypto, so vss, schnorr and dkg work over G1 unchanged.
+// As in crypto/elliptic the point at infinity is (0, 0).
+
+var (
+	p     = fp.Modulus()
+	order = fr.Modulus()
+	g1    = newG1()
+)
+
+const G1CompressedSize = bls.SizeOfG1AffineCompressed
+
+type G1Curve struct {
+	params *elliptic.CurveParams
+}
+
+func newG1() *G1Curve {
+	_, _, gen, _ := bls.Generators()
+	Gx, Gy := g1Coordinates(&gen)
+	return &G1Curve{&elliptic.CurveParams{
+		P:       p,
+		N:       order,
+		B:       big.NewInt(4),
+		Gx:      Gx,
+		Gy:      Gy,
+		BitSize: 381,
+		Name:    "BLS12-381 G1",
+	}}
+}
+
+// G1 returns the BLS12-381 G1 curve
+func G1() *G1Curve {
+	return g1
+}
+
+// Order of G1 and G2
+func Order() *big.Int {
+	return new(big.Int).Set(order)
+}
+
+func (c *G1Curve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+// IsOnCurve y^2 = x^3 + 4 and the point is in G1, infinity is not on curve
+func (c *G1Curve) IsOnCurve(x, y *big.Int) bool {
+	if x == nil || y == nil || x.Sign() < 0 || y.Sign() < 0 || x.Cmp(p) >= 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+	a := g1Affine(x, y)
+	return !a.IsInfinity() && a.IsInSubGroup()
+}
+
+func (c *G1Curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	var r bls.G1Affine
+	r.Add(g1Affine(x1, y1), g1Affine(x2, y2))
+	return g1Coordinates(&r)
+}
+
+func (c *G1Curve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	var r bls.G1Affine
+	r.Double(g1Affine(x1, y1))
+	return g1Coordinates(&r)
+}
+
+// ScalarMult k is big endian
+func (c *G1Curve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	var r bls.G1Affine
+	r.ScalarMultiplication(g1Affine(x1, y1), scalar(new(big.Int).SetBytes(k)))
+	return g1Coordinates(&r)
+}
+
+func (c *G1Curve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	var r bls.G1Affine
+	r.ScalarMultiplicationBase(scalar(new(big.Int).SetBytes(k)))
+	return g1Coordinates(&r)
+}
+
+// InSubgroup r*P = O
+func (c *G1Curve) InSubgroup(x, y *big.Int) bool {
+	return c.IsOnCurve(x, y)
+}
+
+// scalar k mod r, points are in the order r subgroup
+func scalar(k *big.Int) *big.Int {
+	return new(big.Int).Mod(k, order)
+}
+
+func g1Affine(x, y *big.Int) *bls.G1Affine {
+	var a bls.G1Affine
+	a.X.SetBigInt(x)
+	a.Y.SetBigInt(y)
+	return &a
+}
+
+func g1Coordinates(a *bls.G1Affine) (*big.Int, *big.Int) {
+	return a.X.BigInt(new(big.Int)), a.Y.BigInt(new(big.Int))
+}
+
+// G1Compress 48 bytes zcash format: compression flag, infinity flag, y sign flag in the top 3 bits
+func G1Compress(x, y *big.Int) []byte {
+	out := g1Affine(x, y).Bytes()
+	return out[:]
+}
+
+// G1Decompress checks the point is in G1, infinity is rejected
+func G1Decompress(in []byte) (*big.Int, *big.Int, error) {
+	if len(in) != G1CompressedSize || in[0]&0x80 == 0 {
+		return nil, nil, fmt.Errorf("g1 encoding error")
+	}
+	var a bls.G1Affine
+	if _, err := a.SetBytes(in); err != nil {
+		return nil, nil, fmt.Errorf("g1 encoding error, %v", err)
+	}
+	if a.IsInfinity() {
+		return nil, nil, fmt.Errorf("g1 point is infinity")
+	}
+	x, y := g1Coordinates(&a)
+	return x, y, nil
+}
@@ -0,0 +1,94 @@
+package bls12381
+
+import (
+	"fmt"
+	"math/big"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+const G2CompressedSize = bls.SizeOfG2AffineCompressed
+
+// G2Point affine point on E2, the zero value is the point at infinity
+type G2Point struct {
+	p bls.G2Affine
+}
+
+// G2Generator returns the generator of G2
+func G2Generator() *G2Point {
+	_, _, _, gen := bls.Generators()
+	return &G2Point{gen}
+}
+
+func (a *G2Point) IsInfinity() bool {
+	return a.p.IsInfinity()
+}
+
+// IsOnCurve y^2 = x^3 + 4(1+i)
+func (a *G2Point) IsOnCurve() bool {
+	return a.p.IsOnCurve()
+}
+
+func (a *G2Point) Equals(b *G2Point) bool {
+	return a.p.Equal(&b.p)
+}
+
+func (a *G2Point) Neg() *G2Point {
+	r := new(G2Point)
+	r.p.Neg(&a.p)
+	return r
+}
+
+func (a *G2Point) Add(b *G2Point) *G2Point {
+	r := new(G2Point)
+	r.p.Add(&a.p, &b.p)
+	return r
+}
+
+func (a *G2Point) Double() *G2Point {
+	r := new(G2Point)
+	r.p.Double(&a.p)
+	return r
+}
+
+// ScalarMult k may be negative
+func (a *G2Point) ScalarMult(k *big.Int) *G2Point {
+	r := new(G2Point)
+	r.p.ScalarMultiplication(&a.p, scalar(k))
+	return r
+}
+
+// InSubgroup r*P = O
+func (a *G2Point) InSubgroup() bool {
+	return a.p.IsInSubGroup()
+}
+
+// Bytes 96 bytes zcash format x.c1 | x.c0, flags in the top 3 bits as G1
+func (a *G2Point) Bytes() []byte {
+	out := a.p.Bytes()
+	return out[:]
+}
+
+// G2FromBytes checks the point is in G2, infinity is rejected
+func G2FromBytes(in []byte) (*G2Point, error) {
+	if len(in) != G2CompressedSize || in[0]&0x80 == 0 {
+		return nil, fmt.Errorf("g2 encoding error")
+	}
+	point := new(G2Point)
+	if _, err := point.p.SetBytes(in); err != nil {
+		return nil, fmt.Errorf("g2 encoding error, %v", err)
+	}
+	if point.IsInfinity() {
+		return nil, fmt.Errorf("g2 point is infinity")
+	}
+	return point, nil
+}
+
+// HashToG2 BLS12381G2_XMD:SHA-256_SSWU_RO_ of RFC 9380, msg with domain separation tag dst
+func HashToG2(msg, dst []byte) (*G2Point, error) {
+	H, err := bls.HashToG2(msg, dst)
+	if err != nil {
+		return nil, err
+	}
+	return &G2Point{H}, nil
+}
@@ -0,0 +1,27 @@
+package bls12381
+
+import (
+	"math/big"
+
+	bls "github.com/consensys/gnark-crypto/ecc/bls12-381"
+)
+
+// G1Point affine point of G1, (0, 0) is infinity
+type G1Point struct {
+	X, Y *big.Int
+}
+
+// PairingCheck prod e(P_i, Q_i) == 1, optimal ate pairing of gnark-crypto
+func PairingCheck(P []*G1Point, Q []*G2Point) bool {
+	if len(P) != len(Q) {
+		return false
+	}
+	ps := make([]bls.G1Affine, len(P))
+	qs := make([]bls.G2Affine, len(Q))
+	for i := range P {
+		ps[i] = *g1Affine(P[i].X, P[i].Y)
+		qs[i] = Q[i].p
+	}
+	ok, err := bls.PairingCheck(ps, qs)
+	return err == nil && ok
+}
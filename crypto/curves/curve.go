@@ -6,20 +6,25 @@ import (
 
 	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/bls12381"
 )
 
 const (
 	Secp256k1 string = "secp256k1"
 	Ed25519   string = "ed25519"
+	BLS12381  string = "bls12381"
+	P256      string = "p256"
 )
 
 var curveMap map[string]elliptic.Curve
 
-// only support ecdsa、ed25519
+// only support ecdsa on secp256k1 and P-256、ed25519、bls12-381 G1
 func init() {
 	curveMap = map[string]elliptic.Curve{
 		Secp256k1: secp256k1.S256(),
 		Ed25519:   edwards.Edwards(),
+		BLS12381:  bls12381.G1(),
+		P256:      elliptic.P256(),
 	}
 }
 
@@ -0,0 +1,157 @@
+package garble
+
+import (
+	"fmt"
+)
+
+// Boolean circuits for 2-party secure computation with garbled circuits.
+// The Builder folds constants, so gates never take Zero or One as input, and XOR/INV are free when garbled.
+
+// Wire index of a circuit wire, Zero and One are constants
+type Wire int
+
+const (
+	Zero Wire = -1
+	One  Wire = -2
+)
+
+type GateType byte
+
+const (
+	XOR GateType = iota
+	AND
+	INV
+)
+
+type Gate struct {
+	Type GateType
+	A, B Wire
+	Out  Wire
+}
+
+// Circuit wires [0, GarblerInputs) are garbler inputs, the next EvaluatorInputs wires are evaluator inputs
+type Circuit struct {
+	GarblerInputs   int
+	EvaluatorInputs int
+	NumWires        int
+	Gates           []Gate
+	Outputs         []Wire
+	ANDs            int
+}
+
+type Builder struct {
+	c *Circuit
+}
+
+func NewBuilder(garblerInputs, evaluatorInputs int) *Builder {
+	return &Builder{&Circuit{
+		GarblerInputs:   garblerInputs,
+		EvaluatorInputs: evaluatorInputs,
+		NumWires:        garblerInputs + evaluatorInputs,
+	}}
+}
+
+func (b *Builder) GarblerInput(i int) Wire {
+	if i < 0 || i >= b.c.GarblerInputs {
+		panic(fmt.Errorf("garbler input out of range"))
+	}
+	return Wire(i)
+}
+
+func (b *Builder) EvaluatorInput(i int) Wire {
+	if i < 0 || i >= b.c.EvaluatorInputs {
+		panic(fmt.Errorf("evaluator input out of range"))
+	}
+	return Wire(b.c.GarblerInputs + i)
+}
+
+func (b *Builder) gate(t GateType, x, y Wire) Wire {
+	out := Wire(b.c.NumWires)
+	b.c.NumWires++
+	b.c.Gates = append(b.c.Gates, Gate{Type: t, A: x, B: y, Out: out})
+	if t == AND {
+		b.c.ANDs++
+	}
+	return out
+}
+
+func (b *Builder) Xor(x, y Wire) Wire {
+	switch {
+	case x == Zero:
+		return y
+	case y == Zero:
+		return x
+	case x == One:
+		return b.Not(y)
+	case y == One:
+		return b.Not(x)
+	case x == y:
+		return Zero
+	}
+	return b.gate(XOR, x, y)
+}
+
+func (b *Builder) And(x, y Wire) Wire {
+	switch {
+	case x == Zero || y == Zero:
+		return Zero
+	case x == One:
+		return y
+	case y == One:
+		return x
+	case x == y:
+		return x
+	}
+	return b.gate(AND, x, y)
+}
+
+func (b *Builder) Not(x Wire) Wire {
+	switch x {
+	case Zero:
+		return One
+	case One:
+		return Zero
+	}
+	return b.gate(INV, x, x)
+}
+
+// Circuit finish with outputs, the builder must not be used after
+func (b *Builder) Circuit(outputs []Wire) *Circuit {
+	b.c.Outputs = append([]Wire{}, outputs...)
+	return b.c
+}
+
+// Eval plaintext evaluation, for tests and reference
+func (c *Circuit) Eval(garblerIn, evaluatorIn []bool) ([]bool, error) {
+	if len(garblerIn) != c.GarblerInputs || len(evaluatorIn) != c.EvaluatorInputs {
+		return nil, fmt.Errorf("circuit input length error")
+	}
+	values := make([]bool, c.NumWires)
+	copy(values, garblerIn)
+	copy(values[c.GarblerInputs:], evaluatorIn)
+	for _, g := range c.Gates {
+		switch g.Type {
+		case XOR:
+			values[g.Out] = values[g.A] != values[g.B]
+		case AND:
+			values[g.Out] = values[g.A] && values[g.B]
+		case INV:
+			values[g.Out] = !values[g.A]
+		}
+	}
+	out := make([]bool, len(c.Outputs))
+	for i, w := range c.Outputs {
+		out[i] = wireValue(values, w)
+	}
+	return out, nil
+}
+
+func wireValue(values []bool, w Wire) bool {
+	switch w {
+	case Zero:
+		return false
+	case One:
+		return true
+	}
+	return values[w]
+}
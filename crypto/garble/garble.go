@@ -0,0 +1,243 @@
+package garble
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// Yao garbled circuits with free-XOR and half-gates (https://eprint.iacr.org/2014/756).
+// Wire w carries label L0 for 0 and L0 ^ R for 1, lsb(R) = 1 is the point-and-permute bit.
+// An AND gate costs two ciphertexts, XOR and INV are free.
+// A single garbling only protects against a semi-honest garbler, GarbleSeed derives the garbling from a seed
+// so that a cut-and-choose protocol can open and check some circuits and evaluate the others.
+
+const (
+	LabelSize = 16
+	SeedSize  = 32
+)
+
+type Label [LabelSize]byte
+
+// Xor of two labels
+func (l Label) Xor(o Label) Label {
+	var out Label
+	for i := range out {
+		out[i] = l[i] ^ o[i]
+	}
+	return out
+}
+
+func (l Label) lsb() bool {
+	return l[0]&1 == 1
+}
+
+// fixedKey public AES permutation pi of the label hash
+var fixedKey = func() cipher.Block {
+	key := sha256.Sum256([]byte("threshold-lib garble fixed key"))
+	block, err := aes.NewCipher(key[:LabelSize])
+	if err != nil {
+		panic(err)
+	}
+	return block
+}()
+
+// hashLabel H(L, tweak) = pi(pi(L) ^ tweak) ^ pi(L), tweakable correlation robust hash of Guo-Katz-Wang-Yu from fixed-key AES
+func hashLabel(l Label, tweak uint64) Label {
+	var x, y Label
+	fixedKey.Encrypt(x[:], l[:])
+	y = x
+	binary.BigEndian.PutUint64(y[8:], binary.BigEndian.Uint64(y[8:])^tweak)
+	fixedKey.Encrypt(y[:], y[:])
+	return x.Xor(y)
+}
+
+// labelStream AES-CTR keyed by SHA256(seed), source of the labels of a seeded garbling
+type labelStream struct {
+	stream cipher.Stream
+}
+
+func newLabelStream(seed []byte) (*labelStream, error) {
+	key := sha256.Sum256(seed)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return &labelStream{stream: cipher.NewCTR(block, make([]byte, aes.BlockSize))}, nil
+}
+
+func (s *labelStream) next() Label {
+	var l Label
+	s.stream.XORKeyStream(l[:], l[:])
+	return l
+}
+
+// GarbledCircuit sent to the evaluator, Tables holds 2 ciphertexts per AND gate in gate order,
+// Decode is lsb of the zero label of every output wire
+type GarbledCircuit struct {
+	Tables []byte
+	Decode []bool
+}
+
+// Garbler keeps the wire labels, never sent
+type Garbler struct {
+	c     *Circuit
+	r     Label
+	zeros []Label
+}
+
+// Garble the circuit with fresh labels
+func Garble(c *Circuit) (*Garbler, *GarbledCircuit, error) {
+	seed := make([]byte, SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, nil, err
+	}
+	return GarbleSeed(c, seed)
+}
+
+// GarbleSeed the circuit with labels derived from seed, the same seed gives the same garbling,
+// revealing the seed opens the circuit for checking
+func GarbleSeed(c *Circuit, seed []byte) (*Garbler, *GarbledCircuit, error) {
+	if len(seed) != SeedSize {
+		return nil, nil, fmt.Errorf("garbling seed length error")
+	}
+	labels, err := newLabelStream(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	r := labels.next()
+	r[0] |= 1
+	zeros := make([]Label, c.NumWires)
+	for i := 0; i < c.GarblerInputs+c.EvaluatorInputs; i++ {
+		zeros[i] = labels.next()
+	}
+	tables := make([]byte, 0, 2*LabelSize*c.ANDs)
+	var j uint64
+	for _, g := range c.Gates {
+		switch g.Type {
+		case XOR:
+			zeros[g.Out] = zeros[g.A].Xor(zeros[g.B])
+		case INV:
+			zeros[g.Out] = zeros[g.A].Xor(r)
+		case AND:
+			a0, b0 := zeros[g.A], zeros[g.B]
+			a1, b1 := a0.Xor(r), b0.Xor(r)
+			pa, pb := a0.lsb(), b0.lsb()
+			// garbler half gate
+			ha0, ha1 := hashLabel(a0, 2*j), hashLabel(a1, 2*j)
+			tg := ha0.Xor(ha1)
+			if pb {
+				tg = tg.Xor(r)
+			}
+			wg := ha0
+			if pa {
+				wg = wg.Xor(tg)
+			}
+			// evaluator half gate
+			hb0, hb1 := hashLabel(b0, 2*j+1), hashLabel(b1, 2*j+1)
+			te := hb0.Xor(hb1).Xor(a0)
+			we := hb0
+			if pb {
+				we = we.Xor(te.Xor(a0))
+			}
+			zeros[g.Out] = wg.Xor(we)
+			tables = append(tables, tg[:]...)
+			tables = append(tables, te[:]...)
+			j++
+		}
+	}
+	decode := make([]bool, len(c.Outputs))
+	for i, w := range c.Outputs {
+		if w >= 0 {
+			decode[i] = zeros[w].lsb()
+		}
+	}
+	return &Garbler{c: c, r: r, zeros: zeros}, &GarbledCircuit{Tables: tables, Decode: decode}, nil
+}
+
+func (g *Garbler) label(w Wire, bit bool) Label {
+	if bit {
+		return g.zeros[w].Xor(g.r)
+	}
+	return g.zeros[w]
+}
+
+// GarblerLabels labels of the garbler input bits, sent to the evaluator
+func (g *Garbler) GarblerLabels(bits []bool) ([]Label, error) {
+	if len(bits) != g.c.GarblerInputs {
+		return nil, fmt.Errorf("garbler input length error")
+	}
+	labels := make([]Label, len(bits))
+	for i, bit := range bits {
+		labels[i] = g.label(Wire(i), bit)
+	}
+	return labels, nil
+}
+
+// GarblerLabelPairs labels for 0 and 1 of every garbler input
+func (g *Garbler) GarblerLabelPairs() [][2]Label {
+	return g.labelPairs(0, g.c.GarblerInputs)
+}
+
+// EvaluatorLabelPairs labels for 0 and 1 of every evaluator input, transferred by oblivious transfer
+func (g *Garbler) EvaluatorLabelPairs() [][2]Label {
+	return g.labelPairs(g.c.GarblerInputs, g.c.EvaluatorInputs)
+}
+
+func (g *Garbler) labelPairs(first, n int) [][2]Label {
+	pairs := make([][2]Label, n)
+	for i := range pairs {
+		w := Wire(first + i)
+		pairs[i] = [2]Label{g.label(w, false), g.label(w, true)}
+	}
+	return pairs
+}
+
+// Evaluate the garbled circuit, return the decoded output, output labels stay with the evaluator
+func Evaluate(c *Circuit, gc *GarbledCircuit, garblerLabels, evaluatorLabels []Label) ([]bool, error) {
+	if len(garblerLabels) != c.GarblerInputs || len(evaluatorLabels) != c.EvaluatorInputs {
+		return nil, fmt.Errorf("input labels length error")
+	}
+	if gc == nil || len(gc.Tables) != 2*LabelSize*c.ANDs || len(gc.Decode) != len(c.Outputs) {
+		return nil, fmt.Errorf("garbled circuit size error")
+	}
+	labels := make([]Label, c.NumWires)
+	copy(labels, garblerLabels)
+	copy(labels[c.GarblerInputs:], evaluatorLabels)
+	var j uint64
+	for _, g := range c.Gates {
+		switch g.Type {
+		case XOR:
+			labels[g.Out] = labels[g.A].Xor(labels[g.B])
+		case INV:
+			labels[g.Out] = labels[g.A]
+		case AND:
+			var tg, te Label
+			copy(tg[:], gc.Tables[2*LabelSize*j:])
+			copy(te[:], gc.Tables[2*LabelSize*j+LabelSize:])
+			a, b := labels[g.A], labels[g.B]
+			wg := hashLabel(a, 2*j)
+			if a.lsb() {
+				wg = wg.Xor(tg)
+			}
+			we := hashLabel(b, 2*j+1)
+			if b.lsb() {
+				we = we.Xor(te.Xor(a))
+			}
+			labels[g.Out] = wg.Xor(we)
+			j++
+		}
+	}
+	out := make([]bool, len(c.Outputs))
+	for i, w := range c.Outputs {
+		if w < 0 {
+			out[i] = w == One
+			continue
+		}
+		out[i] = labels[w].lsb() != gc.Decode[i]
+	}
+	return out, nil
+}
@@ -0,0 +1,146 @@
+package garble
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func bits(v *big.Int, n int) []bool {
+	out := make([]bool, n)
+	for i := range out {
+		out[i] = v.Bit(i) == 1
+	}
+	return out
+}
+
+func toInt(bits []bool) *big.Int {
+	v := new(big.Int)
+	for i, bit := range bits {
+		if bit {
+			v.SetBit(v, i, 1)
+		}
+	}
+	return v
+}
+
+// addModCircuit x + y mod N
+func addModCircuit(N *big.Int) *Circuit {
+	n := N.BitLen()
+	b := NewBuilder(n, n)
+	x, y := make(Word, n+1), make(Word, n+1)
+	for i := 0; i < n; i++ {
+		x[i], y[i] = b.GarblerInput(i), b.EvaluatorInput(i)
+	}
+	x[n], y[n] = Zero, Zero
+	sum := b.AddWord(x, y)
+	diff, borrow := b.SubBorrow(sum, ConstBigWord(N, n+1))
+	return b.Circuit(b.Mux(borrow, diff, sum)[:n])
+}
+
+func TestGarbleAddMod(t *testing.T) {
+	N := secp256k1.S256().N
+	c := addModCircuit(N)
+	for i := 0; i < 10; i++ {
+		x, y := crypto.RandomNum(N), crypto.RandomNum(N)
+		expected := new(big.Int).Mod(new(big.Int).Add(x, y), N)
+
+		plain, err := c.Eval(bits(x, 256), bits(y, 256))
+		require.NoError(t, err)
+		require.Equal(t, 0, expected.Cmp(toInt(plain)))
+
+		garbler, gc, err := Garble(c)
+		require.NoError(t, err)
+		garblerLabels, err := garbler.GarblerLabels(bits(x, 256))
+		require.NoError(t, err)
+		pairs := garbler.EvaluatorLabelPairs()
+		evaluatorLabels := make([]Label, len(pairs))
+		for j, bit := range bits(y, 256) {
+			if bit {
+				evaluatorLabels[j] = pairs[j][1]
+			} else {
+				evaluatorLabels[j] = pairs[j][0]
+			}
+		}
+		out, err := Evaluate(c, gc, garblerLabels, evaluatorLabels)
+		require.NoError(t, err)
+		require.Equal(t, 0, expected.Cmp(toInt(out)))
+	}
+}
+
+func TestGarbleSeed(t *testing.T) {
+	c := addModCircuit(secp256k1.S256().N)
+	seed := make([]byte, SeedSize)
+	seed[0] = 1
+	garbler1, gc1, err := GarbleSeed(c, seed)
+	require.NoError(t, err)
+	garbler2, gc2, err := GarbleSeed(c, seed)
+	require.NoError(t, err)
+	require.Equal(t, gc1, gc2)
+	require.Equal(t, garbler1.GarblerLabelPairs(), garbler2.GarblerLabelPairs())
+	require.Equal(t, garbler1.EvaluatorLabelPairs(), garbler2.EvaluatorLabelPairs())
+
+	seed[0] = 2
+	_, gc3, err := GarbleSeed(c, seed)
+	require.NoError(t, err)
+	require.NotEqual(t, gc1.Tables, gc3.Tables)
+	_, _, err = GarbleSeed(c, seed[1:])
+	require.Error(t, err)
+}
+
+func TestSHA512Compress(t *testing.T) {
+	// sha512("abc"), single padded block
+	block := make([]byte, 128)
+	copy(block, "abc")
+	block[3] = 0x80
+	block[127] = 24
+	state := SHA512Compress(SHA512IV, block)
+	sum := sha512.Sum512([]byte("abc"))
+	for i := range state {
+		require.Equal(t, binary.BigEndian.Uint64(sum[8*i:]), state[i])
+	}
+
+	// message block as evaluator input, state as constant
+	b := NewBuilder(0, 1024)
+	var words [16]Word
+	for t := range words {
+		words[t] = make(Word, 64)
+		for i := 0; i < 64; i++ {
+			words[t][i] = b.EvaluatorInput(64*t + i)
+		}
+	}
+	var iv [8]Word
+	for i, v := range SHA512IV {
+		iv[i] = ConstWord(v, 64)
+	}
+	out := b.SHA512Compress(iv, words)
+	var outputs []Wire
+	for _, w := range out {
+		outputs = append(outputs, w...)
+	}
+	c := b.Circuit(outputs)
+
+	in := make([]bool, 1024)
+	for t := 0; t < 16; t++ {
+		v := binary.BigEndian.Uint64(block[8*t:])
+		for i := 0; i < 64; i++ {
+			in[64*t+i] = (v>>uint(i))&1 == 1
+		}
+	}
+	result, err := c.Eval(nil, in)
+	require.NoError(t, err)
+	for i := range state {
+		var v uint64
+		for j := 0; j < 64; j++ {
+			if result[64*i+j] {
+				v |= 1 << uint(j)
+			}
+		}
+		require.Equal(t, state[i], v)
+	}
+}
@@ -0,0 +1,87 @@
+package garble
+
+import (
+	"encoding/binary"
+)
+
+// SHA-512 compression, plain for public blocks and as a circuit for secret blocks
+
+var sha512K = [80]uint64{
+	0x428a2f98d728ae22, 0x7137449123ef65cd, 0xb5c0fbcfec4d3b2f, 0xe9b5dba58189dbbc,
+	0x3956c25bf348b538, 0x59f111f1b605d019, 0x923f82a4af194f9b, 0xab1c5ed5da6d8118,
+	0xd807aa98a3030242, 0x12835b0145706fbe, 0x243185be4ee4b28c, 0x550c7dc3d5ffb4e2,
+	0x72be5d74f27b896f, 0x80deb1fe3b1696b1, 0x9bdc06a725c71235, 0xc19bf174cf692694,
+	0xe49b69c19ef14ad2, 0xefbe4786384f25e3, 0x0fc19dc68b8cd5b5, 0x240ca1cc77ac9c65,
+	0x2de92c6f592b0275, 0x4a7484aa6ea6e483, 0x5cb0a9dcbd41fbd4, 0x76f988da831153b5,
+	0x983e5152ee66dfab, 0xa831c66d2db43210, 0xb00327c898fb213f, 0xbf597fc7beef0ee4,
+	0xc6e00bf33da88fc2, 0xd5a79147930aa725, 0x06ca6351e003826f, 0x142929670a0e6e70,
+	0x27b70a8546d22ffc, 0x2e1b21385c26c926, 0x4d2c6dfc5ac42aed, 0x53380d139d95b3df,
+	0x650a73548baf63de, 0x766a0abb3c77b2a8, 0x81c2c92e47edaee6, 0x92722c851482353b,
+	0xa2bfe8a14cf10364, 0xa81a664bbc423001, 0xc24b8b70d0f89791, 0xc76c51a30654be30,
+	0xd192e819d6ef5218, 0xd69906245565a910, 0xf40e35855771202a, 0x106aa07032bbd1b8,
+	0x19a4c116b8d2d0c8, 0x1e376c085141ab53, 0x2748774cdf8eeb99, 0x34b0bcb5e19b48a8,
+	0x391c0cb3c5c95a63, 0x4ed8aa4ae3418acb, 0x5b9cca4f7763e373, 0x682e6ff3d6b2b8a3,
+	0x748f82ee5defb2fc, 0x78a5636f43172f60, 0x84c87814a1f0ab72, 0x8cc702081a6439ec,
+	0x90befffa23631e28, 0xa4506cebde82bde9, 0xbef9a3f7b2c67915, 0xc67178f2e372532b,
+	0xca273eceea26619c, 0xd186b8c721c0c207, 0xeada7dd6cde0eb1e, 0xf57d4f7fee6ed178,
+	0x06f067aa72176fba, 0x0a637dc5a2c898a6, 0x113f9804bef90dae, 0x1b710b35131c471b,
+	0x28db77f523047d84, 0x32caab7b40c72493, 0x3c9ebe0a15c9bebc, 0x431d67c49c100d4c,
+	0x4cc5d4becb3e42b6, 0x597f299cfc657e2a, 0x5fcb6fab3ad6faec, 0x6c44198c4a475817,
+}
+
+// SHA512IV initial hash value
+var SHA512IV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+func rotr(x uint64, k uint) uint64 {
+	return x>>k | x<<(64-k)
+}
+
+// SHA512Compress plain compression of a 128 bytes block
+func SHA512Compress(state [8]uint64, block []byte) [8]uint64 {
+	var w [80]uint64
+	for t := 0; t < 16; t++ {
+		w[t] = binary.BigEndian.Uint64(block[8*t:])
+	}
+	for t := 16; t < 80; t++ {
+		s0 := rotr(w[t-15], 1) ^ rotr(w[t-15], 8) ^ w[t-15]>>7
+		s1 := rotr(w[t-2], 19) ^ rotr(w[t-2], 61) ^ w[t-2]>>6
+		w[t] = s1 + w[t-7] + s0 + w[t-16]
+	}
+	a, b, c, d, e, f, g, h := state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7]
+	for t := 0; t < 80; t++ {
+		t1 := h + (rotr(e, 14) ^ rotr(e, 18) ^ rotr(e, 41)) + (e&f ^ ^e&g) + sha512K[t] + w[t]
+		t2 := (rotr(a, 28) ^ rotr(a, 34) ^ rotr(a, 39)) + (a&b ^ a&c ^ b&c)
+		h, g, f, e, d, c, b, a = g, f, e, d+t1, c, b, a, t1+t2
+	}
+	return [8]uint64{state[0] + a, state[1] + b, state[2] + c, state[3] + d, state[4] + e, state[5] + f, state[6] + g, state[7] + h}
+}
+
+// SHA512Compress circuit of the compression, state and block words are 64 bits
+func (b *Builder) SHA512Compress(state [8]Word, block [16]Word) [8]Word {
+	var w [80]Word
+	copy(w[:], block[:])
+	for t := 16; t < 80; t++ {
+		s0 := b.XorWord(b.XorWord(RotR(w[t-15], 1), RotR(w[t-15], 8)), ShR(w[t-15], 7))
+		s1 := b.XorWord(b.XorWord(RotR(w[t-2], 19), RotR(w[t-2], 61)), ShR(w[t-2], 6))
+		w[t] = b.AddWord(b.AddWord(s1, w[t-7]), b.AddWord(s0, w[t-16]))
+	}
+	a, bb, c, d, e, f, g, h := state[0], state[1], state[2], state[3], state[4], state[5], state[6], state[7]
+	for t := 0; t < 80; t++ {
+		sigma1 := b.XorWord(b.XorWord(RotR(e, 14), RotR(e, 18)), RotR(e, 41))
+		// ch = g ^ (e & (f ^ g))
+		ch := b.XorWord(g, b.AndWord(e, b.XorWord(f, g)))
+		t1 := b.AddWord(b.AddWord(h, sigma1), b.AddWord(ch, b.AddWord(ConstWord(sha512K[t], 64), w[t])))
+		sigma0 := b.XorWord(b.XorWord(RotR(a, 28), RotR(a, 34)), RotR(a, 39))
+		// maj = a ^ ((a ^ b) & (a ^ c))
+		maj := b.XorWord(a, b.AndWord(b.XorWord(a, bb), b.XorWord(a, c)))
+		t2 := b.AddWord(sigma0, maj)
+		h, g, f, e, d, c, bb, a = g, f, e, b.AddWord(d, t1), c, bb, a, b.AddWord(t1, t2)
+	}
+	return [8]Word{
+		b.AddWord(state[0], a), b.AddWord(state[1], bb), b.AddWord(state[2], c), b.AddWord(state[3], d),
+		b.AddWord(state[4], e), b.AddWord(state[5], f), b.AddWord(state[6], g), b.AddWord(state[7], h),
+	}
+}
@@ -0,0 +1,118 @@
+package garble
+
+import (
+	"math/big"
+)
+
+// Word little endian bits, Word[i] is the bit of 2^i
+type Word []Wire
+
+// ConstWord n low bits of v
+func ConstWord(v uint64, n int) Word {
+	w := make(Word, n)
+	for i := range w {
+		w[i] = Zero
+		if i < 64 && (v>>uint(i))&1 == 1 {
+			w[i] = One
+		}
+	}
+	return w
+}
+
+// ConstBigWord n low bits of v
+func ConstBigWord(v *big.Int, n int) Word {
+	w := make(Word, n)
+	for i := range w {
+		w[i] = Zero
+		if v.Bit(i) == 1 {
+			w[i] = One
+		}
+	}
+	return w
+}
+
+func (b *Builder) XorWord(x, y Word) Word {
+	out := make(Word, len(x))
+	for i := range x {
+		out[i] = b.Xor(x[i], y[i])
+	}
+	return out
+}
+
+func (b *Builder) AndWord(x, y Word) Word {
+	out := make(Word, len(x))
+	for i := range x {
+		out[i] = b.And(x[i], y[i])
+	}
+	return out
+}
+
+func (b *Builder) NotWord(x Word) Word {
+	out := make(Word, len(x))
+	for i := range x {
+		out[i] = b.Not(x[i])
+	}
+	return out
+}
+
+// AddWord x + y mod 2^n
+func (b *Builder) AddWord(x, y Word) Word {
+	sum, _ := b.addCarry(x, y, Zero, false)
+	return sum
+}
+
+// AddCarry x + y + c, return sum and carry out
+func (b *Builder) AddCarry(x, y Word, c Wire) (Word, Wire) {
+	return b.addCarry(x, y, c, true)
+}
+
+// addCarry ripple carry adder, carry = ((x^c) & (y^c)) ^ c costs one AND per bit
+func (b *Builder) addCarry(x, y Word, c Wire, carryOut bool) (Word, Wire) {
+	out := make(Word, len(x))
+	for i := range x {
+		xc := b.Xor(x[i], c)
+		out[i] = b.Xor(xc, y[i])
+		if i == len(x)-1 && !carryOut {
+			break
+		}
+		c = b.Xor(b.And(xc, b.Xor(y[i], c)), c)
+	}
+	return out, c
+}
+
+// SubBorrow x - y mod 2^n, borrow is 1 if x < y
+func (b *Builder) SubBorrow(x, y Word) (Word, Wire) {
+	diff, carry := b.AddCarry(x, b.NotWord(y), One)
+	return diff, b.Not(carry)
+}
+
+// Mux sel ? y : x
+func (b *Builder) Mux(sel Wire, x, y Word) Word {
+	out := make(Word, len(x))
+	for i := range x {
+		out[i] = b.Xor(x[i], b.And(sel, b.Xor(x[i], y[i])))
+	}
+	return out
+}
+
+// RotR rotate right by k
+func RotR(x Word, k int) Word {
+	n := len(x)
+	out := make(Word, n)
+	for i := range out {
+		out[i] = x[(i+k)%n]
+	}
+	return out
+}
+
+// ShR shift right by k
+func ShR(x Word, k int) Word {
+	out := make(Word, len(x))
+	for i := range out {
+		out[i] = Zero
+		if i+k < len(x) {
+			out[i] = x[i+k]
+		}
+	}
+	return out
+}
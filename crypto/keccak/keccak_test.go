@@ -0,0 +1,17 @@
+package keccak
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum256(t *testing.T) {
+	require.Equal(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(Sum256(nil)))
+	require.Equal(t, "4e03657aea45a94fc7d47ba826c8d667c0d1e6e33a64a036ec44f58fa12d6c45", hex.EncodeToString(Sum256([]byte("abc"))))
+	// multi block input equals the split input
+	long := []byte(strings.Repeat("a", 300))
+	require.Equal(t, Sum256(long), Sum256(long[:100], long[100:]))
+}
@@ -0,0 +1,135 @@
+package ot
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// Batched 1-out-of-2 oblivious transfer, simplest OT of Chou and Orlandi https://eprint.iacr.org/2015/267.
+// Messages are at most 32 bytes, e.g. garbled circuit labels or keys.
+// The choice bits are hidden from any sender, the receiver learns one message per transfer in the random oracle model.
+// A malicious sender can still transfer wrong messages, the caller must check them, e.g. against committed garblings.
+// Sender: A = a*G. Receiver with choice c: Bj = bj*G + c*A, key H(j, bj*A).
+// Sender keys: k0 = H(j, a*Bj), k1 = H(j, a*(Bj - A)), only kc is known to the receiver.
+
+var (
+	curve = secp256k1.S256()
+)
+
+const MaxMessageSize = sha256.Size
+
+type Sender struct {
+	a *big.Int
+	A *curves.ECPoint
+	n int
+}
+
+type Receiver struct {
+	A       *curves.ECPoint
+	choices []bool
+	b       []*big.Int
+}
+
+// NewSender n transfers, A is sent to the receiver
+func NewSender(n int) (*Sender, *curves.ECPoint) {
+	a := crypto.RandomNum(curve.N)
+	A := curves.ScalarToPoint(curve, a)
+	return &Sender{a: a, A: A, n: n}, A
+}
+
+// NewReceiver choice bits, B is sent to the sender
+func NewReceiver(A *curves.ECPoint, choices []bool) (*Receiver, []*curves.ECPoint, error) {
+	if A == nil || A.Curve != curve || !A.IsOnCurve() {
+		return nil, nil, fmt.Errorf("ot sender point error")
+	}
+	b := make([]*big.Int, len(choices))
+	B := make([]*curves.ECPoint, len(choices))
+	for j, c := range choices {
+		b[j] = crypto.RandomNum(curve.N)
+		B[j] = curves.ScalarToPoint(curve, b[j])
+		if c {
+			var err error
+			B[j], err = B[j].Add(A)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return &Receiver{A: A, choices: choices, b: b}, B, nil
+}
+
+// Encrypt messages m0[j], m1[j] under the keys of Bj
+func (s *Sender) Encrypt(B []*curves.ECPoint, m0, m1 [][]byte) ([][2][]byte, error) {
+	if len(B) != s.n || len(m0) != s.n || len(m1) != s.n {
+		return nil, fmt.Errorf("ot length error")
+	}
+	negA := &curves.ECPoint{Curve: curve, X: s.A.X, Y: new(big.Int).Sub(curve.P, s.A.Y)}
+	out := make([][2][]byte, s.n)
+	for j := range B {
+		if B[j] == nil || B[j].Curve != curve || !B[j].IsOnCurve() {
+			return nil, fmt.Errorf("ot receiver point %d error", j)
+		}
+		if len(m0[j]) > MaxMessageSize || len(m1[j]) > MaxMessageSize {
+			return nil, fmt.Errorf("ot message too long")
+		}
+		k0 := s.key(j, B[j].ScalarMult(s.a))
+		BA, err := B[j].Add(negA)
+		if err != nil {
+			return nil, err
+		}
+		k1 := s.key(j, BA.ScalarMult(s.a))
+		out[j] = [2][]byte{xorBytes(m0[j], k0), xorBytes(m1[j], k1)}
+	}
+	return out, nil
+}
+
+func (s *Sender) key(j int, P *curves.ECPoint) []byte {
+	return otKey(s.A, j, P)
+}
+
+// Decrypt the chosen messages
+func (r *Receiver) Decrypt(e [][2][]byte) ([][]byte, error) {
+	if len(e) != len(r.choices) {
+		return nil, fmt.Errorf("ot length error")
+	}
+	out := make([][]byte, len(e))
+	for j := range e {
+		k := otKey(r.A, j, r.A.ScalarMult(r.b[j]))
+		c := 0
+		if r.choices[j] {
+			c = 1
+		}
+		if len(e[j][c]) > MaxMessageSize {
+			return nil, fmt.Errorf("ot message too long")
+		}
+		out[j] = xorBytes(e[j][c], k)
+	}
+	return out, nil
+}
+
+// otKey H(A | j | P)
+func otKey(A *curves.ECPoint, j int, P *curves.ECPoint) []byte {
+	h := sha256.New()
+	h.Write(A.X.FillBytes(make([]byte, 32)))
+	h.Write(A.Y.FillBytes(make([]byte, 32)))
+	index := make([]byte, 8)
+	binary.BigEndian.PutUint64(index, uint64(j))
+	h.Write(index)
+	h.Write(P.X.FillBytes(make([]byte, 32)))
+	h.Write(P.Y.FillBytes(make([]byte, 32)))
+	return h.Sum(nil)
+}
+
+func xorBytes(m, k []byte) []byte {
+	out := make([]byte, len(m))
+	for i := range m {
+		out[i] = m[i] ^ k[i]
+	}
+	return out
+}
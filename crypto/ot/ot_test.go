@@ -0,0 +1,36 @@
+package ot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOT(t *testing.T) {
+	n := 8
+	m0, m1 := make([][]byte, n), make([][]byte, n)
+	choices := make([]bool, n)
+	for j := 0; j < n; j++ {
+		m0[j] = bytes.Repeat([]byte{byte(2 * j)}, 16)
+		m1[j] = bytes.Repeat([]byte{byte(2*j + 1)}, 16)
+		choices[j] = j%3 == 0
+	}
+	sender, A := NewSender(n)
+	receiver, B, err := NewReceiver(A, choices)
+	require.NoError(t, err)
+	e, err := sender.Encrypt(B, m0, m1)
+	require.NoError(t, err)
+	out, err := receiver.Decrypt(e)
+	require.NoError(t, err)
+	for j := 0; j < n; j++ {
+		if choices[j] {
+			require.Equal(t, m1[j], out[j])
+		} else {
+			require.Equal(t, m0[j], out[j])
+		}
+	}
+
+	_, err = sender.Encrypt(B[1:], m0, m1)
+	require.Error(t, err)
+}
@@ -0,0 +1,24 @@
+package ripemd160
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum(t *testing.T) {
+	vectors := map[string]string{
+		"":               "9c1185a5c5e9fc54612808977ee8f548b2258d31",
+		"a":              "0bdc9d2d256b3ee9daae347be6f4dc835a467ffe",
+		"abc":            "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc",
+		"message digest": "5d0689ef49d2fae572b881b123a85ffa21595f36",
+		"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq": "12a053384a9c0c88e405a06c27dcf49ada62eb2b",
+		strings.Repeat("1234567890", 8):                            "9b752e45573d4b39f4dbd3323cab82bf63326bfb",
+	}
+	for msg, digest := range vectors {
+		sum := Sum([]byte(msg))
+		require.Equal(t, digest, hex.EncodeToString(sum[:]))
+	}
+}
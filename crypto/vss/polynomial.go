@@ -91,3 +91,23 @@ func CalLagrangian(curve elliptic.Curve, x, y *big.Int, xList []*big.Int) *big.I
 	wi = new(big.Int).Mod(wi, q)
 	return wi
 }
+
+// CalLagrangianAt lagrangian interpolation at point at, f(at) = sum(wi)
+func CalLagrangianAt(curve elliptic.Curve, x, y *big.Int, xList []*big.Int, at *big.Int) *big.Int {
+	q := curve.Params().N
+	wi := new(big.Int).Set(y)
+	// wi = y*mul((at-xj)/(xi-xj))
+	for i := 0; i < len(xList); i++ {
+		xj := xList[i]
+		if x.Cmp(xj) == 0 {
+			continue
+		}
+		coef := new(big.Int).Sub(x, xj)
+		coef.Mod(coef, q)
+		coef.ModInverse(coef, q)
+		coef.Mul(coef, new(big.Int).Sub(at, xj))
+		wi.Mul(wi, coef)
+		wi.Mod(wi, q)
+	}
+	return wi
+}
@@ -54,3 +54,22 @@ func TestFeldman(t *testing.T) {
 	w23 := CalLagrangian(curve, big.NewInt(int64(3)), shares[2].Y, []*big.Int{big.NewInt(int64(1)), big.NewInt(int64(3))})
 	fmt.Println(new(big.Int).Mod(new(big.Int).Add(w21, w23), curve.N))
 }
+
+func TestLagrangianAt(t *testing.T) {
+	ec := secp256k1.S256()
+	polynomial, _ := InitPolynomial(ec, big.NewInt(int64(123456)), 2)
+	xList := []*big.Int{big.NewInt(1), big.NewInt(3), big.NewInt(4)}
+	at := big.NewInt(7)
+	sum := big.NewInt(0)
+	for _, x := range xList {
+		share := polynomial.EvaluatePolynomial(x)
+		sum.Add(sum, CalLagrangianAt(ec, x, share.Y, xList, at))
+	}
+	sum.Mod(sum, ec.N)
+	if sum.Cmp(polynomial.EvaluatePolynomial(at).Y) != 0 {
+		t.Fatal("lagrangian interpolation at 7 error")
+	}
+	if CalLagrangianAt(ec, xList[0], big.NewInt(5), xList, big.NewInt(0)).Cmp(CalLagrangian(ec, xList[0], big.NewInt(5), xList)) != 0 {
+		t.Fatal("lagrangian interpolation at 0 error")
+	}
+}
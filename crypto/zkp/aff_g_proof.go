@@ -31,8 +31,9 @@ var (
 )
 
 // https://eprint.iacr.org/2020/492.pdf 4.2 Paillier Operation with Group Commitment in Range ZK
-// y is committed in elliptic curve group instead of Paillier group
+// y is committed in elliptic curve group instead of Paillier group, the group is the curve of st.X
 func PaillierAffineProve(pedersen *pedersen.PedersenParameters, st *AffGStatement, wit *AffGWitness) *AffGProof {
+	curve := st.X.Curve
 	N2 := new(big.Int).Mul(st.N, st.N)
 
 	// sample viaribles
@@ -77,7 +78,7 @@ func PaillierAffineProve(pedersen *pedersen.PedersenParameters, st *AffGStatemen
 
 	// compute challenge e
 	e := crypto.SHA256Int(st.N, st.C, st.D, st.X.X, st.Y.X, A, Bx.X, By.X, E, S, F, T)
-	e = new(big.Int).Mod(e, curve.N)
+	e = new(big.Int).Mod(e, curve.Params().N)
 
 	// compute Z1, Z2, Z3, Z4, W
 	// Z1 = alpha + e * x
@@ -99,9 +100,14 @@ func PaillierAffineProve(pedersen *pedersen.PedersenParameters, st *AffGStatemen
 }
 
 func PaillierAffineVerify(pedersen *pedersen.PedersenParameters, proof *AffGProof, st *AffGStatement) bool {
+	curve := st.X.Curve
+	// all points in the group of st.X
+	if st.Y.Curve != curve || proof.Bx.Curve != curve || proof.By.Curve != curve {
+		return false
+	}
 	N2 := new(big.Int).Mul(st.N, st.N)
 	e := crypto.SHA256Int(st.N, st.C, st.D, st.X.X, st.Y.X, proof.A, proof.Bx.X, proof.By.X, proof.E, proof.S, proof.F, proof.T)
-	e = new(big.Int).Mod(e, curve.N)
+	e = new(big.Int).Mod(e, curve.Params().N)
 
 	// check A
 	// C^Z1 * ((1+N)^Z2 * w^N) = A * D^e mod N2
@@ -1,13 +1,16 @@
 package zkp
 
 import (
+	"crypto/elliptic"
 	"fmt"
 	"math/big"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAffGProof(t *testing.T) {
@@ -52,6 +55,7 @@ func TestAffGProof(t *testing.T) {
 	D = new(big.Int).Mod(new(big.Int).Mul(D, new(big.Int).Exp(new(big.Int).Add(one, N), y, N2)), N2)
 	D = new(big.Int).Mod(new(big.Int).Mul(D, new(big.Int).Exp(rho, N, N2)), N2)
 
+	curve := secp256k1.S256()
 	X := curves.ScalarToPoint(curve, x)
 	Y := curves.ScalarToPoint(curve, y)
 
@@ -68,6 +72,15 @@ func TestAffGProof(t *testing.T) {
 
 	fmt.Println("PaillierAffineProof of honest prover:", verify)
 
+	// the group follows the curve of the statement
+	st.X = curves.ScalarToPoint(elliptic.P256(), x)
+	st.Y = curves.ScalarToPoint(elliptic.P256(), y)
+	proof = PaillierAffineProve(pesersen, st, witness)
+	require.True(t, PaillierAffineVerify(pesersen, proof, st))
+	st.Y = Y
+	require.False(t, PaillierAffineVerify(pesersen, proof, st))
+	st.X = X
+
 	x = crypto.RandomNum(N)
 	witness = &AffGWitness{
 		X:   x,
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/paillier"
@@ -23,7 +24,7 @@ func TestGroupElementPaillierEncryptionRangeProof(t *testing.T) {
 	require.True(t, succ)
 
 	N0 := new(big.Int).Mul(p, q)
-	G := curves.ScalarToPoint(curve, big.NewInt(1))
+	G := curves.ScalarToPoint(secp256k1.S256(), big.NewInt(1))
 	pubKey := paillier.PublicKey{N: N0}
 	l := uint(16)
 
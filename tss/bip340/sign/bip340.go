@@ -0,0 +1,298 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/bip32"
+)
+
+// BIP-340 threshold schnorr signature on secp256k1 https://github.com/bitcoin/bips/blob/master/bip-0340.mediawiki
+// Two rounds with binding factors as FROST, nonce commitments do not depend on the message.
+// Group key and nonce are x-only, the signers negate their share or nonce when the point has odd Y.
+// BIP-341 tweak Q = P + t*G is supported for taproot outputs, tweak t is added once in aggregation.
+
+var (
+	curve = secp256k1.S256()
+)
+
+// protocol id of the message envelope
+const protocol = "bip340/sign"
+
+type SchnorrSign struct {
+	DeviceNumber int
+	Threshold    int
+	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
+
+	partList       []int // participating signers, at least threshold
+	shareI         *big.Int
+	publicKey      *curves.ECPoint         // internal key P
+	sharePubKeyMap map[int]*curves.ECPoint // share publicKey of the derived key
+
+	keyParity *big.Int        // 1 or -1, x-only output key secret = keyParity * (P secret)
+	tweak     *big.Int        // sign of output key applied, 0 if no tweak
+	outputKey *curves.ECPoint // Q with even Y
+
+	hidingNonce  *big.Int // di, single-use
+	bindingNonce *big.Int // ei, single-use
+	commitments  map[int]*NonceCommitment
+
+	message     []byte
+	R           *curves.ECPoint  // group nonce
+	nonceParity *big.Int         // 1 or -1, nonces are negated if R has odd Y
+	c           *big.Int         // challenge
+	rhos        map[int]*big.Int // binding factors
+	shares      map[int]*big.Int // signature shares zi
+}
+
+// NewSchnorrSign tssKey is the root or derived key of dkg share, sharePubKeyMap from dkg is shifted with the derivation offset
+//
+// Deprecated: messages are not bound to a session, use NewSchnorrSignWithSession.
+func NewSchnorrSign(deviceNumber, threshold int, partList []int, tssKey *bip32.TssKey, sharePubKeyMap map[int]*curves.ECPoint) *SchnorrSign {
+	return newSchnorrSign("", deviceNumber, threshold, partList, tssKey, sharePubKeyMap)
+}
+
+// NewSchnorrSignWithSession tssKey is the root or derived key of dkg share, sharePubKeyMap from dkg is shifted with the derivation offset,
+// signers use the same unique sessionId
+func NewSchnorrSignWithSession(sessionId string, deviceNumber, threshold int, partList []int, tssKey *bip32.TssKey, sharePubKeyMap map[int]*curves.ECPoint) *SchnorrSign {
+	if sessionId == "" {
+		return nil
+	}
+	return newSchnorrSign(sessionId, deviceNumber, threshold, partList, tssKey, sharePubKeyMap)
+}
+
+func newSchnorrSign(sessionId string, deviceNumber, threshold int, partList []int, tssKey *bip32.TssKey, sharePubKeyMap map[int]*curves.ECPoint) *SchnorrSign {
+	if threshold < 2 || len(partList) < threshold || tssKey == nil || tssKey.ShareI() == nil {
+		return nil
+	}
+	offset := curves.ScalarToPoint(curve, tssKey.PrivateKeyOffset())
+	seen := make(map[int]bool, len(partList))
+	derived := make(map[int]*curves.ECPoint, len(partList))
+	for _, id := range partList {
+		if id <= 0 || seen[id] || sharePubKeyMap[id] == nil {
+			return nil
+		}
+		seen[id] = true
+		X, err := sharePubKeyMap[id].Add(offset)
+		if err != nil {
+			return nil
+		}
+		derived[id] = X
+	}
+	if !seen[deviceNumber] {
+		return nil
+	}
+	ids := make([]int, len(partList))
+	copy(ids, partList)
+	sort.Ints(ids)
+	info := &SchnorrSign{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		envelope:       tss.Envelope{Protocol: protocol, SessionId: sessionId},
+		partList:       ids,
+		shareI:         tssKey.ShareI(),
+		publicKey:      tssKey.PublicKey(),
+		sharePubKeyMap: derived,
+	}
+	info.setOutputKey(big.NewInt(0))
+	return info
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *SchnorrSign) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+// SetTaprootTweak BIP-341 output key Q = P + hash_TapTweak(P.x || merkleRoot)*G, merkleRoot is empty for key path only
+func (info *SchnorrSign) SetTaprootTweak(merkleRoot []byte) error {
+	if info.RoundNumber != 1 {
+		return fmt.Errorf("round error")
+	}
+	if len(merkleRoot) != 0 && len(merkleRoot) != 32 {
+		return fmt.Errorf("merkle root length error")
+	}
+	t := TaprootTweak(info.publicKey, merkleRoot)
+	if t.Cmp(curve.N) >= 0 {
+		return fmt.Errorf("tweak out of range")
+	}
+	info.setOutputKey(t)
+	return nil
+}
+
+// setOutputKey Q = lift_x(P.x) + t*G, lift_x(P.x) = g1*P, Q' = g2*Q has even Y
+func (info *SchnorrSign) setOutputKey(t *big.Int) {
+	q := curve.N
+	g1 := parity(info.publicKey)
+	P := &curves.ECPoint{Curve: curve, X: info.publicKey.X, Y: info.publicKey.Y}
+	if g1.Sign() < 0 {
+		P = negate(P)
+	}
+	Q := P
+	if t.Sign() != 0 {
+		Q, _ = P.Add(curves.ScalarToPoint(curve, t))
+	}
+	g2 := parity(Q)
+	if g2.Sign() < 0 {
+		Q = negate(Q)
+	}
+	info.keyParity = new(big.Int).Mul(g1, g2)
+	info.tweak = new(big.Int).Mod(new(big.Int).Mul(g2, t), q)
+	info.outputKey = Q
+}
+
+// XOnlyPublicKey 32 bytes x-only output key, taproot witness program
+func (info *SchnorrSign) XOnlyPublicKey() []byte {
+	return info.outputKey.X.FillBytes(make([]byte, 32))
+}
+
+// TaprootTweak t = hash_TapTweak(P.x || merkleRoot)
+func TaprootTweak(publicKey *curves.ECPoint, merkleRoot []byte) *big.Int {
+	return new(big.Int).SetBytes(TaggedHash("TapTweak", publicKey.X.FillBytes(make([]byte, 32)), merkleRoot))
+}
+
+// Verify BIP-340 signature verify, publicKey is 32 bytes x-only
+func Verify(publicKey, message, signature []byte) bool {
+	if len(publicKey) != 32 || len(signature) != 64 {
+		return false
+	}
+	P, err := LiftX(new(big.Int).SetBytes(publicKey))
+	if err != nil {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if r.Cmp(curve.P) >= 0 || s.Cmp(curve.N) >= 0 {
+		return false
+	}
+	e := new(big.Int).SetBytes(TaggedHash("BIP0340/challenge", signature[:32], publicKey, message))
+	e.Mod(e, curve.N)
+	// R = s*G - e*P
+	negE := new(big.Int).Sub(curve.N, e)
+	x1, y1 := curve.ScalarBaseMult(s.Bytes())
+	x2, y2 := curve.ScalarMult(P.X, P.Y, negE.Bytes())
+	x, y := curve.Add(x1, y1, x2, y2)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return false
+	}
+	return y.Bit(0) == 0 && x.Cmp(r) == 0
+}
+
+// lagrangian coefficient of id over partList at 0
+func (info *SchnorrSign) lagrangian(id int) *big.Int {
+	xList := make([]*big.Int, len(info.partList))
+	for i, x := range info.partList {
+		xList[i] = big.NewInt(int64(x))
+	}
+	return vss.CalLagrangian(curve, big.NewInt(int64(id)), big.NewInt(1), xList)
+}
+
+// bindingFactors rho_i = hash_FROST/rho(Q.x || hash(msg) || hash(commitment list) || i)
+func bindingFactors(outputKey *curves.ECPoint, commitments map[int]*NonceCommitment, ids []int, message []byte) map[int]*big.Int {
+	var encoded []byte
+	for _, id := range ids {
+		cmt := commitments[id]
+		encoded = append(encoded, big.NewInt(int64(id)).FillBytes(make([]byte, 32))...)
+		encoded = append(encoded, serializePoint(cmt.D)...)
+		encoded = append(encoded, serializePoint(cmt.E)...)
+	}
+	prefix := append([]byte{}, outputKey.X.FillBytes(make([]byte, 32))...)
+	prefix = append(prefix, TaggedHash("FROST/msg", message)...)
+	prefix = append(prefix, TaggedHash("FROST/com", encoded)...)
+
+	rhos := make(map[int]*big.Int, len(ids))
+	for _, id := range ids {
+		rho := new(big.Int).SetBytes(TaggedHash("FROST/rho", prefix, big.NewInt(int64(id)).FillBytes(make([]byte, 32))))
+		rhos[id] = rho.Mod(rho, curve.N)
+	}
+	return rhos
+}
+
+// groupCommitment R = sum(Di + rho_i*Ei)
+func groupCommitment(commitments map[int]*NonceCommitment, ids []int, rhos map[int]*big.Int) (*curves.ECPoint, error) {
+	var R *curves.ECPoint
+	for _, id := range ids {
+		cmt := commitments[id]
+		Ri, err := cmt.D.Add(cmt.E.ScalarMult(rhos[id]))
+		if err != nil {
+			return nil, err
+		}
+		if R == nil {
+			R = Ri
+			continue
+		}
+		R, err = R.Add(Ri)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return R, nil
+}
+
+// TaggedHash BIP-340 tagged hash, sha256(sha256(tag) || sha256(tag) || x)
+func TaggedHash(tag string, in ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, b := range in {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// LiftX BIP-340 lift_x, point with x and even Y
+func LiftX(x *big.Int) (*curves.ECPoint, error) {
+	p := curve.P
+	if x.Cmp(p) >= 0 {
+		return nil, fmt.Errorf("x out of range")
+	}
+	// y^2 = x^3 + 7
+	c := new(big.Int).Exp(x, big.NewInt(3), p)
+	c.Add(c, big.NewInt(7))
+	c.Mod(c, p)
+	y := new(big.Int).ModSqrt(c, p)
+	if y == nil {
+		return nil, fmt.Errorf("x is not on curve")
+	}
+	if y.Bit(0) == 1 {
+		y.Sub(p, y)
+	}
+	return &curves.ECPoint{Curve: curve, X: x, Y: y}, nil
+}
+
+// parity 1 for even Y, -1 for odd Y
+func parity(point *curves.ECPoint) *big.Int {
+	if point.Y.Bit(0) == 0 {
+		return big.NewInt(1)
+	}
+	return big.NewInt(-1)
+}
+
+func negate(point *curves.ECPoint) *curves.ECPoint {
+	return &curves.ECPoint{Curve: curve, X: point.X, Y: new(big.Int).Sub(curve.P, point.Y)}
+}
+
+// serializePoint 33 bytes compressed
+func serializePoint(point *curves.ECPoint) []byte {
+	prefix := byte(2)
+	if point.Y.Bit(0) == 1 {
+		prefix = 3
+	}
+	return append([]byte{prefix}, point.X.FillBytes(make([]byte, 32))...)
+}
+
+// checkPoint on curve, the identity is not on curve
+func checkPoint(point *curves.ECPoint) error {
+	if point == nil || point.X == nil || point.Y == nil || !curve.IsOnCurve(point.X, point.Y) {
+		return fmt.Errorf("invalid point")
+	}
+	return nil
+}
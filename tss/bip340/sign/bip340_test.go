@@ -0,0 +1,162 @@
+package sign
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/bip32"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	// BIP-340 test vector 0
+	publicKey, _ := hex.DecodeString("F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9")
+	message := make([]byte, 32)
+	signature, _ := hex.DecodeString("E907831F80848D1069A5371B402410364BDF1C5F8307B0084C55F1CE2DCA821525F66A4A85EA8B71E482A74F382D2CE5EBEEE8FDB2172F477DF4900D310536C0")
+	require.True(t, Verify(publicKey, message, signature))
+	signature[63] ^= 1
+	require.False(t, Verify(publicKey, message, signature))
+}
+
+func TestSchnorrSign(t *testing.T) {
+	hash := sha256.Sum256([]byte("hello"))
+	message := hash[:]
+	for i := 0; i < 4; i++ {
+		keyData := keyGen(t, 2, 3)
+		root, err := bip32.NewTssKey(nil, keyData[0].PublicKey, keyData[0].ChainCode)
+		require.NoError(t, err)
+		child, err := root.NewChildKey(996)
+		require.NoError(t, err)
+
+		for _, partList := range [][]int{{1, 2}, {3, 1}, {1, 2, 3}} {
+			sign(t, 2, keyData, partList, nil, nil, message)
+			sign(t, 2, keyData, partList, []uint32{996}, nil, message)
+		}
+		// key path only and script path taproot outputs
+		xOnly := sign(t, 2, keyData, []int{2, 3}, nil, []byte{}, message)
+		require.Equal(t, taprootOutput(t, root.PublicKey(), nil), xOnly)
+		merkleRoot := sha256.Sum256([]byte("script"))
+		xOnly = sign(t, 2, keyData, []int{1, 3}, []uint32{996}, merkleRoot[:], message)
+		require.Equal(t, taprootOutput(t, child.PublicKey(), merkleRoot[:]), xOnly)
+	}
+
+	keyData := keyGen(t, 3, 5)
+	sign(t, 3, keyData, []int{5, 2, 4, 1}, []uint32{1, 2}, []byte{}, message)
+}
+
+func TestSchnorrSignBlame(t *testing.T) {
+	keyData := keyGen(t, 2, 3)
+	infos, out1 := step1(t, 2, keyData, []int{1, 2}, nil, nil)
+	out2 := make(map[int]map[int]*tss.Message)
+	for id, info := range infos {
+		msgs, err := info.SignStep2("00", collect(out1, id))
+		require.NoError(t, err)
+		out2[id] = msgs
+	}
+	var content Step2Data
+	require.NoError(t, json.Unmarshal([]byte(out2[2][1].Data), &content))
+	content.Share.Add(content.Share, big.NewInt(1))
+	bytes, _ := json.Marshal(content)
+	out2[2][1].Data = string(bytes)
+
+	_, err := infos[1].SignStep3(collect(out2, 1))
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 2, blame.Culprit)
+}
+
+// taprootOutput x-only Q = lift_x(P.x) + hash_TapTweak(P.x || merkleRoot)*G
+func taprootOutput(t *testing.T, publicKey *curves.ECPoint, merkleRoot []byte) []byte {
+	P, err := LiftX(publicKey.X)
+	require.NoError(t, err)
+	Q, err := P.Add(curves.ScalarToPoint(curve, TaprootTweak(publicKey, merkleRoot)))
+	require.NoError(t, err)
+	return Q.X.FillBytes(make([]byte, 32))
+}
+
+// sign return x-only output key, merkleRoot nil for no taproot tweak
+func sign(t *testing.T, threshold int, keyData []*tss.KeyStep3Data, partList []int, path []uint32, merkleRoot []byte, message []byte) []byte {
+	infos, out1 := step1(t, threshold, keyData, partList, path, merkleRoot)
+	out2 := make(map[int]map[int]*tss.Message)
+	for id, info := range infos {
+		msgs, err := info.SignStep2(hex.EncodeToString(message), collect(out1, id))
+		require.NoError(t, err)
+		out2[id] = msgs
+	}
+	var xOnly []byte
+	for id, info := range infos {
+		signature, err := info.SignStep3(collect(out2, id))
+		require.NoError(t, err)
+		xOnly = info.XOnlyPublicKey()
+		require.True(t, Verify(xOnly, message, signature))
+	}
+	return xOnly
+}
+
+func step1(t *testing.T, threshold int, keyData []*tss.KeyStep3Data, partList []int, path []uint32, merkleRoot []byte) (map[int]*SchnorrSign, map[int]map[int]*tss.Message) {
+	infos := make(map[int]*SchnorrSign)
+	for _, id := range partList {
+		data := keyData[id-1]
+		tssKey, err := bip32.NewTssKey(data.ShareI, data.PublicKey, data.ChainCode)
+		require.NoError(t, err)
+		for _, idx := range path {
+			tssKey, err = tssKey.NewChildKey(idx)
+			require.NoError(t, err)
+		}
+		info := NewSchnorrSignWithSession("bip340", id, threshold, partList, tssKey, data.SharePubKeyMap)
+		require.NotNil(t, info)
+		if merkleRoot != nil {
+			require.NoError(t, info.SetTaprootTweak(merkleRoot))
+		}
+		infos[id] = info
+	}
+	out1 := make(map[int]map[int]*tss.Message)
+	for id, info := range infos {
+		msgs, err := info.SignStep1()
+		require.NoError(t, err)
+		out1[id] = msgs
+	}
+	return infos, out1
+}
+
+func collect(out map[int]map[int]*tss.Message, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for from, msgMap := range out {
+		if from != id {
+			msgs = append(msgs, msgMap[id])
+		}
+	}
+	return msgs
+}
+
+func keyGen(t *testing.T, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	out1 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out1[i+1] = msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out1, i+1))
+		require.NoError(t, err)
+		out2[i+1] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(out2, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
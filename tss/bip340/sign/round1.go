@@ -0,0 +1,47 @@
+package sign
+
+import (
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// NonceCommitment Di = di*G, Ei = ei*G
+type NonceCommitment struct {
+	D *curves.ECPoint
+	E *curves.ECPoint
+}
+
+type Step1Data struct {
+	Commitment *NonceCommitment
+}
+
+// SignStep1 broadcast nonce commitment, message is not needed, can run ahead of signing
+func (info *SchnorrSign) SignStep1() (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	info.hidingNonce = crypto.RandomNum(curve.N)
+	info.bindingNonce = crypto.RandomNum(curve.N)
+	commitment := &NonceCommitment{
+		D: curves.ScalarToPoint(curve, info.hidingNonce),
+		E: curves.ScalarToPoint(curve, info.bindingNonce),
+	}
+	info.commitments = map[int]*NonceCommitment{info.DeviceNumber: commitment}
+	info.RoundNumber = 2
+
+	bytes, err := info.envelope.MarshalData(Step1Data{Commitment: commitment})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]*tss.Message, len(info.partList)-1)
+	for _, id := range info.partList {
+		if id == info.DeviceNumber {
+			continue
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
+	}
+	return out, nil
+}
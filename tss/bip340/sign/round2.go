@@ -0,0 +1,99 @@
+package sign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step2Data struct {
+	Share *big.Int // zi
+}
+
+// SignStep2 binding factors, group nonce R and signature share
+// zi = nonceParity*(di + ei*rho_i) + c*lambda_i*keyParity*xi, nonces are deleted after use
+func (info *SchnorrSign) SignStep2(message string, msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	msg, err := hex.DecodeString(message)
+	if err != nil {
+		return nil, err
+	}
+	// nonce is never used twice, even if this step fails
+	info.RoundNumber = -1
+	di, ei := info.hidingNonce, info.bindingNonce
+	info.hidingNonce, info.bindingNonce = nil, nil
+
+	for _, m := range msgs {
+		if m.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(m, 1); err != nil {
+			return nil, err
+		}
+		if !info.isSigner(m.From) || m.From == info.DeviceNumber {
+			return nil, fmt.Errorf("unknown signer %d", m.From)
+		}
+		if _, ok := info.commitments[m.From]; ok {
+			return nil, fmt.Errorf("duplicate message, signer %d", m.From)
+		}
+		var content Step1Data
+		err = tss.UnmarshalData([]byte(m.Data), &content)
+		if err != nil || content.Commitment == nil || checkPoint(content.Commitment.D) != nil || checkPoint(content.Commitment.E) != nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, m)
+		}
+		info.commitments[m.From] = content.Commitment
+	}
+
+	q := curve.N
+	info.message = msg
+	info.rhos = bindingFactors(info.outputKey, info.commitments, info.partList, msg)
+	R, err := groupCommitment(info.commitments, info.partList, info.rhos)
+	if err != nil {
+		return nil, err
+	}
+	info.R = R
+	info.nonceParity = parity(R)
+	// c = hash_BIP0340/challenge(R.x || Q.x || m)
+	c := new(big.Int).SetBytes(TaggedHash("BIP0340/challenge", R.X.FillBytes(make([]byte, 32)), info.XOnlyPublicKey(), msg))
+	info.c = c.Mod(c, q)
+
+	k := new(big.Int).Mul(ei, info.rhos[info.DeviceNumber])
+	k.Add(k, di)
+	k.Mul(k, info.nonceParity)
+	xi := new(big.Int).Mul(info.lagrangian(info.DeviceNumber), info.shareI)
+	xi.Mul(xi, info.keyParity)
+	xi.Mul(xi, info.c)
+	zi := k.Add(k, xi)
+	zi.Mod(zi, q)
+	info.RoundNumber = 3
+
+	bytes, err := info.envelope.MarshalData(Step2Data{Share: zi})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]*tss.Message, len(info.partList)-1)
+	for _, id := range info.partList {
+		if id == info.DeviceNumber {
+			continue
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 2, bytes)
+	}
+	info.shares = map[int]*big.Int{info.DeviceNumber: zi}
+	return out, nil
+}
+
+func (info *SchnorrSign) isSigner(id int) bool {
+	for _, i := range info.partList {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package sign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// SignStep3 verify signature shares, s = sum(zi) + c*tweak, return 64 bytes BIP-340 signature R.x || s
+func (info *SchnorrSign) SignStep3(msgs []*tss.Message) ([]byte, error) {
+	if info.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	q := curve.N
+	s := new(big.Int).Set(info.shares[info.DeviceNumber])
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, err
+		}
+		if !info.isSigner(msg.From) || msg.From == info.DeviceNumber {
+			return nil, fmt.Errorf("unknown signer %d", msg.From)
+		}
+		if _, ok := info.shares[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, signer %d", msg.From)
+		}
+		var content Step2Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil || content.Share == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		if !info.verifyShare(msg.From, content.Share) {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
+		}
+		info.shares[msg.From] = content.Share
+		s.Add(s, content.Share)
+	}
+	s.Add(s, new(big.Int).Mul(info.c, info.tweak))
+	s.Mod(s, q)
+	info.RoundNumber = -1
+
+	signature := make([]byte, 0, 64)
+	signature = append(signature, info.R.X.FillBytes(make([]byte, 32))...)
+	signature = append(signature, s.FillBytes(make([]byte, 32))...)
+	if !Verify(info.XOnlyPublicKey(), info.message, signature) {
+		return nil, fmt.Errorf("bip340 signature verify fail")
+	}
+	return signature, nil
+}
+
+// verifyShare zi*G = nonceParity*(Di + rho_i*Ei) + c*lambda_i*keyParity*Xi
+func (info *SchnorrSign) verifyShare(id int, zi *big.Int) bool {
+	q := curve.N
+	if zi.Sign() < 0 || zi.Cmp(q) >= 0 {
+		return false
+	}
+	cmt := info.commitments[id]
+	Ri, err := cmt.D.Add(cmt.E.ScalarMult(info.rhos[id]))
+	if err != nil {
+		return false
+	}
+	if info.nonceParity.Sign() < 0 {
+		Ri = negate(Ri)
+	}
+	coef := new(big.Int).Mul(info.c, info.lagrangian(id))
+	coef.Mul(coef, info.keyParity)
+	coef.Mod(coef, q)
+	expected, err := Ri.Add(info.sharePubKeyMap[id].ScalarMult(coef))
+	if err != nil {
+		return false
+	}
+	return curves.ScalarToPoint(curve, zi).Equals(expected)
+}
@@ -0,0 +1,431 @@
+package bitcoin
+
+import (
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/crypto/ripemd160"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/bip340/sign"
+	"github.com/okx/threshold-lib/tss/ecdsa/keygen"
+	ecdsasign "github.com/okx/threshold-lib/tss/ecdsa/sign"
+	"github.com/okx/threshold-lib/tss/key/bip32"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+var curve = secp256k1.S256()
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func witnessUtxo(value int64, script []byte) []byte {
+	return (&TxOut{Value: value, PkScript: script}).serialize()
+}
+
+func TestWitnessV0Sighash(t *testing.T) {
+	// BIP-143 native P2WPKH
+	tx, err := ParseTx(decodeHex(t, "0100000002fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f0000000000eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac11000000"))
+	require.NoError(t, err)
+	p, err := NewPsbt(tx)
+	require.NoError(t, err)
+	p.Inputs[1].Set([]byte{PsbtInWitnessUtxo}, witnessUtxo(600000000, decodeHex(t, "00141d0f172a0ecb48aee1be1f2687d2963ae33f71a1")))
+	hash, err := p.Sighash(1)
+	require.NoError(t, err)
+	require.Equal(t, "c37af31116d1b27caf68aae9e3ac82f1477929014d5b917657d0eb49478cb670", hex.EncodeToString(hash))
+	_, err = p.Sighash(0)
+	require.Error(t, err)
+
+	// BIP-143 P2SH-P2WPKH
+	tx, err = ParseTx(decodeHex(t, "0100000001db6b1b20aa0fd7b23880be2ecbd4a98130974cf4748fb66092ac4d3ceb1a54770100000000feffffff02b8b4eb0b000000001976a914a457b684d7f0d539a46a45bbc043f35b59d0d96388ac0008af2f000000001976a914fd270b1ee6abcaea97fea7ad0402e8bd8ad6d77c88ac92040000"))
+	require.NoError(t, err)
+	p, err = NewPsbt(tx)
+	require.NoError(t, err)
+	redeem := decodeHex(t, "001479091972186c449eb1ded22b78e40d009bdf0089")
+	p2sh := append(append([]byte{0xa9, 0x14}, ripemd160.Hash160(redeem)...), 0x87)
+	p.Inputs[0].Set([]byte{PsbtInWitnessUtxo}, witnessUtxo(1000000000, p2sh))
+	p.Inputs[0].Set([]byte{PsbtInRedeemScript}, redeem)
+	hash, err = p.Sighash(0)
+	require.NoError(t, err)
+	require.Equal(t, "64f3b0f4dd2bb3aa1ce8566d220cc74dda9df97d8490cc81d89d735c92e59fb6", hex.EncodeToString(hash))
+
+	// round trip
+	parsed, err := ParsePsbtBase64(p.Base64())
+	require.NoError(t, err)
+	require.Equal(t, p.Serialize(), parsed.Serialize())
+}
+
+func TestLegacySighash(t *testing.T) {
+	// block 170, P2PK spend of block 9 coinbase
+	tx, err := ParseTx(decodeHex(t, "0100000001c997a5e56e104102fa209c6a852dd90660a20b2d9c352423edce25857fcd3704000000004847304402204e45e16932b8af514961a1d3a1a25fdf3f4f7732e9d624c6c61548ab5fb8cd410220181522ec8eca07de4860a4acdd12909d831cc56cbbac4622082221a8768d1d0901ffffffff0200ca9a3b00000000434104ae1a62fe09c5f51b13905f07f06b99a2f7159b2225f374cd378d71302fa28414e7aab37397f554a7df5f142c21c1b7303b8a0626f1baded5c72a704f7e6cd84cac00286bee0000000043410411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5cb2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3ac00000000"))
+	require.NoError(t, err)
+	script := decodeHex(t, "410411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5cb2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3ac")
+	hash, err := LegacySighash(tx, 0, script, SigHashAll)
+	require.NoError(t, err)
+	require.Equal(t, "7a05c6145f10101e9d6325494245adf1297d80f8f38d4d576d57cdba220bcb19", hex.EncodeToString(hash))
+	requireScriptSig(t, tx.Inputs[0].ScriptSig, script[1:66], hash)
+
+	// BIP-143 native P2WPKH example, P2PK input 0 as signed
+	tx, err = ParseTx(decodeHex(t, "01000000000102fff7f7881a8099afa6940d42d1e7f6362bec38171ea3edf433541db4e4ad969f00000000494830450221008b9d1dc26ba6a9cb62127b02742fa9d754cd3bebf337f7a55d114c8e5cdd30be022040529b194ba3f9281a99f2b1c0a19c0489bc22ede944ccf4ecbab4cc618ef3ed01eeffffffef51e1b804cc89d182d279655c3aa89e815b1b309fe287d9b2b55d57b90ec68a0100000000ffffffff02202cb206000000001976a9148280b37df378db99f66f85c95a783a76ac7a6d5988ac9093510d000000001976a9143bde42dbee7e4dbe6a21b2d50ce2f0167faa815988ac000247304402203609e17b84f6a7d30c80bfa610b5b4542f32a8a0d5447a12fb1366d7f01cc44a0220573a954c4518331561406f90300e8f3358f51928d43c212a8caed02de67eebee0121025476c2e83188368da1ff3e292e7acafcdb3566bb0ad253f62fc70f07aeeea35711000000"))
+	require.NoError(t, err)
+	script = decodeHex(t, "2103c9f4836b9a4f77fc0d81f7bcb01b7f1b35916864b9476c241ce9fc198bd25432ac")
+	hash, err = LegacySighash(tx, 0, script, SigHashAll)
+	require.NoError(t, err)
+	require.Equal(t, "63cec688ee06a91e913875356dd4dea2f8e0f2a2659885372da2a37e32c7532e", hex.EncodeToString(hash))
+	requireScriptSig(t, tx.Inputs[0].ScriptSig, script[1:34], hash)
+
+	// SIGHASH_SINGLE without matching output signs uint256 one
+	tx.Outputs = tx.Outputs[:1]
+	hash, err = LegacySighash(tx, 1, script, SigHashSingle)
+	require.NoError(t, err)
+	require.Equal(t, "0100000000000000000000000000000000000000000000000000000000000000", hex.EncodeToString(hash))
+}
+
+// requireScriptSig checks the <sig> push of a P2PK script sig against hash
+func requireScriptSig(t *testing.T, scriptSig, publicKey, hash []byte) {
+	require.Equal(t, int(scriptSig[0])+1, len(scriptSig))
+	require.Equal(t, byte(SigHashAll), scriptSig[len(scriptSig)-1])
+	var sig struct{ R, S *big.Int }
+	_, err := asn1.Unmarshal(scriptSig[1:len(scriptSig)-1], &sig)
+	require.NoError(t, err)
+	pub, err := secp256k1.ParsePubKey(publicKey)
+	require.NoError(t, err)
+	require.True(t, ecdsa.Verify(pub.ToECDSA(), hash, sig.R, sig.S))
+}
+
+func TestTaprootSighash(t *testing.T) {
+	// BIP-341 wallet test vectors, keyPathSpending
+	tx, err := ParseTx(decodeHex(t, "02000000097de20cbff686da83a54981d2b9bab3586f4ca7e48f57f5b55963115f3b334e9c010000000000000000d7b7cab57b1393ace2d064f4d4a2cb8af6def61273e127517d44759b6dafdd990000000000fffffffff8e1f583384333689228c5d28eac13366be082dc57441760d957275419a41842000000006b4830450221008f3b8f8f0537c420654d2283673a761b7ee2ea3c130753103e08ce79201cf32a022079e7ab904a1980ef1c5890b648c8783f4d10103dd62f740d13daa79e298d50c201210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798fffffffff0689180aa63b30cb162a73c6d2a38b7eeda2a83ece74310fda0843ad604853b0100000000feffffffaa5202bdf6d8ccd2ee0f0202afbbb7461d9264a25e5bfd3c5a52ee1239e0ba6c0000000000feffffff956149bdc66faa968eb2be2d2faa29718acbfe3941215893a2a3446d32acd050000000000000000000e664b9773b88c09c32cb70a2a3e4da0ced63b7ba3b22f848531bbb1d5d5f4c94010000000000000000e9aa6b8e6c9de67619e6a3924ae25696bb7b694bb677a632a74ef7eadfd4eabf0000000000ffffffffa778eb6a263dc090464cd125c466b5a99667720b1c110468831d058aa1b82af10100000000ffffffff0200ca9a3b000000001976a91406afd46bcdfd22ef94ac122aa11f241244a37ecc88ac807840cb0000000020ac9a87f5594be208f8532db38cff670c450ed2fea8fcdefcc9a663f78bab962b0065cd1d"))
+	require.NoError(t, err)
+	amounts := []int64{420000000, 462000000, 294000000, 504000000, 630000000, 378000000, 672000000, 546000000, 588000000}
+	scripts := []string{
+		"512053a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343",
+		"5120147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3",
+		"76a914751e76e8199196d454941c45d1b3a323f1433bd688ac",
+		"5120e4d810fd50586274face62b8a807eb9719cef49c04177cc6b76a9a4251d5450e",
+		"512091b64d5324723a985170e4dc5a0f84c041804f2cd12660fa5dec09fc21783605",
+		"00147dd65592d0ab2fe0d0257d571abf032cd9db93dc",
+		"512075169f4001aa68f15bbed28b218df1d0a62cbbcf1188c6665110c293c907b831",
+		"5120712447206d7a5238acc7ff53fbe94a3b64539ad291c7cdbc490b7577e4b17df5",
+		"512077e30a5522dd9f894c3f8b8bd4c4b2cf82ca7da8a3ea6a239655c39c050ab220",
+	}
+	prevOuts := make([]*TxOut, len(amounts))
+	var amountBytes, scriptBytes []byte
+	for i := range amounts {
+		prevOuts[i] = &TxOut{Value: amounts[i], PkScript: decodeHex(t, scripts[i])}
+		amountBytes = append(amountBytes, uint64Bytes(uint64(amounts[i]))...)
+		scriptBytes = append(scriptBytes, varBytes(prevOuts[i].PkScript)...)
+	}
+	require.Equal(t, "e3b33bb4ef3a52ad1fffb555c0d82828eb22737036eaeb02a235d82b909c4c3f", hex.EncodeToString(sha256Bytes(prevouts(tx))))
+	require.Equal(t, "58a6964a4f5f8f0b642ded0a8a553be7622a719da71d1f5befcefcdee8e0fde6", hex.EncodeToString(sha256Bytes(amountBytes)))
+	require.Equal(t, "23ad0f61ad2bca5ba6a7693f50fce988e17c3780bf2b1e720cfbb38fbdd52e21", hex.EncodeToString(sha256Bytes(scriptBytes)))
+	require.Equal(t, "18959c7221ab5ce9e26c3cd67b22c24f8baa54bac281d8e6b05e400e6c3a957e", hex.EncodeToString(sha256Bytes(sequences(tx))))
+	require.Equal(t, "a2e6dab7c1f0dcd297c8d61647fd17d821541ea69c3cc37dcbad7f90d4eb4bc5", hex.EncodeToString(sha256Bytes(outputs(tx))))
+
+	vectors := []struct {
+		idx      int
+		hashType uint32
+		sighash  string
+	}{
+		{0, 0x03, "2514a6272f85cfa0f45eb907fcb0d121b808ed37c6ea160a5a9046ed5526d555"},
+		{1, 0x83, "325a644af47e8a5a2591cda0ab0723978537318f10e6a63d4eed783b96a71a4d"},
+		{3, 0x01, "bf013ea93474aa67815b1b6cc441d23b64fa310911d991e713cd34c7f5d46669"},
+		{4, 0x00, "4f900a0bae3f1446fd48490c2958b5a023228f01661cda3496a11da502a7f7ef"},
+		{6, 0x02, "15f25c298eb5cdc7eb1d638dd2d45c97c4c59dcaec6679cfc16ad84f30876b85"},
+		{7, 0x82, "cd292de50313804dabe4685e83f923d2969577191a3e1d2882220dca88cbeb10"},
+		{8, 0x81, "cccb739eca6c13a8a89e6e5cd317ffe55669bbda23f2fd37b0f18755e008edd2"},
+	}
+	for _, v := range vectors {
+		hash, err := TaprootSighash(tx, v.idx, prevOuts, v.hashType)
+		require.NoError(t, err)
+		require.Equal(t, v.sighash, hex.EncodeToString(hash))
+	}
+	// witness of input 0
+	witness := decodeHex(t, "ed7c1647cb97379e76892be0cacff57ec4a7102aa24296ca39af7541246d8ff14d38958d4cc1e2e478e4d4a764bbfd835b16d4e314b72937b29833060b87276c03")
+	require.True(t, sign.Verify(prevOuts[0].PkScript[2:], decodeHex(t, vectors[0].sighash), witness[:64]))
+
+	_, err = TaprootSighash(tx, 0, prevOuts, 0x04)
+	require.Error(t, err)
+	_, err = TaprootSighash(tx, 0, prevOuts[1:], SigHashAll)
+	require.Error(t, err)
+}
+
+func TestTaprootOutputKey(t *testing.T) {
+	// BIP-341 wallet test vectors, scriptPubKey
+	internalKey := func(x string) *curves.ECPoint {
+		pub, err := secp256k1.ParsePubKey(append([]byte{0x02}, decodeHex(t, x)...))
+		require.NoError(t, err)
+		return &curves.ECPoint{Curve: curve, X: pub.X, Y: pub.Y}
+	}
+	output := taprootOutputKey(internalKey("d6889cb081036e0faefa3a35157ad71086b123b2b144b649798b494c300a961d"), nil)
+	require.Equal(t, "53a1f6e454df1aa2776a2814a721372d6258050de330b3c6d10ee8f4e0dda343", hex.EncodeToString(output))
+
+	leafScript := decodeHex(t, "20d85a959b0290bf19bb89ed43c916be835475d013da4b362117393e25a48229b8ac")
+	leafHash := sign.TaggedHash("TapLeaf", append([]byte{0xc0}, varBytes(leafScript)...))
+	require.Equal(t, "5b75adecf53548f3ec6ad7d78383bf84cc57b55a3127c72b9a2481752dd88b21", hex.EncodeToString(leafHash))
+	output = taprootOutputKey(internalKey("187791b6f712a8ea41c8ecdd0ee77fab3e85263b37e1ec18a3651926b3a6cf27"), leafHash)
+	require.Equal(t, "147c9c57132f6e7ecddba9800bb0c4449251c92a1e60371ee77557b6620f3ea3", hex.EncodeToString(output))
+}
+
+func TestParsePsbt(t *testing.T) {
+	// BIP-174 valid: one P2PKH input with non witness utxo, outputs are empty
+	b := decodeHex(t, "70736274ff0100750200000001268171371edff285e937adeea4b37b78000c0566cbb3ad64641713ca42171bf60000000000feffffff02d3dff505000000001976a914d0c59903c5bac2868760e90fd521a4665aa7652088ac00e1f5050000000017a9143545e6e33b832c47050f24d3eeb93c9c03948bc787b32e1300000100fda5010100000000010289a3c71eab4d20e0371bbba4cc698fa295c9463afa2e397f8533ccb62f9567e50100000017160014be18d152a9b012039daf3da7de4f53349eecb985ffffffff86f8aa43a71dff1448893a530a7237ef6b4608bbb2dd2d0171e63aec6a4890b40100000017160014fe3e9ef1a745e974d902c4355943abcb34bd5353ffffffff0200c2eb0b000000001976a91485cff1097fd9e008bb34af709c62197b38978a4888ac72fef84e2c00000017a914339725ba21efd62ac753a9bcd067d6c7a6a39d05870247304402202712be22e0270f394f568311dc7ca9a68970b8025fdd3b240229f07f8a5f3a240220018b38d7dcd314e734c9276bd6fb40f673325bc4baa144c800d2f2f02db2765c012103d2e15674941bad4a996372cb87e1856d3652606d98562fe39c5e9e7e413f210502483045022100d12b852d85dcd961d2f5f4ab660654df6eedcc794c0c33ce5cc309ffb5fce58d022067338a8e0e1725c197fb1a88af59f51e44e4255b20167c8684031c05d1f2592a01210223b72beef0965d10be0778efecd61fcac6f79a4ea169393380734464f84f2ab300000000000000")
+	p, err := ParsePsbt(b)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(p.Inputs))
+	require.Equal(t, 2, len(p.Outputs))
+	require.Equal(t, b, p.Serialize())
+	// the non witness utxo hashes to the spent outpoint
+	prevOut, err := p.prevOut(0)
+	require.NoError(t, err)
+	require.Equal(t, int64(200000000), prevOut.Value)
+	require.Equal(t, "76a91485cff1097fd9e008bb34af709c62197b38978a4888ac", hex.EncodeToString(prevOut.PkScript))
+	hash, err := p.Sighash(0)
+	require.NoError(t, err)
+	legacy, err := LegacySighash(p.UnsignedTx, 0, prevOut.PkScript, SigHashAll)
+	require.NoError(t, err)
+	require.Equal(t, legacy, hash)
+
+	// BIP-174 invalid: network transaction, trailing bytes, signed unsigned transaction
+	_, err = ParsePsbt(p.UnsignedTx.Serialize(false))
+	require.Error(t, err)
+	_, err = ParsePsbt(append(append([]byte{}, b...), 0x00))
+	require.Error(t, err)
+	signed, err := ParseTx(p.Inputs[0].Get([]byte{PsbtInNonWitnessUtxo}))
+	require.NoError(t, err)
+	invalid := &Psbt{Global: PsbtMap{{Key: []byte{PsbtGlobalUnsignedTx}, Value: signed.Serialize(false)}}}
+	_, err = ParsePsbt(invalid.Serialize())
+	require.Error(t, err)
+
+	// tampered non witness utxo
+	utxo := append([]byte{}, p.Inputs[0].Get([]byte{PsbtInNonWitnessUtxo})...)
+	utxo[len(utxo)-1] ^= 1
+	p.Inputs[0].Set([]byte{PsbtInNonWitnessUtxo}, utxo)
+	_, err = p.Sighash(0)
+	require.Error(t, err)
+}
+
+func TestPsbtSign(t *testing.T) {
+	keyData := keyGen(t, 2, 3)
+	roots := make([]*bip32.TssKey, len(keyData))
+	for i, data := range keyData {
+		root, err := bip32.NewTssKeyBip32(data.ShareI, data.PublicKey, data.ChainCode)
+		require.NoError(t, err)
+		roots[i] = root
+	}
+	derivePath := func(root *bip32.TssKey, path []uint32) *bip32.TssKey {
+		key := root
+		for _, idx := range path {
+			var err error
+			key, err = key.NewChildKey(idx)
+			require.NoError(t, err)
+		}
+		return key
+	}
+	derivation := func(path []uint32) []byte {
+		value := append([]byte{}, roots[0].Fingerprint()...)
+		for _, idx := range path {
+			value = append(value, uint32Bytes(idx)...)
+		}
+		return value
+	}
+	p2pkhPath, p2wpkhPath, p2trPath := []uint32{0, 0}, []uint32{0, 1}, []uint32{1, 0}
+	p2pkhKey := derivePath(roots[0], p2pkhPath)
+	p2wpkhKey := derivePath(roots[0], p2wpkhPath)
+	p2trKey := derivePath(roots[0], p2trPath)
+	p2trOutput := taprootOutputKey(p2trKey.PublicKey(), nil)
+	// children are those of wallets on the xpub
+	xpub, err := roots[0].Xpub()
+	require.NoError(t, err)
+	watchOnly, err := bip32.NewTssKeyFromXpub(xpub)
+	require.NoError(t, err)
+	require.True(t, derivePath(watchOnly, p2pkhPath).PublicKey().Equals(p2pkhKey.PublicKey()))
+
+	prevTx := &Tx{Version: 2, Inputs: []*TxIn{{PrevIndex: 0, Sequence: 0xffffffff}}, Outputs: []*TxOut{
+		{Value: 50000, PkScript: p2pkh(ripemd160.Hash160(compressed(p2pkhKey.PublicKey())))},
+		{Value: 60000, PkScript: append([]byte{0x00, 0x14}, ripemd160.Hash160(compressed(p2wpkhKey.PublicKey()))...)},
+		{Value: 70000, PkScript: append([]byte{0x51, 0x20}, p2trOutput...)},
+	}}
+	prevHash := prevTx.TxHash()
+	tx := &Tx{Version: 2, Outputs: []*TxOut{{Value: 170000, PkScript: prevTx.Outputs[2].PkScript}}}
+	for i := range prevTx.Outputs {
+		tx.Inputs = append(tx.Inputs, &TxIn{PrevHash: prevHash, PrevIndex: uint32(i), Sequence: 0xfffffffd})
+	}
+	p, err := NewPsbt(tx)
+	require.NoError(t, err)
+	p.Inputs[0].Set([]byte{PsbtInNonWitnessUtxo}, prevTx.Serialize(true))
+	p.Inputs[0].Set(append([]byte{PsbtInBip32Derivation}, compressed(p2pkhKey.PublicKey())...), derivation(p2pkhPath))
+	p.Inputs[1].Set([]byte{PsbtInWitnessUtxo}, prevTx.Outputs[1].serialize())
+	p.Inputs[1].Set(append([]byte{PsbtInBip32Derivation}, compressed(p2wpkhKey.PublicKey())...), derivation(p2wpkhPath))
+	p.Inputs[2].Set([]byte{PsbtInWitnessUtxo}, prevTx.Outputs[2].serialize())
+	xOnly := p2trKey.PublicKey().X.FillBytes(make([]byte, 32))
+	p.Inputs[2].Set(append([]byte{PsbtInTapBip32}, xOnly...), append([]byte{0x00}, derivation(p2trPath)...))
+	p.Inputs[2].Set([]byte{PsbtInTapInternalKey}, xOnly)
+
+	p, err = ParsePsbtBase64(p.Base64())
+	require.NoError(t, err)
+
+	// parties 1 and 2 sign, ecdsa with the 2-party protocol where P2 holds the derived share
+	partList := []int{1, 2}
+	paiPrivate, preParams := loadPreKey(t)
+	p1Msg, E_x1, err := keygen.P1(keyData[0].ShareI, paiPrivate, 1, 2, preParams, preParams.PedersonParameters(), preParams.Proof)
+	require.NoError(t, err)
+	p2Data, err := keygen.P2(keyData[1].ShareI, keyData[1].PublicKey, p1Msg, 1, 2, preParams.PedersonParameters())
+	require.NoError(t, err)
+	p2Root, err := bip32.NewTssKeyBip32(p2Data.X2, keyData[1].PublicKey, keyData[1].ChainCode)
+	require.NoError(t, err)
+	ecdsaSign := func(key *bip32.TssKey, path []uint32, hash []byte) (*big.Int, *big.Int, error) {
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: key.PublicKey().X, Y: key.PublicKey().Y}
+		message := hex.EncodeToString(hash)
+		p1 := ecdsasign.NewP1WithSession("psbt", publicKey, message, paiPrivate, E_x1, preParams.PedersonParameters())
+		p2 := ecdsasign.NewP2WithSession("psbt", key.ShareI(), p2Data.E_x1, publicKey, p2Data.PaiPubKey, message, p2Data.Ped1)
+		msg1, err := p1.SessionStep1()
+		if err != nil {
+			return nil, nil, err
+		}
+		msg2, err := p2.SessionStep1(msg1)
+		if err != nil {
+			return nil, nil, err
+		}
+		msg3, err := p1.SessionStep2(msg2)
+		if err != nil {
+			return nil, nil, err
+		}
+		msg4, err := p2.SessionStep2(msg3)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p1.SessionStep3(msg4)
+	}
+	schnorrSign := func(key *bip32.TssKey, path []uint32, hash, merkleRoot []byte) ([]byte, error) {
+		return schnorrThreshold(t, keyData, roots, partList, path, hash, merkleRoot), nil
+	}
+	count, err := p.Sign(p2Root, ecdsaSign, schnorrSign)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	p, err = ParsePsbtBase64(p.Base64())
+	require.NoError(t, err)
+	for i, key := range []*bip32.TssKey{p2pkhKey, p2wpkhKey} {
+		value := p.Inputs[i].Get(append([]byte{PsbtInPartialSig}, compressed(key.PublicKey())...))
+		require.NotNil(t, value)
+		require.Equal(t, byte(SigHashAll), value[len(value)-1])
+		var sig struct{ R, S *big.Int }
+		_, err = asn1.Unmarshal(value[:len(value)-1], &sig)
+		require.NoError(t, err)
+		hash, err := p.Sighash(i)
+		require.NoError(t, err)
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: key.PublicKey().X, Y: key.PublicKey().Y}
+		require.True(t, ecdsa.Verify(publicKey, hash, sig.R, sig.S))
+	}
+	hash, err := p.Sighash(2)
+	require.NoError(t, err)
+	tapSig := p.Inputs[2].Get([]byte{PsbtInTapKeySig})
+	require.Equal(t, 64, len(tapSig))
+	require.True(t, sign.Verify(p2trOutput, hash, tapSig))
+
+	// inputs other roots can not sign are errors
+	other, err := bip32.NewTssKeyBip32(keyData[0].ShareI, curves.ScalarToPoint(curve, big.NewInt(7)), keyData[0].ChainCode)
+	require.NoError(t, err)
+	count, err = p.Sign(other, ecdsaSign, schnorrSign)
+	require.EqualError(t, err, "input 0: no bip32 derivation of root")
+	require.Equal(t, 0, count)
+	_, err = p.Sign(p2Root, nil, schnorrSign)
+	require.Error(t, err)
+	labelRoot, err := bip32.NewTssKey(p2Data.X2, keyData[1].PublicKey, keyData[1].ChainCode)
+	require.NoError(t, err)
+	_, err = p.Sign(labelRoot, ecdsaSign, schnorrSign)
+	require.Error(t, err)
+	// hardened paths are not derived
+	p.Inputs[0].Set(append([]byte{PsbtInBip32Derivation}, compressed(p2pkhKey.PublicKey())...), derivation([]uint32{bip32.HardenedKeyStart, 0}))
+	_, err = p.Sign(p2Root, ecdsaSign, schnorrSign)
+	require.EqualError(t, err, "input 0: hardened derivation is unsupported")
+}
+
+// loadPreKey paillier key and pre-params of P1, safe primes are slow so the keygen testdata is shared
+func loadPreKey(t *testing.T) (*paillier.PrivateKey, *keygen.PreParamsWithDlnProof) {
+	bytes, err := os.ReadFile("../ecdsa/keygen/testdata/prekeys.json")
+	require.NoError(t, err)
+	var preKeys []struct {
+		PaiPriKey *paillier.PrivateKey
+		PreParams *keygen.PreParamsWithDlnProof
+	}
+	require.NoError(t, json.Unmarshal(bytes, &preKeys))
+	return preKeys[0].PaiPriKey, preKeys[0].PreParams
+}
+
+// schnorrThreshold runs threshold BIP-340 signing of hash between partList
+func schnorrThreshold(t *testing.T, keyData []*tss.KeyStep3Data, roots []*bip32.TssKey, partList []int, path []uint32, hash, merkleRoot []byte) []byte {
+	infos := make(map[int]*sign.SchnorrSign)
+	out1 := make(map[int]map[int]*tss.Message)
+	for _, id := range partList {
+		key := roots[id-1]
+		for _, idx := range path {
+			var err error
+			key, err = key.NewChildKey(idx)
+			require.NoError(t, err)
+		}
+		info := sign.NewSchnorrSignWithSession("bip340", id, 2, partList, key, keyData[id-1].SharePubKeyMap)
+		require.NotNil(t, info)
+		require.NoError(t, info.SetTaprootTweak(merkleRoot))
+		msgs, err := info.SignStep1()
+		require.NoError(t, err)
+		infos[id], out1[id] = info, msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message)
+	for id, info := range infos {
+		msgs, err := info.SignStep2(hex.EncodeToString(hash), collect(out1, id))
+		require.NoError(t, err)
+		out2[id] = msgs
+	}
+	signature, err := infos[partList[0]].SignStep3(collect(out2, partList[0]))
+	require.NoError(t, err)
+	return signature
+}
+
+func collect(out map[int]map[int]*tss.Message, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for from, msgMap := range out {
+		if from != id {
+			msgs = append(msgs, msgMap[id])
+		}
+	}
+	return msgs
+}
+
+func keyGen(t *testing.T, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	out1 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out1[i+1] = msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out1, i+1))
+		require.NoError(t, err)
+		out2[i+1] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(out2, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
@@ -0,0 +1,198 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+)
+
+// BIP-174 partially signed bitcoin transaction, version 0.
+// Maps keep every key-value pair in order, unknown pairs are serialized back unchanged.
+
+var psbtMagic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// global, input and output key types used by the signer
+const (
+	PsbtGlobalUnsignedTx = 0x00
+
+	PsbtInNonWitnessUtxo  = 0x00
+	PsbtInWitnessUtxo     = 0x01
+	PsbtInPartialSig      = 0x02
+	PsbtInSighashType     = 0x03
+	PsbtInRedeemScript    = 0x04
+	PsbtInWitnessScript   = 0x05
+	PsbtInBip32Derivation = 0x06
+	PsbtInTapKeySig       = 0x13
+	PsbtInTapBip32        = 0x16
+	PsbtInTapInternalKey  = 0x17
+	PsbtInTapMerkleRoot   = 0x18
+)
+
+type KeyValue struct {
+	Key   []byte // key type | key data
+	Value []byte
+}
+
+// PsbtMap key-value map of the global, an input or an output
+type PsbtMap []*KeyValue
+
+type Psbt struct {
+	UnsignedTx *Tx
+	Global     PsbtMap
+	Inputs     []PsbtMap
+	Outputs    []PsbtMap
+}
+
+// NewPsbt empty maps for an unsigned transaction
+func NewPsbt(tx *Tx) (*Psbt, error) {
+	for _, in := range tx.Inputs {
+		if len(in.ScriptSig) != 0 || len(in.Witness) != 0 {
+			return nil, fmt.Errorf("transaction is not unsigned")
+		}
+	}
+	return &Psbt{
+		UnsignedTx: tx,
+		Global:     PsbtMap{{Key: []byte{PsbtGlobalUnsignedTx}, Value: tx.Serialize(false)}},
+		Inputs:     make([]PsbtMap, len(tx.Inputs)),
+		Outputs:    make([]PsbtMap, len(tx.Outputs)),
+	}, nil
+}
+
+// ParsePsbt binary psbt
+func ParsePsbt(b []byte) (*Psbt, error) {
+	if !bytes.HasPrefix(b, psbtMagic) {
+		return nil, fmt.Errorf("invalid psbt magic")
+	}
+	r := bytes.NewReader(b[len(psbtMagic):])
+	global, err := readPsbtMap(r)
+	if err != nil {
+		return nil, err
+	}
+	unsigned := global.Get([]byte{PsbtGlobalUnsignedTx})
+	if unsigned == nil {
+		return nil, fmt.Errorf("psbt without unsigned transaction")
+	}
+	tx, err := ParseTx(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	p, err := NewPsbt(tx)
+	if err != nil {
+		return nil, err
+	}
+	p.Global = global
+	for i := range p.Inputs {
+		if p.Inputs[i], err = readPsbtMap(r); err != nil {
+			return nil, err
+		}
+	}
+	for i := range p.Outputs {
+		if p.Outputs[i], err = readPsbtMap(r); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("trailing psbt bytes")
+	}
+	return p, nil
+}
+
+// ParsePsbtBase64 base64 psbt as exchanged by wallets
+func ParsePsbtBase64(s string) (*Psbt, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePsbt(b)
+}
+
+func (p *Psbt) Serialize() []byte {
+	var buf bytes.Buffer
+	buf.Write(psbtMagic)
+	p.Global.write(&buf)
+	for _, m := range p.Inputs {
+		m.write(&buf)
+	}
+	for _, m := range p.Outputs {
+		m.write(&buf)
+	}
+	return buf.Bytes()
+}
+
+func (p *Psbt) Base64() string {
+	return base64.StdEncoding.EncodeToString(p.Serialize())
+}
+
+// Get value of key, nil if not present
+func (m PsbtMap) Get(key []byte) []byte {
+	for _, kv := range m {
+		if bytes.Equal(kv.Key, key) {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+// Set replace the value of key or append the pair
+func (m *PsbtMap) Set(key, value []byte) {
+	for _, kv := range *m {
+		if bytes.Equal(kv.Key, key) {
+			kv.Value = value
+			return
+		}
+	}
+	*m = append(*m, &KeyValue{Key: key, Value: value})
+}
+
+// OfType pairs with key type t
+func (m PsbtMap) OfType(t byte) []*KeyValue {
+	var out []*KeyValue
+	for _, kv := range m {
+		if kv.Key[0] == t {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// SighashType input sighash type, def if not set
+func (m PsbtMap) SighashType(def uint32) (uint32, error) {
+	value := m.Get([]byte{PsbtInSighashType})
+	if value == nil {
+		return def, nil
+	}
+	if len(value) != 4 {
+		return 0, fmt.Errorf("invalid sighash type")
+	}
+	return binary.LittleEndian.Uint32(value), nil
+}
+
+func (m PsbtMap) write(buf *bytes.Buffer) {
+	for _, kv := range m {
+		buf.Write(varBytes(kv.Key))
+		buf.Write(varBytes(kv.Value))
+	}
+	buf.WriteByte(0x00)
+}
+
+func readPsbtMap(r *bytes.Reader) (PsbtMap, error) {
+	m := PsbtMap{}
+	for {
+		key, err := readVarBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(key) == 0 {
+			return m, nil
+		}
+		if m.Get(key) != nil {
+			return nil, fmt.Errorf("duplicate psbt key %x", key)
+		}
+		value, err := readVarBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		m = append(m, &KeyValue{Key: key, Value: value})
+	}
+}
@@ -0,0 +1,172 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/okx/threshold-lib/tss/bip340/sign"
+)
+
+const (
+	SigHashDefault      = uint32(0x00) // taproot only, ALL without the sighash byte
+	SigHashAll          = uint32(0x01)
+	SigHashNone         = uint32(0x02)
+	SigHashSingle       = uint32(0x03)
+	SigHashAnyoneCanPay = uint32(0x80)
+)
+
+// LegacySighash signature hash of input idx before segwit, scriptCode is the prevout script or the P2SH redeem script
+func LegacySighash(tx *Tx, idx int, scriptCode []byte, hashType uint32) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input index out of range")
+	}
+	base := hashType & 0x1f
+	if base == SigHashSingle && idx >= len(tx.Outputs) {
+		// uint256 one, signing it is the known SIGHASH_SINGLE bug
+		one := make([]byte, 32)
+		one[0] = 1
+		return one, nil
+	}
+	copyTx := &Tx{Version: tx.Version, LockTime: tx.LockTime}
+	for i, in := range tx.Inputs {
+		if hashType&SigHashAnyoneCanPay != 0 && i != idx {
+			continue
+		}
+		txIn := &TxIn{PrevHash: in.PrevHash, PrevIndex: in.PrevIndex, Sequence: in.Sequence}
+		if i == idx {
+			txIn.ScriptSig = scriptCode
+		} else if base == SigHashNone || base == SigHashSingle {
+			txIn.Sequence = 0
+		}
+		copyTx.Inputs = append(copyTx.Inputs, txIn)
+	}
+	switch base {
+	case SigHashNone:
+	case SigHashSingle:
+		for i := 0; i < idx; i++ {
+			copyTx.Outputs = append(copyTx.Outputs, &TxOut{Value: -1})
+		}
+		copyTx.Outputs = append(copyTx.Outputs, tx.Outputs[idx])
+	default:
+		copyTx.Outputs = tx.Outputs
+	}
+	return doubleSha256(append(copyTx.Serialize(false), uint32Bytes(hashType)...)), nil
+}
+
+// WitnessV0Sighash BIP-143 signature hash, scriptCode is p2pkh(hash) for P2WPKH or the witness script
+func WitnessV0Sighash(tx *Tx, idx int, scriptCode []byte, amount int64, hashType uint32) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.Inputs) {
+		return nil, fmt.Errorf("input index out of range")
+	}
+	base := hashType & 0x1f
+	anyoneCanPay := hashType&SigHashAnyoneCanPay != 0
+	zero := make([]byte, 32)
+	hashPrevouts, hashSequence, hashOutputs := zero, zero, zero
+	if !anyoneCanPay {
+		hashPrevouts = doubleSha256(prevouts(tx))
+		if base != SigHashSingle && base != SigHashNone {
+			hashSequence = doubleSha256(sequences(tx))
+		}
+	}
+	if base != SigHashSingle && base != SigHashNone {
+		hashOutputs = doubleSha256(outputs(tx))
+	} else if base == SigHashSingle && idx < len(tx.Outputs) {
+		hashOutputs = doubleSha256(tx.Outputs[idx].serialize())
+	}
+	in := tx.Inputs[idx]
+	var buf bytes.Buffer
+	buf.Write(uint32Bytes(uint32(tx.Version)))
+	buf.Write(hashPrevouts)
+	buf.Write(hashSequence)
+	buf.Write(in.PrevHash[:])
+	buf.Write(uint32Bytes(in.PrevIndex))
+	buf.Write(varBytes(scriptCode))
+	buf.Write(uint64Bytes(uint64(amount)))
+	buf.Write(uint32Bytes(in.Sequence))
+	buf.Write(hashOutputs)
+	buf.Write(uint32Bytes(tx.LockTime))
+	buf.Write(uint32Bytes(hashType))
+	return doubleSha256(buf.Bytes()), nil
+}
+
+// TaprootSighash BIP-341 key path signature hash without annex, prevOuts are the outputs spent by all inputs
+func TaprootSighash(tx *Tx, idx int, prevOuts []*TxOut, hashType uint32) ([]byte, error) {
+	if idx < 0 || idx >= len(tx.Inputs) || len(prevOuts) != len(tx.Inputs) {
+		return nil, fmt.Errorf("input index or prevouts error")
+	}
+	if hashType > 0x03 && (hashType < 0x81 || hashType > 0x83) {
+		return nil, fmt.Errorf("invalid taproot sighash type %#x", hashType)
+	}
+	base := hashType & 0x03
+	if base == SigHashDefault {
+		base = SigHashAll
+	}
+	anyoneCanPay := hashType&SigHashAnyoneCanPay != 0
+	var buf bytes.Buffer
+	buf.WriteByte(0x00) // epoch
+	buf.WriteByte(byte(hashType))
+	buf.Write(uint32Bytes(uint32(tx.Version)))
+	buf.Write(uint32Bytes(tx.LockTime))
+	if !anyoneCanPay {
+		var amounts, scripts []byte
+		for _, out := range prevOuts {
+			amounts = append(amounts, uint64Bytes(uint64(out.Value))...)
+			scripts = append(scripts, varBytes(out.PkScript)...)
+		}
+		buf.Write(sha256Bytes(prevouts(tx)))
+		buf.Write(sha256Bytes(amounts))
+		buf.Write(sha256Bytes(scripts))
+		buf.Write(sha256Bytes(sequences(tx)))
+	}
+	if base != SigHashNone && base != SigHashSingle {
+		buf.Write(sha256Bytes(outputs(tx)))
+	}
+	buf.WriteByte(0x00) // spend type, key path and no annex
+	if anyoneCanPay {
+		in := tx.Inputs[idx]
+		buf.Write(in.PrevHash[:])
+		buf.Write(uint32Bytes(in.PrevIndex))
+		buf.Write(prevOuts[idx].serialize())
+		buf.Write(uint32Bytes(in.Sequence))
+	} else {
+		buf.Write(uint32Bytes(uint32(idx)))
+	}
+	if base == SigHashSingle {
+		if idx >= len(tx.Outputs) {
+			return nil, fmt.Errorf("no output for SIGHASH_SINGLE")
+		}
+		buf.Write(sha256Bytes(tx.Outputs[idx].serialize()))
+	}
+	return sign.TaggedHash("TapSighash", buf.Bytes()), nil
+}
+
+func prevouts(tx *Tx) []byte {
+	var b []byte
+	for _, in := range tx.Inputs {
+		b = append(b, in.PrevHash[:]...)
+		b = append(b, uint32Bytes(in.PrevIndex)...)
+	}
+	return b
+}
+
+func sequences(tx *Tx) []byte {
+	var b []byte
+	for _, in := range tx.Inputs {
+		b = append(b, uint32Bytes(in.Sequence)...)
+	}
+	return b
+}
+
+func outputs(tx *Tx) []byte {
+	var b []byte
+	for _, out := range tx.Outputs {
+		b = append(b, out.serialize()...)
+	}
+	return b
+}
+
+func sha256Bytes(b []byte) []byte {
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
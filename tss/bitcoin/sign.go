@@ -0,0 +1,287 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/ripemd160"
+	"github.com/okx/threshold-lib/tss/bip340/sign"
+	"github.com/okx/threshold-lib/tss/key/bip32"
+)
+
+// EcdsaSignFunc runs 2-party ECDSA for key derived from root by path over the 32 bytes hash, s must be low-S
+type EcdsaSignFunc func(key *bip32.TssKey, path []uint32, hash []byte) (r, s *big.Int, err error)
+
+// SchnorrSignFunc runs threshold BIP-340 for key derived from root by path and tweaked with merkleRoot, see SchnorrSign.SetTaprootTweak
+type SchnorrSignFunc func(key *bip32.TssKey, path []uint32, hash, merkleRoot []byte) ([]byte, error)
+
+// Sign every input with a bip32 derivation of root, root is a NewTssKeyBip32 key and its fingerprint is the master fingerprint of the derivation.
+// ECDSA signatures are written as partial signatures, taproot key path signatures as tap key sig.
+// An input without a derivation of root or with a hardened path of root is an error, hardened paths are not derived here.
+// Return the number of signatures written.
+func (p *Psbt) Sign(root *bip32.TssKey, ecdsaSign EcdsaSignFunc, schnorrSign SchnorrSignFunc) (int, error) {
+	if root == nil || !root.Bip32() {
+		return 0, fmt.Errorf("root requires bip32 derivation, use NewTssKeyBip32")
+	}
+	count := 0
+	for i, input := range p.Inputs {
+		prevOut, err := p.prevOut(i)
+		if err != nil {
+			return count, err
+		}
+		signed := count
+		if isP2TR(prevOut.PkScript) {
+			if schnorrSign == nil {
+				return count, fmt.Errorf("input %d: taproot without schnorr sign", i)
+			}
+			for _, kv := range input.OfType(PsbtInTapBip32) {
+				key, path, err := taprootKey(root, kv)
+				if err != nil {
+					return count, fmt.Errorf("input %d: %v", i, err)
+				}
+				if key == nil {
+					continue
+				}
+				merkleRoot := input.Get([]byte{PsbtInTapMerkleRoot})
+				if !bytes.Equal(taprootOutputKey(key.PublicKey(), merkleRoot), prevOut.PkScript[2:]) {
+					continue
+				}
+				hashType, err := input.SighashType(SigHashDefault)
+				if err != nil {
+					return count, err
+				}
+				hash, err := p.Sighash(i)
+				if err != nil {
+					return count, err
+				}
+				sig, err := schnorrSign(key, path, hash, merkleRoot)
+				if err != nil {
+					return count, err
+				}
+				if hashType != SigHashDefault {
+					sig = append(sig, byte(hashType))
+				}
+				p.Inputs[i].Set([]byte{PsbtInTapKeySig}, sig)
+				count++
+				break
+			}
+			if count == signed {
+				return count, fmt.Errorf("input %d: no key path derivation of root", i)
+			}
+			continue
+		}
+		if ecdsaSign == nil {
+			return count, fmt.Errorf("input %d: ecdsa without ecdsa sign", i)
+		}
+		for _, kv := range input.OfType(PsbtInBip32Derivation) {
+			key, path, err := derive(root, kv.Value)
+			if err != nil {
+				return count, fmt.Errorf("input %d: %v", i, err)
+			}
+			if key == nil || !bytes.Equal(compressed(key.PublicKey()), kv.Key[1:]) {
+				continue
+			}
+			hashType, err := input.SighashType(SigHashAll)
+			if err != nil {
+				return count, err
+			}
+			hash, err := p.Sighash(i)
+			if err != nil {
+				return count, err
+			}
+			r, s, err := ecdsaSign(key, path, hash)
+			if err != nil {
+				return count, err
+			}
+			partialSigKey := append([]byte{PsbtInPartialSig}, kv.Key[1:]...)
+			p.Inputs[i].Set(partialSigKey, append(derSignature(r, s), byte(hashType)))
+			count++
+		}
+		if count == signed {
+			return count, fmt.Errorf("input %d: no bip32 derivation of root", i)
+		}
+	}
+	return count, nil
+}
+
+// Sighash signature hash of input i: legacy, BIP-143 for segwit v0 (also nested in P2SH), BIP-341 key path for taproot
+func (p *Psbt) Sighash(i int) ([]byte, error) {
+	if i < 0 || i >= len(p.Inputs) {
+		return nil, fmt.Errorf("input index out of range")
+	}
+	input := p.Inputs[i]
+	prevOut, err := p.prevOut(i)
+	if err != nil {
+		return nil, err
+	}
+	if isP2TR(prevOut.PkScript) {
+		prevOuts := make([]*TxOut, len(p.Inputs))
+		for j := range p.Inputs {
+			if prevOuts[j], err = p.prevOut(j); err != nil {
+				return nil, err
+			}
+		}
+		hashType, err := input.SighashType(SigHashDefault)
+		if err != nil {
+			return nil, err
+		}
+		return TaprootSighash(p.UnsignedTx, i, prevOuts, hashType)
+	}
+	hashType, err := input.SighashType(SigHashAll)
+	if err != nil {
+		return nil, err
+	}
+	script := prevOut.PkScript
+	if isP2SH(script) {
+		redeem := input.Get([]byte{PsbtInRedeemScript})
+		if redeem == nil || !bytes.Equal(ripemd160.Hash160(redeem), script[2:22]) {
+			return nil, fmt.Errorf("redeem script mismatch")
+		}
+		script = redeem
+	}
+	switch {
+	case isP2WPKH(script):
+		return WitnessV0Sighash(p.UnsignedTx, i, p2pkh(script[2:]), prevOut.Value, hashType)
+	case isP2WSH(script):
+		witnessScript := input.Get([]byte{PsbtInWitnessScript})
+		if witnessScript == nil || !bytes.Equal(sha256Bytes(witnessScript), script[2:]) {
+			return nil, fmt.Errorf("witness script mismatch")
+		}
+		return WitnessV0Sighash(p.UnsignedTx, i, witnessScript, prevOut.Value, hashType)
+	case isWitnessProgram(script):
+		return nil, fmt.Errorf("unsupported witness version")
+	}
+	return LegacySighash(p.UnsignedTx, i, script, hashType)
+}
+
+// prevOut spent output of input i from witness utxo or non witness utxo
+func (p *Psbt) prevOut(i int) (*TxOut, error) {
+	in := p.UnsignedTx.Inputs[i]
+	if value := p.Inputs[i].Get([]byte{PsbtInNonWitnessUtxo}); value != nil {
+		prevTx, err := ParseTx(value)
+		if err != nil {
+			return nil, err
+		}
+		if prevTx.TxHash() != in.PrevHash || int(in.PrevIndex) >= len(prevTx.Outputs) {
+			return nil, fmt.Errorf("input %d non witness utxo mismatch", i)
+		}
+		return prevTx.Outputs[in.PrevIndex], nil
+	}
+	if value := p.Inputs[i].Get([]byte{PsbtInWitnessUtxo}); value != nil {
+		r := bytes.NewReader(value)
+		amount, err := readUint64(r)
+		if err != nil {
+			return nil, err
+		}
+		script, err := readVarBytes(r)
+		if err != nil || r.Len() != 0 {
+			return nil, fmt.Errorf("input %d invalid witness utxo", i)
+		}
+		return &TxOut{Value: int64(amount), PkScript: script}, nil
+	}
+	return nil, fmt.Errorf("input %d without utxo", i)
+}
+
+// derive key of a bip32 derivation value fingerprint | path, nil if it is not under root, a hardened path of root is an error
+func derive(root *bip32.TssKey, value []byte) (*bip32.TssKey, []uint32, error) {
+	if len(value) < 4 || len(value)%4 != 0 {
+		return nil, nil, fmt.Errorf("invalid bip32 derivation")
+	}
+	if !bytes.Equal(value[:4], root.Fingerprint()) {
+		return nil, nil, nil
+	}
+	key := root
+	path := make([]uint32, 0, len(value)/4-1)
+	for off := 4; off < len(value); off += 4 {
+		idx := binary.LittleEndian.Uint32(value[off:])
+		if idx >= bip32.HardenedKeyStart {
+			return nil, nil, fmt.Errorf("hardened derivation is unsupported")
+		}
+		var err error
+		if key, err = key.NewChildKey(idx); err != nil {
+			return nil, nil, err
+		}
+		path = append(path, idx)
+	}
+	return key, path, nil
+}
+
+// taprootKey derived key of a key path tap bip32 derivation, leaf hashes | fingerprint | path
+func taprootKey(root *bip32.TssKey, kv *KeyValue) (*bip32.TssKey, []uint32, error) {
+	r := bytes.NewReader(kv.Value)
+	n, err := readCompactSize(r)
+	if err != nil || len(kv.Key) != 33 {
+		return nil, nil, fmt.Errorf("invalid tap bip32 derivation")
+	}
+	if n != 0 {
+		// script path
+		return nil, nil, nil
+	}
+	key, path, err := derive(root, kv.Value[len(kv.Value)-r.Len():])
+	if err != nil || key == nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(key.PublicKey().X.FillBytes(make([]byte, 32)), kv.Key[1:]) {
+		return nil, nil, nil
+	}
+	return key, path, nil
+}
+
+// taprootOutputKey x-only Q = lift_x(P.x) + hash_TapTweak(P.x | merkleRoot)*G
+func taprootOutputKey(publicKey *curves.ECPoint, merkleRoot []byte) []byte {
+	P, err := sign.LiftX(publicKey.X)
+	if err != nil {
+		return nil
+	}
+	Q, err := P.Add(curves.ScalarToPoint(secp256k1.S256(), sign.TaprootTweak(P, merkleRoot)))
+	if err != nil {
+		return nil
+	}
+	return Q.X.FillBytes(make([]byte, 32))
+}
+
+// derSignature strict DER encoding of (r, s)
+func derSignature(r, s *big.Int) []byte {
+	encode := func(i *big.Int) []byte {
+		b := i.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0x00}, b...)
+		}
+		return append([]byte{0x02, byte(len(b))}, b...)
+	}
+	body := append(encode(r), encode(s)...)
+	return append([]byte{0x30, byte(len(body))}, body...)
+}
+
+func compressed(publicKey *curves.ECPoint) []byte {
+	return (&secp256k1.PublicKey{Curve: publicKey.Curve, X: publicKey.X, Y: publicKey.Y}).SerializeCompressed()
+}
+
+func p2pkh(hash []byte) []byte {
+	return append(append([]byte{0x76, 0xa9, 0x14}, hash...), 0x88, 0xac)
+}
+
+func isP2SH(script []byte) bool {
+	return len(script) == 23 && script[0] == 0xa9 && script[1] == 0x14 && script[22] == 0x87
+}
+
+func isP2WPKH(script []byte) bool {
+	return len(script) == 22 && script[0] == 0x00 && script[1] == 0x14
+}
+
+func isP2WSH(script []byte) bool {
+	return len(script) == 34 && script[0] == 0x00 && script[1] == 0x20
+}
+
+func isP2TR(script []byte) bool {
+	return len(script) == 34 && script[0] == 0x51 && script[1] == 0x20
+}
+
+// isWitnessProgram OP_1..OP_16 followed by a 2 to 40 bytes push
+func isWitnessProgram(script []byte) bool {
+	return len(script) >= 4 && len(script) <= 42 && script[0] >= 0x51 && script[0] <= 0x60 && int(script[1])+2 == len(script)
+}
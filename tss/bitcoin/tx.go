@@ -0,0 +1,262 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Bitcoin transaction serialization, witness serialization follows BIP-144
+
+type TxIn struct {
+	PrevHash  [32]byte // internal byte order
+	PrevIndex uint32
+	ScriptSig []byte
+	Sequence  uint32
+	Witness   [][]byte
+}
+
+type TxOut struct {
+	Value    int64
+	PkScript []byte
+}
+
+type Tx struct {
+	Version  int32
+	Inputs   []*TxIn
+	Outputs  []*TxOut
+	LockTime uint32
+}
+
+// ParseTx legacy or witness serialization
+func ParseTx(b []byte) (*Tx, error) {
+	r := bytes.NewReader(b)
+	tx, err := readTx(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("trailing transaction bytes")
+	}
+	return tx, nil
+}
+
+func readTx(r *bytes.Reader) (*Tx, error) {
+	tx := &Tx{}
+	var err error
+	var version uint32
+	if version, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	tx.Version = int32(version)
+	n, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	witness := false
+	if n == 0 {
+		// marker 0x00, flag 0x01
+		flag, err := r.ReadByte()
+		if err != nil || flag != 1 {
+			return nil, fmt.Errorf("invalid witness flag")
+		}
+		witness = true
+		if n, err = readCompactSize(r); err != nil {
+			return nil, err
+		}
+	}
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("invalid input count")
+	}
+	tx.Inputs = make([]*TxIn, n)
+	for i := range tx.Inputs {
+		in := &TxIn{}
+		if _, err = io.ReadFull(r, in.PrevHash[:]); err != nil {
+			return nil, err
+		}
+		if in.PrevIndex, err = readUint32(r); err != nil {
+			return nil, err
+		}
+		if in.ScriptSig, err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+		if in.Sequence, err = readUint32(r); err != nil {
+			return nil, err
+		}
+		tx.Inputs[i] = in
+	}
+	if n, err = readCompactSize(r); err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("invalid output count")
+	}
+	tx.Outputs = make([]*TxOut, n)
+	for i := range tx.Outputs {
+		out := &TxOut{}
+		var value uint64
+		if value, err = readUint64(r); err != nil {
+			return nil, err
+		}
+		out.Value = int64(value)
+		if out.PkScript, err = readVarBytes(r); err != nil {
+			return nil, err
+		}
+		tx.Outputs[i] = out
+	}
+	if witness {
+		for _, in := range tx.Inputs {
+			if n, err = readCompactSize(r); err != nil {
+				return nil, err
+			}
+			if n > uint64(r.Len()) {
+				return nil, fmt.Errorf("invalid witness count")
+			}
+			in.Witness = make([][]byte, n)
+			for j := range in.Witness {
+				if in.Witness[j], err = readVarBytes(r); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if tx.LockTime, err = readUint32(r); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Serialize witness serialization if witness is set and any input has witness data
+func (tx *Tx) Serialize(witness bool) []byte {
+	hasWitness := false
+	for _, in := range tx.Inputs {
+		hasWitness = hasWitness || len(in.Witness) > 0
+	}
+	witness = witness && hasWitness
+	var buf bytes.Buffer
+	buf.Write(uint32Bytes(uint32(tx.Version)))
+	if witness {
+		buf.Write([]byte{0x00, 0x01})
+	}
+	buf.Write(compactSize(uint64(len(tx.Inputs))))
+	for _, in := range tx.Inputs {
+		buf.Write(in.PrevHash[:])
+		buf.Write(uint32Bytes(in.PrevIndex))
+		buf.Write(varBytes(in.ScriptSig))
+		buf.Write(uint32Bytes(in.Sequence))
+	}
+	buf.Write(compactSize(uint64(len(tx.Outputs))))
+	for _, out := range tx.Outputs {
+		buf.Write(out.serialize())
+	}
+	if witness {
+		for _, in := range tx.Inputs {
+			buf.Write(compactSize(uint64(len(in.Witness))))
+			for _, item := range in.Witness {
+				buf.Write(varBytes(item))
+			}
+		}
+	}
+	buf.Write(uint32Bytes(tx.LockTime))
+	return buf.Bytes()
+}
+
+// TxHash double sha256 of the legacy serialization, internal byte order
+func (tx *Tx) TxHash() [32]byte {
+	var hash [32]byte
+	copy(hash[:], doubleSha256(tx.Serialize(false)))
+	return hash
+}
+
+func (out *TxOut) serialize() []byte {
+	return append(uint64Bytes(uint64(out.Value)), varBytes(out.PkScript)...)
+}
+
+func doubleSha256(b []byte) []byte {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:]
+}
+
+func compactSize(n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return []byte{byte(n)}
+	case n <= 0xffff:
+		b := make([]byte, 3)
+		b[0] = 0xfd
+		binary.LittleEndian.PutUint16(b[1:], uint16(n))
+		return b
+	case n <= 0xffffffff:
+		return append([]byte{0xfe}, uint32Bytes(uint32(n))...)
+	}
+	return append([]byte{0xff}, uint64Bytes(n)...)
+}
+
+func readCompactSize(r *bytes.Reader) (uint64, error) {
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch prefix {
+	case 0xfd:
+		b := make([]byte, 2)
+		if _, err = io.ReadFull(r, b); err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint16(b)), nil
+	case 0xfe:
+		n, err := readUint32(r)
+		return uint64(n), err
+	case 0xff:
+		return readUint64(r)
+	}
+	return uint64(prefix), nil
+}
+
+func varBytes(b []byte) []byte {
+	return append(compactSize(uint64(len(b))), b...)
+}
+
+func readVarBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readCompactSize(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+func uint32Bytes(n uint32) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, n)
+	return b
+}
+
+func uint64Bytes(n uint64) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, n)
+	return b
+}
+
+func readUint32(r *bytes.Reader) (uint32, error) {
+	b := make([]byte, 4)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(b), nil
+}
+
+func readUint64(r *bytes.Reader) (uint64, error) {
+	b := make([]byte, 8)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
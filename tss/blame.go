@@ -0,0 +1,32 @@
+package tss
+
+import "fmt"
+
+const (
+	BlameMessage    = "invalid message"
+	BlameCommitment = "commitment DeCommit fail"
+	BlameShare      = "invalid share"
+	BlameProof      = "schnorr verify fail"
+	BlameComplaint  = "false complaint"
+	BlameDuplicate  = "duplicate message"
+)
+
+// BlameError identifiable abort, Culprit sent the invalid message, Evidence is the offending message
+type BlameError struct {
+	Culprit  int
+	Reason   string
+	Evidence *Message
+}
+
+func (e *BlameError) Error() string {
+	return fmt.Sprintf("%s, participant %d", e.Reason, e.Culprit)
+}
+
+// NewBlameError blame the sender of msg
+func NewBlameError(reason string, msg *Message) *BlameError {
+	return &BlameError{
+		Culprit:  msg.From,
+		Reason:   reason,
+		Evidence: msg,
+	}
+}
@@ -0,0 +1,126 @@
+package sign
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/okx/threshold-lib/crypto/bls12381"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// Threshold BLS signature on BLS12-381, key shares from dkg over G1 (curves.BLS12381).
+// Step1 broadcast partial signature sigma_i = xi*H(m) in G2, no nonce is involved.
+// Step2 verify every partial with e(Xi, H(m)) = e(G1, sigma_i), sigma = sum(lambda_i*sigma_i) is the
+// signature of the group key, same bytes as Ethereum consensus signing with the full key.
+
+var (
+	curve = bls12381.G1()
+)
+
+// protocol id of the message envelope
+const protocol = "bls/sign"
+
+type BlsSign struct {
+	DeviceNumber int
+	Threshold    int
+	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
+
+	partList       []int // participating signers, at least threshold
+	shareI         *big.Int
+	publicKey      *curves.ECPoint
+	sharePubKeyMap map[int]*curves.ECPoint
+
+	message  []byte
+	H        *bls12381.G2Point
+	partials map[int]*bls12381.G2Point
+}
+
+// NewBlsSign partList is any subset of at least threshold participants, sharePubKeyMap from dkg verifies the partials
+//
+// Deprecated: messages are not bound to a session, use NewBlsSignWithSession.
+func NewBlsSign(deviceNumber, threshold int, partList []int, shareI *big.Int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint) *BlsSign {
+	return newBlsSign("", deviceNumber, threshold, partList, shareI, publicKey, sharePubKeyMap)
+}
+
+// NewBlsSignWithSession partList is any subset of at least threshold participants, sharePubKeyMap from dkg verifies the partials,
+// signers use the same unique sessionId
+func NewBlsSignWithSession(sessionId string, deviceNumber, threshold int, partList []int, shareI *big.Int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint) *BlsSign {
+	if sessionId == "" {
+		return nil
+	}
+	return newBlsSign(sessionId, deviceNumber, threshold, partList, shareI, publicKey, sharePubKeyMap)
+}
+
+func newBlsSign(sessionId string, deviceNumber, threshold int, partList []int, shareI *big.Int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint) *BlsSign {
+	if threshold < 2 || len(partList) < threshold || shareI == nil || publicKey == nil {
+		return nil
+	}
+	seen := make(map[int]bool, len(partList))
+	for _, id := range partList {
+		if id <= 0 || seen[id] || sharePubKeyMap[id] == nil {
+			return nil
+		}
+		seen[id] = true
+	}
+	if !seen[deviceNumber] {
+		return nil
+	}
+	ids := make([]int, len(partList))
+	copy(ids, partList)
+	sort.Ints(ids)
+	return &BlsSign{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		envelope:       tss.Envelope{Protocol: protocol, SessionId: sessionId},
+		partList:       ids,
+		shareI:         shareI,
+		publicKey:      publicKey,
+		sharePubKeyMap: sharePubKeyMap,
+	}
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *BlsSign) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+// PublicKey 48 bytes compressed group key, the Ethereum validator public key
+func (info *BlsSign) PublicKey() []byte {
+	return bls12381.G1Compress(info.publicKey.X, info.publicKey.Y)
+}
+
+func (info *BlsSign) isSigner(id int) bool {
+	for _, x := range info.partList {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+// lagrangian coefficient of id over partList at 0
+func (info *BlsSign) lagrangian(id int) *big.Int {
+	xList := make([]*big.Int, len(info.partList))
+	for i, x := range info.partList {
+		xList[i] = big.NewInt(int64(x))
+	}
+	return vss.CalLagrangian(curve, big.NewInt(int64(id)), big.NewInt(1), xList)
+}
+
+// verifyPartial e(Xi, H(m)) = e(G1, sigma_i)
+func (info *BlsSign) verifyPartial(id int, partial *bls12381.G2Point) bool {
+	X := info.sharePubKeyMap[id]
+	return bls12381.VerifyPoint(&bls12381.G1Point{X: X.X, Y: X.Y}, info.message, partial)
+}
+
+func checkPublicKey(p *curves.ECPoint) error {
+	if p == nil || !curve.InSubgroup(p.X, p.Y) {
+		return fmt.Errorf("invalid public key")
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package sign
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/okx/threshold-lib/crypto/bls12381"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlsSign(t *testing.T) {
+	// signing root of an Ethereum message
+	message := make([]byte, 32)
+	message[31] = 7
+	keyData := keyGen(t, 2, 3)
+	for _, partList := range [][]int{{1, 2}, {3, 1}, {1, 2, 3}} {
+		signature, publicKey := sign(t, 2, keyData, partList, message)
+		require.True(t, bls12381.Verify(publicKey, message, signature))
+
+		// same signature as the full key
+		sk := recoverKey(keyData, partList)
+		expected, err := bls12381.Sign(sk, message)
+		require.NoError(t, err)
+		require.Equal(t, expected, signature)
+	}
+}
+
+func TestBlsSignBlame(t *testing.T) {
+	keyData := keyGen(t, 2, 3)
+	infos, out1 := step1(t, 2, keyData, []int{1, 2}, []byte("message"))
+	// partial of another message
+	H, err := bls12381.HashToPoint([]byte("other"))
+	require.NoError(t, err)
+	bytes, _ := json.Marshal(Step1Data{Partial: hex.EncodeToString(H.ScalarMult(keyData[1].ShareI).Bytes())})
+	out1[2][1].Data = string(bytes)
+
+	_, err = infos[1].SignStep2(collect(out1, 1))
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 2, blame.Culprit)
+	require.Equal(t, tss.BlameShare, blame.Reason)
+}
+
+func sign(t *testing.T, threshold int, keyData []*tss.KeyStep3Data, partList []int, message []byte) ([]byte, []byte) {
+	infos, out1 := step1(t, threshold, keyData, partList, message)
+	var signature, publicKey []byte
+	for id, info := range infos {
+		sig, err := info.SignStep2(collect(out1, id))
+		require.NoError(t, err)
+		if signature != nil {
+			require.Equal(t, signature, sig)
+		}
+		signature, publicKey = sig, info.PublicKey()
+	}
+	return signature, publicKey
+}
+
+func step1(t *testing.T, threshold int, keyData []*tss.KeyStep3Data, partList []int, message []byte) (map[int]*BlsSign, map[int]map[int]*tss.Message) {
+	infos := make(map[int]*BlsSign)
+	for _, id := range partList {
+		data := keyData[id-1]
+		info := NewBlsSignWithSession("bls", id, threshold, partList, data.ShareI, data.PublicKey, data.SharePubKeyMap)
+		require.NotNil(t, info)
+		infos[id] = info
+	}
+	out1 := make(map[int]map[int]*tss.Message)
+	for id, info := range infos {
+		msgs, err := info.SignStep1(hex.EncodeToString(message))
+		require.NoError(t, err)
+		out1[id] = msgs
+	}
+	return infos, out1
+}
+
+func recoverKey(keyData []*tss.KeyStep3Data, partList []int) *big.Int {
+	xList := make([]*big.Int, len(partList))
+	for i, id := range partList {
+		xList[i] = big.NewInt(int64(id))
+	}
+	sk := new(big.Int)
+	for _, id := range partList {
+		sk.Add(sk, vss.CalLagrangian(curve, big.NewInt(int64(id)), keyData[id-1].ShareI, xList))
+	}
+	return sk.Mod(sk, curve.Params().N)
+}
+
+func collect(out map[int]map[int]*tss.Message, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for from, msgMap := range out {
+		if from != id {
+			msgs = append(msgs, msgMap[id])
+		}
+	}
+	return msgs
+}
+
+func keyGen(t *testing.T, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	out1 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out1[i+1] = msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out1, i+1))
+		require.NoError(t, err)
+		out2[i+1] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(out2, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
@@ -0,0 +1,49 @@
+package sign
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/bls12381"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step1Data struct {
+	Partial string // hex of 96 bytes compressed sigma_i
+}
+
+// SignStep1 message is hex, the raw signing root for Ethereum, broadcast partial signature xi*H(m)
+func (info *BlsSign) SignStep1(message string) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	msg, err := hex.DecodeString(message)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkPublicKey(info.publicKey); err != nil {
+		return nil, err
+	}
+	H, err := bls12381.HashToPoint(msg)
+	if err != nil {
+		return nil, err
+	}
+	partial := H.ScalarMult(info.shareI)
+	info.message = msg
+	info.H = H
+	info.partials = map[int]*bls12381.G2Point{info.DeviceNumber: partial}
+	info.RoundNumber = 2
+
+	bytes, err := info.envelope.MarshalData(Step1Data{Partial: hex.EncodeToString(partial.Bytes())})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]*tss.Message, len(info.partList)-1)
+	for _, id := range info.partList {
+		if id == info.DeviceNumber {
+			continue
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
+	}
+	return out, nil
+}
@@ -0,0 +1,65 @@
+package sign
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/bls12381"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// SignStep2 verify partial signatures, return 96 bytes signature sum(lambda_i*sigma_i)
+func (info *BlsSign) SignStep2(msgs []*tss.Message) ([]byte, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
+		if !info.isSigner(msg.From) || msg.From == info.DeviceNumber {
+			return nil, fmt.Errorf("unknown signer %d", msg.From)
+		}
+		if _, ok := info.partials[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, signer %d", msg.From)
+		}
+		var content Step1Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		bytes, err := hex.DecodeString(content.Partial)
+		if err != nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		partial, err := bls12381.G2FromBytes(bytes)
+		if err != nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		if !info.verifyPartial(msg.From, partial) {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
+		}
+		info.partials[msg.From] = partial
+	}
+
+	var sigma *bls12381.G2Point
+	for _, id := range info.partList {
+		term := info.partials[id].ScalarMult(info.lagrangian(id))
+		if sigma == nil {
+			sigma = term
+			continue
+		}
+		sigma = sigma.Add(term)
+	}
+	info.RoundNumber = -1
+	if !bls12381.VerifyPoint(&bls12381.G1Point{X: info.publicKey.X, Y: info.publicKey.Y}, info.message, sigma) {
+		return nil, fmt.Errorf("signature verify fail")
+	}
+	return sigma.Bytes(), nil
+}
@@ -0,0 +1,258 @@
+package channel
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// Optional authenticated and encrypted p2p layer for round messages, relays carry messages without seeing shares.
+// Each party has a long-term identity, a message is sealed with ECIES to the recipient
+// (secp256k1 ECDH, HMAC-SHA256 key derivation, AES-256-GCM) and signed by the sender with ed25519.
+// From, To, protocol, session, round and version stay in clear for routing and are authenticated.
+
+const label = "threshold-lib/channel/v1"
+
+var curve = secp256k1.S256()
+
+// PublicIdentity long-term public keys of a party, distributed out of band
+type PublicIdentity struct {
+	Id         int
+	SignKey    []byte          // ed25519 public key
+	EncryptKey *curves.ECPoint // secp256k1 public key
+}
+
+// Identity long-term keys of a party, must be stored securely
+type Identity struct {
+	Id         int
+	signKey    ed25519.PrivateKey
+	encryptKey *big.Int
+}
+
+// SealedData Message.Data of a sealed message
+type SealedData struct {
+	Ephemeral  *curves.ECPoint // ephemeral ECDH key
+	Ciphertext []byte
+	Signature  []byte // sender signature over header, ephemeral key and ciphertext
+}
+
+// Channel seal messages to peers and open messages from peers
+type Channel struct {
+	identity *Identity
+	peers    map[int]*PublicIdentity
+	format   tss.WireFormat // encoding of sealed data, json by default
+}
+
+// NewIdentity generate long-term keys of party id
+func NewIdentity(id int) (*Identity, error) {
+	_, signKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewIdentityFromKeys(id, signKey.Seed(), crypto.RandomNum(curve.N))
+}
+
+// NewIdentityFromKeys restore identity from the ed25519 seed and secp256k1 private key
+func NewIdentityFromKeys(id int, signSeed []byte, encryptKey *big.Int) (*Identity, error) {
+	if id <= 0 || len(signSeed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("identity params error")
+	}
+	if encryptKey == nil || encryptKey.Sign() <= 0 || encryptKey.Cmp(curve.N) >= 0 {
+		return nil, fmt.Errorf("invalid encrypt key")
+	}
+	return &Identity{
+		Id:         id,
+		signKey:    ed25519.NewKeyFromSeed(signSeed),
+		encryptKey: new(big.Int).Set(encryptKey),
+	}, nil
+}
+
+// Public public keys to distribute to the peers
+func (identity *Identity) Public() *PublicIdentity {
+	return &PublicIdentity{
+		Id:         identity.Id,
+		SignKey:    append([]byte{}, identity.signKey.Public().(ed25519.PublicKey)...),
+		EncryptKey: curves.ScalarToPoint(curve, identity.encryptKey),
+	}
+}
+
+// NewChannel channel of identity with the peers
+func NewChannel(identity *Identity, peers []*PublicIdentity) (*Channel, error) {
+	if identity == nil {
+		return nil, fmt.Errorf("identity is nil")
+	}
+	peerMap := make(map[int]*PublicIdentity, len(peers))
+	for _, peer := range peers {
+		if peer == nil || peer.Id <= 0 || peer.Id == identity.Id {
+			return nil, fmt.Errorf("invalid peer")
+		}
+		if _, ok := peerMap[peer.Id]; ok {
+			return nil, fmt.Errorf("duplicate peer %d", peer.Id)
+		}
+		if len(peer.SignKey) != ed25519.PublicKeySize || !onCurve(peer.EncryptKey) {
+			return nil, fmt.Errorf("invalid keys of peer %d", peer.Id)
+		}
+		peerMap[peer.Id] = peer
+	}
+	return &Channel{identity: identity, peers: peerMap}, nil
+}
+
+// SetWireFormat choose the encoding of sealed data, incoming sealed data is accepted in both formats
+func (c *Channel) SetWireFormat(format tss.WireFormat) error {
+	if err := format.Check(); err != nil {
+		return err
+	}
+	c.format = format
+	return nil
+}
+
+// Seal encrypt msg to the recipient and sign it
+func (c *Channel) Seal(msg *tss.Message) (*tss.Message, error) {
+	if msg == nil || msg.From != c.identity.Id {
+		return nil, fmt.Errorf("message is not from participant %d", c.identity.Id)
+	}
+	peer, ok := c.peers[msg.To]
+	if !ok {
+		return nil, fmt.Errorf("unknown peer %d", msg.To)
+	}
+	header, err := messageHeader(msg)
+	if err != nil {
+		return nil, err
+	}
+	k := crypto.RandomNum(curve.N)
+	ephemeral := curves.ScalarToPoint(curve, k)
+	aead, err := newAEAD(peer.EncryptKey.ScalarMult(k), ephemeral, peer.EncryptKey)
+	if err != nil {
+		return nil, err
+	}
+	data := &SealedData{
+		Ephemeral:  ephemeral,
+		Ciphertext: aead.Seal(nil, make([]byte, aead.NonceSize()), []byte(msg.Data), header),
+	}
+	data.Signature = ed25519.Sign(c.identity.signKey, signedBytes(header, data))
+	bytes, err := tss.MarshalData(c.format, data)
+	if err != nil {
+		return nil, err
+	}
+	sealed := *msg
+	sealed.Data = string(bytes)
+	return &sealed, nil
+}
+
+// Open verify the sender signature and decrypt msg
+func (c *Channel) Open(msg *tss.Message) (*tss.Message, error) {
+	if msg == nil || msg.To != c.identity.Id {
+		return nil, fmt.Errorf("message is not for participant %d", c.identity.Id)
+	}
+	peer, ok := c.peers[msg.From]
+	if !ok {
+		return nil, fmt.Errorf("unknown peer %d", msg.From)
+	}
+	var data SealedData
+	err := tss.UnmarshalData([]byte(msg.Data), &data)
+	if err != nil {
+		return nil, err
+	}
+	if !onCurve(data.Ephemeral) {
+		return nil, fmt.Errorf("invalid ephemeral key")
+	}
+	header, err := messageHeader(msg)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(peer.SignKey, signedBytes(header, &data), data.Signature) {
+		return nil, fmt.Errorf("signature of participant %d verify fail", msg.From)
+	}
+	aead, err := newAEAD(data.Ephemeral.ScalarMult(c.identity.encryptKey), data.Ephemeral, curves.ScalarToPoint(curve, c.identity.encryptKey))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, make([]byte, aead.NonceSize()), data.Ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("message decrypt fail")
+	}
+	opened := *msg
+	opened.Data = string(plaintext)
+	return &opened, nil
+}
+
+// SealAll seal the output of a step
+func (c *Channel) SealAll(msgs map[int]*tss.Message) (map[int]*tss.Message, error) {
+	out := make(map[int]*tss.Message, len(msgs))
+	for id, msg := range msgs {
+		sealed, err := c.Seal(msg)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = sealed
+	}
+	return out, nil
+}
+
+// OpenAll open the input of a step
+func (c *Channel) OpenAll(msgs []*tss.Message) ([]*tss.Message, error) {
+	out := make([]*tss.Message, len(msgs))
+	for i, msg := range msgs {
+		opened, err := c.Open(msg)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = opened
+	}
+	return out, nil
+}
+
+// messageHeader canonical encoding of the clear fields, authenticated by AEAD and signature
+func messageHeader(msg *tss.Message) ([]byte, error) {
+	return tss.Marshal(&tss.Message{
+		From:      msg.From,
+		To:        msg.To,
+		Protocol:  msg.Protocol,
+		SessionId: msg.SessionId,
+		Round:     msg.Round,
+		Version:   msg.Version,
+	})
+}
+
+func signedBytes(header []byte, data *SealedData) []byte {
+	out := append([]byte(label), header...)
+	out = append(out, pointBytes(data.Ephemeral)...)
+	return append(out, data.Ciphertext...)
+}
+
+// newAEAD AES-256-GCM with key HMAC-SHA256(shared.X, label | ephemeral | recipient), each key encrypts one message
+func newAEAD(shared, ephemeral, recipient *curves.ECPoint) (cipher.AEAD, error) {
+	mac := hmac.New(sha256.New, padded(shared.X))
+	mac.Write([]byte(label))
+	mac.Write(pointBytes(ephemeral))
+	mac.Write(pointBytes(recipient))
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func onCurve(point *curves.ECPoint) bool {
+	return point != nil && point.X != nil && point.Y != nil &&
+		curves.GetCurveName(point.Curve) == curves.Secp256k1 && curve.IsOnCurve(point.X, point.Y)
+}
+
+func pointBytes(point *curves.ECPoint) []byte {
+	return append(padded(point.X), padded(point.Y)...)
+}
+
+func padded(n *big.Int) []byte {
+	out := make([]byte, 32)
+	return n.FillBytes(out)
+}
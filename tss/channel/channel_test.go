@@ -0,0 +1,141 @@
+package channel
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func newChannels(t *testing.T, total int) []*Channel {
+	identities := make([]*Identity, total)
+	publics := make([]*PublicIdentity, total)
+	for i := range identities {
+		identity, err := NewIdentity(i + 1)
+		require.NoError(t, err)
+		identities[i], publics[i] = identity, identity.Public()
+	}
+	channels := make([]*Channel, total)
+	for i, identity := range identities {
+		var peers []*PublicIdentity
+		for j, public := range publics {
+			if j != i {
+				peers = append(peers, public)
+			}
+		}
+		channel, err := NewChannel(identity, peers)
+		require.NoError(t, err)
+		channels[i] = channel
+	}
+	return channels
+}
+
+func TestChannelKeyGen(t *testing.T) {
+	channels := newChannels(t, 3)
+	setUps := make([]*dkg.SetupInfo, 3)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("session", i+1, 2, 3, secp256k1.S256())
+	}
+	// relay sees only sealed messages
+	sealed := make([]map[int]*tss.Message, 3)
+	for i, setUp := range setUps {
+		out, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		sealed[i], err = channels[i].SealAll(out)
+		require.NoError(t, err)
+	}
+	inputs := func(id int) []*tss.Message {
+		var msgs []*tss.Message
+		for i := range sealed {
+			if i+1 != id {
+				msgs = append(msgs, sealed[i][id])
+			}
+		}
+		msgs, err := channels[id-1].OpenAll(msgs)
+		require.NoError(t, err)
+		return msgs
+	}
+	step2 := make([]map[int]*tss.Message, 3)
+	for i, setUp := range setUps {
+		out, err := setUp.DKGStep2(inputs(i + 1))
+		require.NoError(t, err)
+		step2[i] = out
+	}
+	for i := range setUps {
+		var err error
+		sealed[i], err = channels[i].SealAll(step2[i])
+		require.NoError(t, err)
+		for id, msg := range sealed[i] {
+			require.NotContains(t, msg.Data, step2[i][id].Data)
+			require.Equal(t, step2[i][id].Round, msg.Round)
+			require.Equal(t, "session", msg.SessionId)
+		}
+	}
+	var publicKeys []string
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(inputs(i + 1))
+		require.NoError(t, err)
+		publicKeys = append(publicKeys, data.PublicKey.X.String())
+	}
+	require.Equal(t, publicKeys[0], publicKeys[1])
+	require.Equal(t, publicKeys[0], publicKeys[2])
+}
+
+func TestChannelTamper(t *testing.T) {
+	channels := newChannels(t, 3)
+	envelope := &tss.Envelope{Protocol: "dkg", SessionId: "s"}
+	msg, err := channels[0].Seal(envelope.NewMessage(1, 2, 1, []byte("secret share")))
+	require.NoError(t, err)
+	require.False(t, strings.Contains(msg.Data, "secret share"))
+	opened, err := channels[1].Open(msg)
+	require.NoError(t, err)
+	require.Equal(t, "secret share", opened.Data)
+	require.Equal(t, 1, opened.Round)
+
+	// wrong recipient
+	_, err = channels[2].Open(msg)
+	require.Error(t, err)
+	// header fields are authenticated
+	other := *msg
+	other.Round = 2
+	_, err = channels[1].Open(&other)
+	require.Error(t, err)
+	other = *msg
+	other.From = 3
+	_, err = channels[1].Open(&other)
+	require.Error(t, err)
+	// ciphertext
+	var data SealedData
+	require.NoError(t, tss.UnmarshalData([]byte(msg.Data), &data))
+	data.Ciphertext[0] ^= 1
+	bytes, err := tss.MarshalData(tss.WireJSON, &data)
+	require.NoError(t, err)
+	other = *msg
+	other.Data = string(bytes)
+	_, err = channels[1].Open(&other)
+	require.Error(t, err)
+	// re-signed by another party
+	data.Ciphertext[0] ^= 1
+	data.Signature = ed25519Sign(channels[2], msg, &data)
+	// both wire formats are opened
+	bytes, err = tss.MarshalData(tss.WireBinary, &data)
+	require.NoError(t, err)
+	other.Data = string(bytes)
+	_, err = channels[1].Open(&other)
+	require.Error(t, err)
+
+	// only own messages are sealed
+	_, err = channels[0].Seal(envelope.NewMessage(2, 3, 1, nil))
+	require.Error(t, err)
+	_, err = channels[0].Seal(envelope.NewMessage(1, 4, 1, nil))
+	require.Error(t, err)
+}
+
+func ed25519Sign(c *Channel, msg *tss.Message, data *SealedData) []byte {
+	header, _ := messageHeader(msg)
+	return ed25519.Sign(c.identity.signKey, signedBytes(header, data))
+}
@@ -13,6 +13,11 @@ type Message struct {
 	From int
 	To   int
 	Data string
+
+	Protocol  string // protocol of the run, e.g. "dkg"
+	SessionId string // session of the run, agreed by all participants
+	Round     int    // step that produced the message
+	Version   int    // envelope version, MessageVersion
 }
 
 type KeyStep1Data struct {
@@ -0,0 +1,47 @@
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/okx/threshold-lib/crypto/keccak"
+)
+
+type Address [20]byte
+
+// PublicKeyToAddress keccak256(X | Y)[12:]
+func PublicKeyToAddress(publicKey *ecdsa.PublicKey) Address {
+	var address Address
+	hash := keccak.Sum256(publicKey.X.FillBytes(make([]byte, 32)), publicKey.Y.FillBytes(make([]byte, 32)))
+	copy(address[:], hash[12:])
+	return address
+}
+
+// HexToAddress 0x prefixed or plain hex, the EIP-55 checksum is not enforced
+func HexToAddress(s string) (Address, error) {
+	var address Address
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return address, err
+	}
+	if len(b) != len(address) {
+		return address, fmt.Errorf("invalid address length")
+	}
+	copy(address[:], b)
+	return address, nil
+}
+
+// Hex EIP-55 checksum encoding
+func (a Address) Hex() string {
+	lower := hex.EncodeToString(a[:])
+	hash := keccak.Sum256([]byte(lower))
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && (hash[i/2]>>(4*uint(1-i%2)))&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
@@ -0,0 +1,158 @@
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/ecdsa/keygen"
+	"github.com/okx/threshold-lib/tss/ecdsa/sign"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLegacyTx(t *testing.T) {
+	// EIP-155 example
+	to, _ := HexToAddress("0x3535353535353535353535353535353535353535")
+	value, _ := new(big.Int).SetString("1000000000000000000", 10)
+	tx := &LegacyTx{ChainID: big.NewInt(1), Nonce: 9, GasPrice: big.NewInt(20000000000), Gas: 21000, To: &to, Value: value}
+	require.Equal(t, "daf5a779ae972f972197303d7b574746c7ef83eadac0f2791ad23db92e4c8e53", hex.EncodeToString(tx.SigningHash()))
+	r, _ := new(big.Int).SetString("18515461264373351373200002665853028612451056578545711640558177340181847433846", 10)
+	s, _ := new(big.Int).SetString("46948507304638947509940763649030358759909902576025900602547168820602576006531", 10)
+	raw := "f86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83"
+	require.Equal(t, raw, hex.EncodeToString(tx.EncodeSigned(r, s, 0)))
+
+	// sender of the example, private key 0x4646...46
+	x, _ := new(big.Int).SetString("4646464646464646464646464646464646464646464646464646464646464646", 16)
+	X := curves.ScalarToPoint(secp256k1.S256(), x)
+	address := PublicKeyToAddress(&ecdsa.PublicKey{Curve: X.Curve, X: X.X, Y: X.Y})
+	require.Equal(t, "0x9d8A62f656a8d1615C1294fd71e9CFb3E4855A4F", address.Hex())
+	recovered, err := sign.RecoverPublicKey(secp256k1.S256(), tx.SigningHash(), r, s, 0)
+	require.NoError(t, err)
+	require.Equal(t, address, PublicKeyToAddress(recovered))
+}
+
+func TestTypedDataHash(t *testing.T) {
+	// EIP-712 example
+	mail := `{
+		"types": {
+			"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "version", "type": "string"},
+				{"name": "chainId", "type": "uint256"}, {"name": "verifyingContract", "type": "address"}],
+			"Person": [{"name": "name", "type": "string"}, {"name": "wallet", "type": "address"}],
+			"Mail": [{"name": "from", "type": "Person"}, {"name": "to", "type": "Person"}, {"name": "contents", "type": "string"}]
+		},
+		"primaryType": "Mail",
+		"domain": {"name": "Ether Mail", "version": "1", "chainId": 1, "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"},
+		"message": {
+			"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+			"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+			"contents": "Hello, Bob!"
+		}
+	}`
+	var td TypedData
+	require.NoError(t, json.Unmarshal([]byte(mail), &td))
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", td.EncodeType("Mail"))
+	domain, err := td.HashStruct("EIP712Domain", td.Domain)
+	require.NoError(t, err)
+	require.Equal(t, "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f", hex.EncodeToString(domain))
+	hash, err := td.Hash()
+	require.NoError(t, err)
+	require.Equal(t, "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", hex.EncodeToString(hash))
+}
+
+func TestSignWithMpcKey(t *testing.T) {
+	publicKey, signer := mpcSigner(t)
+	from := PublicKeyToAddress(publicKey)
+	to, _ := HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	require.Equal(t, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", to.Hex())
+
+	txs := []Transaction{
+		&LegacyTx{ChainID: big.NewInt(1), Nonce: 1, GasPrice: big.NewInt(1e9), Gas: 21000, To: &to, Value: big.NewInt(1)},
+		&AccessListTx{ChainID: big.NewInt(1), Nonce: 2, GasPrice: big.NewInt(1e9), Gas: 30000, To: &to, Value: big.NewInt(1),
+			AccessList: []AccessTuple{{Address: to, StorageKeys: [][32]byte{{1}}}}},
+		&DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 3, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(3e10), Gas: 21000, To: &to, Data: []byte{0xa9, 0x05}},
+	}
+	for _, tx := range txs {
+		var r, s *big.Int
+		var v byte
+		raw, err := SignTx(tx, func(hash []byte) (*big.Int, *big.Int, byte, error) {
+			var err error
+			r, s, v, err = signer(hash)
+			return r, s, v, err
+		})
+		require.NoError(t, err)
+		require.Equal(t, tx.EncodeSigned(r, s, v), raw)
+		recovered, err := sign.RecoverPublicKey(secp256k1.S256(), tx.SigningHash(), r, s, v)
+		require.NoError(t, err)
+		require.Equal(t, from, PublicKeyToAddress(recovered))
+	}
+
+	message := []byte("hello")
+	sig, err := SignPersonalMessage(message, signer)
+	require.NoError(t, err)
+	require.Equal(t, 65, len(sig))
+	recovered, err := sign.RecoverPublicKey(secp256k1.S256(), PersonalMessageHash(message),
+		new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64]), sig[64]-27)
+	require.NoError(t, err)
+	require.Equal(t, from, PublicKeyToAddress(recovered))
+}
+
+// mpcSigner 2-party ecdsa key, SignFunc runs P1 and P2 locally
+func mpcSigner(t *testing.T) (*ecdsa.PublicKey, SignFunc) {
+	curve := secp256k1.S256()
+	setUp1 := dkg.NewSetUpWithSession("keygen", 1, 2, 2, curve)
+	setUp2 := dkg.NewSetUpWithSession("keygen", 2, 2, 2, curve)
+	msgs1_1, _ := setUp1.DKGStep1()
+	msgs2_1, _ := setUp2.DKGStep1()
+	msgs1_2, _ := setUp1.DKGStep2([]*tss.Message{msgs2_1[1]})
+	msgs2_2, _ := setUp2.DKGStep2([]*tss.Message{msgs1_1[2]})
+	p1Data, err := setUp1.DKGStep3([]*tss.Message{msgs2_2[1]})
+	require.NoError(t, err)
+	p2Data, err := setUp2.DKGStep3([]*tss.Message{msgs1_2[2]})
+	require.NoError(t, err)
+
+	// safe primes are slow, the paillier key and pre-params of P1 come from the keygen testdata
+	bytes, err := os.ReadFile("../keygen/testdata/prekeys.json")
+	require.NoError(t, err)
+	var preKeys []struct {
+		PaiPriKey *paillier.PrivateKey
+		PreParams *keygen.PreParamsWithDlnProof
+	}
+	require.NoError(t, json.Unmarshal(bytes, &preKeys))
+	paiPrivate, preParams := preKeys[0].PaiPriKey, preKeys[0].PreParams
+	p1Dto, E_x1, err := keygen.P1(p1Data.ShareI, paiPrivate, 1, 2, preParams, preParams.PedersonParameters(), preParams.Proof)
+	require.NoError(t, err)
+	p2SaveData, err := keygen.P2(p2Data.ShareI, p2Data.PublicKey, p1Dto, 1, 2, preParams.PedersonParameters())
+	require.NoError(t, err)
+	publicKey := &ecdsa.PublicKey{Curve: curve, X: p2Data.PublicKey.X, Y: p2Data.PublicKey.Y}
+
+	return publicKey, func(hash []byte) (*big.Int, *big.Int, byte, error) {
+		message := hex.EncodeToString(hash)
+		p1 := sign.NewP1WithSession("sign-"+message, publicKey, message, paiPrivate, E_x1, preParams.PedersonParameters())
+		p2 := sign.NewP2WithSession("sign-"+message, p2SaveData.X2, p2SaveData.E_x1, publicKey, p2SaveData.PaiPubKey, message, p2SaveData.Ped1)
+		msg1, err := p1.SessionStep1()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		msg2, err := p2.SessionStep1(msg1)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		msg3, err := p1.SessionStep2(msg2)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		msg4, err := p2.SessionStep2(msg3)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		return p1.SessionStep3WithRecoveryId(msg4)
+	}
+}
@@ -0,0 +1,267 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/okx/threshold-lib/crypto/keccak"
+)
+
+// PersonalMessageHash EIP-191 version 0x45, keccak256("\x19Ethereum Signed Message:\n" | len(message) | message)
+func PersonalMessageHash(message []byte) []byte {
+	prefix := fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))
+	return keccak.Sum256([]byte(prefix), message)
+}
+
+// SignPersonalMessage 65 bytes r | s | 27 + v as personal_sign
+func SignPersonalMessage(message []byte, sign SignFunc) ([]byte, error) {
+	return signHash(PersonalMessageHash(message), sign)
+}
+
+type TypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TypedData EIP-712 typed structured data, the json of eth_signTypedData_v4
+type TypedData struct {
+	Types       map[string][]TypedDataField `json:"types"`
+	PrimaryType string                      `json:"primaryType"`
+	Domain      map[string]interface{}      `json:"domain"`
+	Message     map[string]interface{}      `json:"message"`
+}
+
+// Hash keccak256("\x19\x01" | hashStruct(domain) | hashStruct(message))
+func (td *TypedData) Hash() ([]byte, error) {
+	domain, err := td.HashStruct("EIP712Domain", td.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if td.PrimaryType == "EIP712Domain" {
+		return keccak.Sum256([]byte{0x19, 0x01}, domain), nil
+	}
+	message, err := td.HashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, err
+	}
+	return keccak.Sum256([]byte{0x19, 0x01}, domain, message), nil
+}
+
+// SignTypedData 65 bytes r | s | 27 + v as eth_signTypedData_v4
+func SignTypedData(td *TypedData, sign SignFunc) ([]byte, error) {
+	hash, err := td.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return signHash(hash, sign)
+}
+
+// HashStruct keccak256(typeHash | encodeData)
+func (td *TypedData) HashStruct(primaryType string, data map[string]interface{}) ([]byte, error) {
+	fields, ok := td.Types[primaryType]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %s", primaryType)
+	}
+	encoded := [][]byte{keccak.Sum256([]byte(td.EncodeType(primaryType)))}
+	for _, field := range fields {
+		value, err := td.encodeValue(field.Type, data[field.Name])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", primaryType, field.Name, err)
+		}
+		encoded = append(encoded, value)
+	}
+	return keccak.Sum256(encoded...), nil
+}
+
+// EncodeType primary type followed by the referenced struct types sorted by name
+func (td *TypedData) EncodeType(primaryType string) string {
+	deps := map[string]bool{}
+	td.dependencies(primaryType, deps)
+	delete(deps, primaryType)
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range append([]string{primaryType}, names...) {
+		fields := make([]string, len(td.Types[name]))
+		for i, field := range td.Types[name] {
+			fields[i] = field.Type + " " + field.Name
+		}
+		b.WriteString(name + "(" + strings.Join(fields, ",") + ")")
+	}
+	return b.String()
+}
+
+func (td *TypedData) dependencies(typ string, deps map[string]bool) {
+	typ = baseType(typ)
+	if deps[typ] {
+		return
+	}
+	if _, ok := td.Types[typ]; !ok {
+		return
+	}
+	deps[typ] = true
+	for _, field := range td.Types[typ] {
+		td.dependencies(field.Type, deps)
+	}
+}
+
+// baseType type without array suffixes
+func baseType(typ string) string {
+	if i := strings.Index(typ, "["); i >= 0 {
+		return typ[:i]
+	}
+	return typ
+}
+
+// encodeValue 32 bytes encoding of value
+func (td *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	if strings.HasSuffix(typ, "]") {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not an array", typ)
+		}
+		elemType := typ[:strings.LastIndex(typ, "[")]
+		encoded := make([][]byte, len(items))
+		for i, item := range items {
+			var err error
+			encoded[i], err = td.encodeValue(elemType, item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return keccak.Sum256(encoded...), nil
+	}
+	if _, ok := td.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s is not a struct", typ)
+		}
+		return td.HashStruct(typ, data)
+	}
+	switch {
+	case typ == "string":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("string expected")
+		}
+		return keccak.Sum256([]byte(s)), nil
+	case typ == "bytes":
+		b, err := hexBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return keccak.Sum256(b), nil
+	case typ == "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("bool expected")
+		}
+		out := make([]byte, 32)
+		if b {
+			out[31] = 1
+		}
+		return out, nil
+	case typ == "address":
+		b, err := hexBytes(value)
+		if err != nil || len(b) != 20 {
+			return nil, fmt.Errorf("address expected")
+		}
+		return append(make([]byte, 12), b...), nil
+	case strings.HasPrefix(typ, "bytes"):
+		size, err := strconv.Atoi(typ[5:])
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("unknown type %s", typ)
+		}
+		b, err := hexBytes(value)
+		if err != nil || len(b) != size {
+			return nil, fmt.Errorf("%s expected", typ)
+		}
+		return append(b, make([]byte, 32-size)...), nil
+	case strings.HasPrefix(typ, "uint"), strings.HasPrefix(typ, "int"):
+		signed := strings.HasPrefix(typ, "int")
+		bits, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(typ, "u"), "int"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, fmt.Errorf("unknown type %s", typ)
+		}
+		i, err := bigValue(value)
+		if err != nil {
+			return nil, err
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+		if signed {
+			limit.Rsh(limit, 1)
+			if i.Cmp(limit) >= 0 || i.Cmp(new(big.Int).Neg(limit)) < 0 {
+				return nil, fmt.Errorf("%s out of range", typ)
+			}
+			// two's complement
+			i = new(big.Int).Mod(i, new(big.Int).Lsh(big.NewInt(1), 256))
+		} else if i.Sign() < 0 || i.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("%s out of range", typ)
+		}
+		return i.FillBytes(make([]byte, 32)), nil
+	}
+	return nil, fmt.Errorf("unknown type %s", typ)
+}
+
+func hexBytes(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("0x hex string expected")
+	}
+	return hex.DecodeString(s[2:])
+}
+
+// bigValue integer from a json number, a decimal or 0x hex string
+func bigValue(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("integer expected")
+		}
+		return big.NewInt(int64(v)), nil
+	case json.Number:
+		return bigString(string(v))
+	case string:
+		return bigString(v)
+	case *big.Int:
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	}
+	return nil, fmt.Errorf("integer expected")
+}
+
+func bigString(s string) (*big.Int, error) {
+	i, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %s", s)
+	}
+	return i, nil
+}
+
+func signHash(hash []byte, sign SignFunc) ([]byte, error) {
+	r, s, v, err := sign(hash)
+	if err != nil {
+		return nil, err
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("recovery id %d can not be encoded", v)
+	}
+	out := make([]byte, 65)
+	r.FillBytes(out[:32])
+	s.FillBytes(out[32:64])
+	out[64] = 27 + v
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package ethereum
+
+import (
+	"math/big"
+)
+
+// Recursive length prefix encoding, https://ethereum.org/en/developers/docs/data-structures-and-encoding/rlp/
+
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+// rlpInt minimal big endian, zero is the empty string
+func rlpInt(i *big.Int) []byte {
+	if i == nil {
+		return rlpBytes(nil)
+	}
+	return rlpBytes(i.Bytes())
+}
+
+func rlpUint(i uint64) []byte {
+	return rlpInt(new(big.Int).SetUint64(i))
+}
+
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func rlpHeader(offset byte, length int) []byte {
+	if length < 56 {
+		return []byte{offset + byte(length)}
+	}
+	size := new(big.Int).SetInt64(int64(length)).Bytes()
+	return append([]byte{offset + 55 + byte(len(size))}, size...)
+}
@@ -0,0 +1,139 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/keccak"
+)
+
+// Ethereum transaction building and signing with a 2-party ECDSA key on secp256k1.
+// SignFunc runs the signing protocol over hash, e.g. NewP1WithSession(sessionId, publicKey, hex(hash), ...) to P1Context.SessionStep3WithRecoveryId.
+
+// SignFunc signature (r, s) and recovery id v over the 32 bytes hash
+type SignFunc func(hash []byte) (r, s *big.Int, v byte, err error)
+
+// Transaction unsigned transaction
+type Transaction interface {
+	// SigningHash keccak256 of the signing payload
+	SigningHash() []byte
+	// EncodeSigned raw transaction for eth_sendRawTransaction, v is the recovery id 0 or 1
+	EncodeSigned(r, s *big.Int, v byte) []byte
+}
+
+type AccessTuple struct {
+	Address     Address
+	StorageKeys [][32]byte
+}
+
+// LegacyTx EIP-155 transaction, ChainID nil is the pre EIP-155 format
+type LegacyTx struct {
+	ChainID  *big.Int
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *Address // nil creates a contract
+	Value    *big.Int
+	Data     []byte
+}
+
+// AccessListTx EIP-2930 transaction, type 0x01
+type AccessListTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         *Address
+	Value      *big.Int
+	Data       []byte
+	AccessList []AccessTuple
+}
+
+// DynamicFeeTx EIP-1559 transaction, type 0x02
+type DynamicFeeTx struct {
+	ChainID    *big.Int
+	Nonce      uint64
+	GasTipCap  *big.Int // maxPriorityFeePerGas
+	GasFeeCap  *big.Int // maxFeePerGas
+	Gas        uint64
+	To         *Address
+	Value      *big.Int
+	Data       []byte
+	AccessList []AccessTuple
+}
+
+func (tx *LegacyTx) fields() [][]byte {
+	return [][]byte{rlpUint(tx.Nonce), rlpInt(tx.GasPrice), rlpUint(tx.Gas), rlpTo(tx.To), rlpInt(tx.Value), rlpBytes(tx.Data)}
+}
+
+func (tx *LegacyTx) SigningHash() []byte {
+	fields := tx.fields()
+	if tx.ChainID != nil {
+		fields = append(fields, rlpInt(tx.ChainID), rlpUint(0), rlpUint(0))
+	}
+	return keccak.Sum256(rlpList(fields...))
+}
+
+// EncodeSigned V = 27 + v, or chainId*2 + 35 + v with EIP-155
+func (tx *LegacyTx) EncodeSigned(r, s *big.Int, v byte) []byte {
+	V := big.NewInt(27 + int64(v))
+	if tx.ChainID != nil {
+		V = new(big.Int).Add(new(big.Int).Lsh(tx.ChainID, 1), big.NewInt(35+int64(v)))
+	}
+	return rlpList(append(tx.fields(), rlpInt(V), rlpInt(r), rlpInt(s))...)
+}
+
+func (tx *AccessListTx) fields() [][]byte {
+	return [][]byte{rlpInt(tx.ChainID), rlpUint(tx.Nonce), rlpInt(tx.GasPrice), rlpUint(tx.Gas), rlpTo(tx.To), rlpInt(tx.Value), rlpBytes(tx.Data), rlpAccessList(tx.AccessList)}
+}
+
+func (tx *AccessListTx) SigningHash() []byte {
+	return keccak.Sum256([]byte{0x01}, rlpList(tx.fields()...))
+}
+
+func (tx *AccessListTx) EncodeSigned(r, s *big.Int, v byte) []byte {
+	return append([]byte{0x01}, rlpList(append(tx.fields(), rlpUint(uint64(v)), rlpInt(r), rlpInt(s))...)...)
+}
+
+func (tx *DynamicFeeTx) fields() [][]byte {
+	return [][]byte{rlpInt(tx.ChainID), rlpUint(tx.Nonce), rlpInt(tx.GasTipCap), rlpInt(tx.GasFeeCap), rlpUint(tx.Gas), rlpTo(tx.To), rlpInt(tx.Value), rlpBytes(tx.Data), rlpAccessList(tx.AccessList)}
+}
+
+func (tx *DynamicFeeTx) SigningHash() []byte {
+	return keccak.Sum256([]byte{0x02}, rlpList(tx.fields()...))
+}
+
+func (tx *DynamicFeeTx) EncodeSigned(r, s *big.Int, v byte) []byte {
+	return append([]byte{0x02}, rlpList(append(tx.fields(), rlpUint(uint64(v)), rlpInt(r), rlpInt(s))...)...)
+}
+
+func rlpTo(to *Address) []byte {
+	if to == nil {
+		return rlpBytes(nil)
+	}
+	return rlpBytes(to[:])
+}
+
+func rlpAccessList(list []AccessTuple) []byte {
+	items := make([][]byte, len(list))
+	for i, tuple := range list {
+		keys := make([][]byte, len(tuple.StorageKeys))
+		for j := range tuple.StorageKeys {
+			keys[j] = rlpBytes(tuple.StorageKeys[j][:])
+		}
+		items[i] = rlpList(rlpBytes(tuple.Address[:]), rlpList(keys...))
+	}
+	return rlpList(items...)
+}
+
+// SignTx sign the transaction, return the raw signed transaction
+func SignTx(tx Transaction, sign SignFunc) ([]byte, error) {
+	r, s, v, err := sign(tx.SigningHash())
+	if err != nil {
+		return nil, err
+	}
+	if v > 1 {
+		return nil, fmt.Errorf("recovery id %d can not be encoded", v)
+	}
+	return tx.EncodeSigned(r, s, v), nil
+}
@@ -0,0 +1,244 @@
+package keygen
+
+import (
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// protocol id of the message envelope
+const auxProtocol = "ecdsa/aux"
+
+// AuxSetupInfo after dkg, exchange paillier public keys and pedersen parameters for t/n signature
+type AuxSetupInfo struct {
+	DeviceNumber int
+	Total        int
+	RoundNumber  int
+
+	paiPriKey         *paillier.PrivateKey
+	preParamsAndProof *PreParamsWithDlnProof
+	pedMap            map[int]*pedersen.PedersenParameters
+	envelope          tss.Envelope // protocol and session of the messages
+}
+
+type AuxStep1Data struct {
+	Ped      *pedersen.PedersenParameters
+	DlnProof *zkp.DlnProof
+}
+
+type AuxStep2Data struct {
+	PaiPubKey          *paillier.PublicKey
+	BlumProof          *zkp.PaillierBlumProof
+	NoSmallFactorProof *zkp.NoSmallFactorProof
+}
+
+// AuxData paillier and pedersen information of all participants, save it with key share
+type AuxData struct {
+	Id        int
+	PaiPriKey *paillier.PrivateKey
+	PaiPubKey map[int]*paillier.PublicKey
+	Ped       map[int]*pedersen.PedersenParameters
+}
+
+// NewAuxSetUp paiPriKey and preParamsAndProof recommend to pre-generate locally
+//
+// Deprecated: messages are not bound to a session, use NewAuxSetUpWithSession.
+func NewAuxSetUp(deviceNumber, total int, paiPriKey *paillier.PrivateKey, preParamsAndProof *PreParamsWithDlnProof) *AuxSetupInfo {
+	return newAuxSetUp("", deviceNumber, total, paiPriKey, preParamsAndProof)
+}
+
+// NewAuxSetUpWithSession paiPriKey and preParamsAndProof recommend to pre-generate locally, participants use the same unique sessionId
+func NewAuxSetUpWithSession(sessionId string, deviceNumber, total int, paiPriKey *paillier.PrivateKey, preParamsAndProof *PreParamsWithDlnProof) *AuxSetupInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewAuxSetUp params error"))
+	}
+	return newAuxSetUp(sessionId, deviceNumber, total, paiPriKey, preParamsAndProof)
+}
+
+func newAuxSetUp(sessionId string, deviceNumber, total int, paiPriKey *paillier.PrivateKey, preParamsAndProof *PreParamsWithDlnProof) *AuxSetupInfo {
+	if total < 2 || deviceNumber > total || deviceNumber <= 0 {
+		panic(fmt.Errorf("NewAuxSetUp params error"))
+	}
+	if paiPriKey == nil || preParamsAndProof == nil {
+		panic(fmt.Errorf("NewAuxSetUp params error"))
+	}
+	return &AuxSetupInfo{
+		DeviceNumber:      deviceNumber,
+		Total:             total,
+		RoundNumber:       1,
+		paiPriKey:         paiPriKey,
+		preParamsAndProof: preParamsAndProof,
+		envelope:          tss.Envelope{Protocol: auxProtocol, SessionId: sessionId},
+	}
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *AuxSetupInfo) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+func (info *AuxSetupInfo) Ids() []int {
+	var ids []int
+	for i := 1; i <= info.Total; i++ {
+		ids = append(ids, i)
+	}
+	return ids
+}
+
+// checkSender sender must be another participant, a second message from the same sender is blamed
+func (info *AuxSetupInfo) checkSender(msg *tss.Message, received map[int]bool) error {
+	if msg.From < 1 || msg.From > info.Total || msg.From == info.DeviceNumber {
+		return fmt.Errorf("unknown participant %d", msg.From)
+	}
+	if received[msg.From] {
+		return tss.NewBlameError(tss.BlameDuplicate, msg)
+	}
+	received[msg.From] = true
+	return nil
+}
+
+// AuxStep1 send pedersen parameters and dln proof
+func (info *AuxSetupInfo) AuxStep1() (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	info.RoundNumber = 2
+
+	out := make(map[int]*tss.Message, info.Total-1)
+	for _, id := range info.Ids() {
+		if id == info.DeviceNumber {
+			continue
+		}
+		content := AuxStep1Data{
+			Ped:      info.preParamsAndProof.PedersonParameters(),
+			DlnProof: info.preParamsAndProof.Proof,
+		}
+		bytes, err := info.envelope.MarshalData(content)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
+	}
+	return out, nil
+}
+
+// AuxStep2 verify pedersen parameters, prove paillier key is well-formed under receiver's pedersen parameters
+func (info *AuxSetupInfo) AuxStep2(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (info.Total - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	info.pedMap = make(map[int]*pedersen.PedersenParameters, info.Total)
+	info.pedMap[info.DeviceNumber] = info.preParamsAndProof.PedersonParameters()
+	received := make(map[int]bool, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
+		if err := info.checkSender(msg, received); err != nil {
+			return nil, err
+		}
+		var content AuxStep1Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil {
+			return nil, err
+		}
+		if content.Ped == nil || content.DlnProof == nil {
+			return nil, fmt.Errorf("aux step1 data error, participant %d", msg.From)
+		}
+		if !zkp.DlnVerify(content.DlnProof, content.Ped.T, content.Ped.S, content.Ped.Ntilde) {
+			return nil, fmt.Errorf("dln proof verify fail, participant %d", msg.From)
+		}
+		info.pedMap[msg.From] = content.Ped
+	}
+
+	blumProof, err := zkp.PaillierBlumProve(info.paiPriKey.N, info.paiPriKey.P, info.paiPriKey.Q)
+	if err != nil {
+		return nil, fmt.Errorf("fail to generate blum proof due to error [%w]", err)
+	}
+	securityParams := &zkp.SecurityParameter{
+		Q_bitlen: 64,
+		Epsilon:  128,
+	}
+	info.RoundNumber = 3
+
+	out := make(map[int]*tss.Message, info.Total-1)
+	for _, id := range info.Ids() {
+		if id == info.DeviceNumber {
+			continue
+		}
+		noSmallFactorProof := zkp.NoSmallFactorProve(info.paiPriKey.N, info.paiPriKey.P, info.paiPriKey.Q, 16, info.pedMap[id], securityParams)
+		content := AuxStep2Data{
+			PaiPubKey:          &info.paiPriKey.PublicKey,
+			BlumProof:          blumProof,
+			NoSmallFactorProof: noSmallFactorProof,
+		}
+		bytes, err := info.envelope.MarshalData(content)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 2, bytes)
+	}
+	return out, nil
+}
+
+// AuxStep3 verify paillier public keys, return aux information
+func (info *AuxSetupInfo) AuxStep3(msgs []*tss.Message) (*AuxData, error) {
+	if info.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (info.Total - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	paiPubKeyMap := make(map[int]*paillier.PublicKey, info.Total)
+	paiPubKeyMap[info.DeviceNumber] = &info.paiPriKey.PublicKey
+	received := make(map[int]bool, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, err
+		}
+		if err := info.checkSender(msg, received); err != nil {
+			return nil, err
+		}
+		var content AuxStep2Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil {
+			return nil, err
+		}
+		if content.PaiPubKey == nil || content.PaiPubKey.N == nil {
+			return nil, fmt.Errorf("aux step2 data error, participant %d", msg.From)
+		}
+		// checking paillier keys correct size
+		bitlen := content.PaiPubKey.N.BitLen()
+		if bitlen != paillier.PrimeBits && bitlen != paillier.PrimeBits-1 {
+			return nil, fmt.Errorf("invalid paillier keys, participant %d", msg.From)
+		}
+		err = zkp.PaillierBlumVerify(content.PaiPubKey.N, content.BlumProof)
+		if err != nil {
+			return nil, fmt.Errorf("Blum proof verify fail due to error [%w], participant %d", err, msg.From)
+		}
+		if content.NoSmallFactorProof == nil || !zkp.NoSmallFactorVerify(content.PaiPubKey.N, content.NoSmallFactorProof, info.pedMap[info.DeviceNumber]) {
+			return nil, fmt.Errorf("No small factor verify fail, participant %d", msg.From)
+		}
+		paiPubKeyMap[msg.From] = content.PaiPubKey
+	}
+	info.RoundNumber = -1
+
+	return &AuxData{
+		Id:        info.DeviceNumber,
+		PaiPriKey: info.paiPriKey,
+		PaiPubKey: paiPubKeyMap,
+		Ped:       info.pedMap,
+	}, nil
+}
@@ -1,7 +1,10 @@
 package keygen
 
 import (
+	"crypto/elliptic"
+	"encoding/json"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/okx/threshold-lib/crypto/curves"
@@ -70,6 +73,11 @@ func TestKeyGen(t *testing.T) {
 	require.NoError(t, err)
 	fmt.Println("p2Data", p2Data)
 
+	// P1 data of secp256k1 is rejected for a P-256 key
+	p256Key := curves.ScalarToPoint(elliptic.P256(), p3SaveData.ShareI)
+	_, err = P2(p3SaveData.ShareI, p256Key, p1Data, setUp1.DeviceNumber, setUp3.DeviceNumber, p2PreParamsAndProof.PedersonParameters())
+	require.Error(t, err)
+
 	fmt.Println("=========bip32==========")
 	tssKey, err := bip32.NewTssKey(p1SaveData.ShareI, p1SaveData.PublicKey, p1SaveData.ChainCode)
 	require.NoError(t, err)
@@ -84,3 +92,57 @@ func TestKeyGen(t *testing.T) {
 	fmt.Println(tssKey.PublicKey())
 
 }
+
+func TestAuxDuplicateSender(t *testing.T) {
+	preKeys := loadPreKeys(t)
+	setUps := make([]*AuxSetupInfo, 3)
+	out := make([]map[int]*tss.Message, 3)
+	for i := range setUps {
+		setUps[i] = NewAuxSetUpWithSession("aux", i+1, 3, preKeys[i].PaiPriKey, preKeys[i].PreParams)
+		msgs, err := setUps[i].AuxStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	// two step1 messages of participant 2 pass the count check
+	_, err := setUps[0].AuxStep2([]*tss.Message{out[1][1], out[1][1]})
+	var blame *tss.BlameError
+	require.ErrorAs(t, err, &blame)
+	require.Equal(t, 2, blame.Culprit)
+	require.Equal(t, tss.BlameDuplicate, blame.Reason)
+	msg := *out[1][1]
+	msg.From = 4
+	_, err = setUps[0].AuxStep2([]*tss.Message{out[2][1], &msg})
+	require.Error(t, err)
+
+	next := make([]map[int]*tss.Message, 3)
+	for i, setUp := range setUps {
+		var msgs []*tss.Message
+		for j := range out {
+			if j != i {
+				msgs = append(msgs, out[j][i+1])
+			}
+		}
+		msgs2, err := setUp.AuxStep2(msgs)
+		require.NoError(t, err)
+		next[i] = msgs2
+	}
+	_, err = setUps[0].AuxStep3([]*tss.Message{next[2][1], next[2][1]})
+	require.ErrorAs(t, err, &blame)
+	require.Equal(t, 3, blame.Culprit)
+	require.Equal(t, tss.BlameDuplicate, blame.Reason)
+}
+
+// preKey paillier key and pedersen parameters of one party
+type preKey struct {
+	PaiPriKey *paillier.PrivateKey
+	PreParams *PreParamsWithDlnProof
+}
+
+// loadPreKeys one per party, safe primes are slow so testdata comes from GeneratePreParamsWithDlnProof and paillier.NewKeyPair once
+func loadPreKeys(t *testing.T) []*preKey {
+	bytes, err := os.ReadFile("testdata/prekeys.json")
+	require.NoError(t, err)
+	var preKeys []*preKey
+	require.NoError(t, json.Unmarshal(bytes, &preKeys))
+	return preKeys
+}
@@ -1,7 +1,7 @@
 package keygen
 
 import (
-	"encoding/json"
+	"crypto/elliptic"
 	"fmt"
 	"math/big"
 
@@ -18,7 +18,7 @@ import (
 
 var (
 	curve = secp256k1.S256()
-	G     = curves.ScalarToPoint(curve, big.NewInt(1))
+	G     = curves.ScalarToPoint(curve, big.NewInt(1)) // generator of secp256k1
 )
 
 type PreParams struct {
@@ -105,6 +105,14 @@ type P1Data struct {
 // P1 after dkg, prepare for 2-party signature, P1 send encrypt x1 to P2
 // RPC: paillier key pair generation is time-consuming, generated in advance, encrypted storage?
 func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParamsAndProof *PreParamsWithDlnProof, p2_ped *pedersen.PedersenParameters, p2_dlnproof *zkp.DlnProof) (*tss.Message, *big.Int, error) {
+	return P1WithCurve(curve, share1, paiPriKey, from, to, preParamsAndProof, p2_ped, p2_dlnproof)
+}
+
+// P1WithCurve P1 for a key of dkg over curve, e.g. elliptic.P256(), the curve must be registered in curves
+func P1WithCurve(curve elliptic.Curve, share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParamsAndProof *PreParamsWithDlnProof, p2_ped *pedersen.PedersenParameters, p2_dlnproof *zkp.DlnProof) (*tss.Message, *big.Int, error) {
+	if curves.GetCurveName(curve) == "" {
+		return nil, nil, fmt.Errorf("curve not supported")
+	}
 	if !zkp.DlnVerify(p2_dlnproof, p2_ped.T, p2_ped.S, p2_ped.Ntilde) {
 		return nil, nil, fmt.Errorf("fail to verify dln proof for p2 pederson parameters. ")
 	}
@@ -130,7 +138,7 @@ func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams
 	// PDLwSlackStatement
 	q_bitlen := uint(X1.Curve.Params().N.BitLen())
 	X1RangeProof := zkp.NewGroupElementPaillierEncryptionRangeProof(
-		paiPriKey.N, E_x1, x1, r, q_bitlen, X1, G, p2_ped, security_params,
+		paiPriKey.N, E_x1, x1, r, q_bitlen, X1, curves.ScalarToPoint(curve, big.NewInt(1)), p2_ped, security_params,
 	)
 	l := uint(16)
 	securty_params := &zkp.SecurityParameter{
@@ -155,7 +163,8 @@ func P1(share1 *big.Int, paiPriKey *paillier.PrivateKey, from, to int, preParams
 		X1RangeProof:       X1RangeProof,
 	}
 
-	bytes, err := json.Marshal(p1Data)
+	// one-shot payload is json, P2 accepts both wire formats
+	bytes, err := tss.MarshalData(tss.WireJSON, p1Data)
 	if err != nil {
 		return nil, nil, err
 	}
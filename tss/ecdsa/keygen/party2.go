@@ -1,7 +1,6 @@
 package keygen
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -24,16 +23,21 @@ type P2SaveData struct {
 	Ped2      *pedersen.PedersenParameters
 }
 
-// P2 after dkg, prepare for 2-party signature, P2 receives encrypt x1 and paillier public key from P1
+// P2 after dkg, prepare for 2-party signature, P2 receives encrypt x1 and paillier public key from P1.
+// The curve is the curve of publicKey
 func P2(share2 *big.Int, publicKey *curves.ECPoint, msg *tss.Message, from, to int, ped2 *pedersen.PedersenParameters) (*P2SaveData, error) {
 	if msg.From != from || msg.To != to {
 		return nil, fmt.Errorf("message mismatch")
 	}
 	p1Data := &P1Data{}
-	err := json.Unmarshal([]byte(msg.Data), p1Data)
+	err := tss.UnmarshalData([]byte(msg.Data), p1Data)
 	if err != nil {
 		return nil, err
 	}
+	curve := publicKey.Curve
+	if p1Data.X1 == nil || p1Data.X1.Curve != curve || p1Data.X1RangeProof == nil {
+		return nil, fmt.Errorf("p1 data curve mismatch")
+	}
 	// lagrangian interpolation x2, x = x1 + x2
 	x2 := vss.CalLagrangian(curve, big.NewInt(int64(to)), share2, []*big.Int{big.NewInt(int64(from)), big.NewInt(int64(to))})
 	X2 := curves.ScalarToPoint(curve, x2)
@@ -69,7 +73,13 @@ func P2(share2 *big.Int, publicKey *curves.ECPoint, msg *tss.Message, from, to i
 		return nil, fmt.Errorf("DlnProof for Ped1 verify fail")
 	}
 
-	ok = zkp.GroupElementPaillierEncryptionRangeVerify(p1Data.X1RangeProof, ped2)
+	// range proof is about X1 = x1*G of this curve
+	rangeProof := p1Data.X1RangeProof
+	if rangeProof.G == nil || !rangeProof.G.Equals(curves.ScalarToPoint(curve, big.NewInt(1))) || !rangeProof.X.Equals(p1Data.X1) ||
+		rangeProof.G.Curve != curve || rangeProof.X.Curve != curve {
+		return nil, fmt.Errorf("Group Element Paillier Encryption Range Proof statement mismatch")
+	}
+	ok = zkp.GroupElementPaillierEncryptionRangeVerify(rangeProof, ped2)
 	if !ok {
 		return nil, fmt.Errorf("Group Element Paillier Encryption Range Proof fail")
 	}
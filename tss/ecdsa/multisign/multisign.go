@@ -0,0 +1,177 @@
+package multisign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/ecdsa/keygen"
+)
+
+// protocol id of the message envelope
+const protocol = "ecdsa/multisign"
+
+// SignInfo t/n ecdsa signature, https://eprint.iacr.org/2021/060.pdf
+// k = sum(ki), gamma = sum(gamma_i), delta = k*gamma, chi = k*x, R = delta^-1 * gamma*G
+type SignInfo struct {
+	DeviceNumber int
+	Threshold    int
+	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
+	partList     []int        // participating signature number, at least threshold
+
+	curve          elliptic.Curve // curve of publicKey, secp256k1 or P-256
+	sessionID      *big.Int
+	wi             *big.Int // lagrangian interpolation share
+	publicKey      *ecdsa.PublicKey
+	sharePubKeyMap map[int]*curves.ECPoint
+	aux            *keygen.AuxData
+	message        string
+
+	ki, gammaI   *big.Int
+	rhoI         *big.Int // paillier randomness of Ki
+	H            *curves.ECPoint
+	kMap         map[int]*big.Int // paillier encrypt kj
+	gammaMap     map[int]*curves.ECPoint
+	betaMap      map[int]*big.Int
+	betaHatMap   map[int]*big.Int
+	deltaI, chiI *big.Int
+	gamma        *curves.ECPoint
+	r            *big.Int
+	sigmaI       *big.Int
+}
+
+// NewSign t/n signature init, ShareI and sharePubKeyMap come from dkg
+//
+// Deprecated: messages are not bound to a session, use NewSignWithSession.
+func NewSign(deviceNumber, threshold int, partList []int, ShareI *big.Int, publicKey *ecdsa.PublicKey,
+	sharePubKeyMap map[int]*curves.ECPoint, aux *keygen.AuxData, message string) *SignInfo {
+	return newSign("", deviceNumber, threshold, partList, ShareI, publicKey, sharePubKeyMap, aux, message)
+}
+
+// NewSignWithSession t/n signature init on the curve of publicKey, ShareI and sharePubKeyMap come from dkg, signers use the same unique sessionId
+func NewSignWithSession(sessionId string, deviceNumber, threshold int, partList []int, ShareI *big.Int, publicKey *ecdsa.PublicKey,
+	sharePubKeyMap map[int]*curves.ECPoint, aux *keygen.AuxData, message string) *SignInfo {
+	if sessionId == "" {
+		return nil
+	}
+	return newSign(sessionId, deviceNumber, threshold, partList, ShareI, publicKey, sharePubKeyMap, aux, message)
+}
+
+func newSign(sessionId string, deviceNumber, threshold int, partList []int, ShareI *big.Int, publicKey *ecdsa.PublicKey,
+	sharePubKeyMap map[int]*curves.ECPoint, aux *keygen.AuxData, message string) *SignInfo {
+	if threshold < 2 || len(partList) < threshold || aux == nil || aux.Id != deviceNumber {
+		return nil
+	}
+	if publicKey == nil || !supportedCurve(publicKey.Curve) {
+		return nil
+	}
+	curve := publicKey.Curve
+	msg, err := hex.DecodeString(message)
+	if err != nil {
+		return nil
+	}
+	xList := make([]*big.Int, 0, len(partList))
+	seen := make(map[int]bool, len(partList))
+	for _, id := range partList {
+		if seen[id] || sharePubKeyMap[id] == nil || sharePubKeyMap[id].Curve != curve || aux.PaiPubKey[id] == nil || aux.Ped[id] == nil {
+			return nil
+		}
+		seen[id] = true
+		xList = append(xList, big.NewInt(int64(id)))
+	}
+	if !seen[deviceNumber] {
+		return nil
+	}
+	// lagrangian interpolation wi
+	wi := vss.CalLagrangian(curve, big.NewInt(int64(deviceNumber)), ShareI, xList)
+
+	// sessionId binds publicKey, message and participants
+	input := append([]*big.Int{publicKey.X, publicKey.Y, new(big.Int).SetBytes(msg)}, xList...)
+	sessionID := crypto.SHA256Int(input...)
+
+	info := &SignInfo{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		envelope:       tss.Envelope{Protocol: protocol, SessionId: sessionId},
+		partList:       partList,
+		curve:          curve,
+		sessionID:      sessionID,
+		wi:             wi,
+		publicKey:      publicKey,
+		sharePubKeyMap: sharePubKeyMap,
+		aux:            aux,
+		message:        message,
+	}
+	return info
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *SignInfo) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+// lagrangianPoint return wj*G = lambda_j * Xj
+func (info *SignInfo) lagrangianPoint(id int) *curves.ECPoint {
+	xList := make([]*big.Int, len(info.partList))
+	for i, x := range info.partList {
+		xList[i] = big.NewInt(int64(x))
+	}
+	lambda := vss.CalLagrangian(info.curve, big.NewInt(int64(id)), big.NewInt(1), xList)
+	return info.sharePubKeyMap[id].ScalarMult(lambda)
+}
+
+// hashToPoint nothing-up-my-sleeve point with unknown discrete logarithm, try-and-increment
+func hashToPoint(curve elliptic.Curve, sessionId *big.Int) *curves.ECPoint {
+	params := curve.Params()
+	p := params.P
+	for i := int64(0); ; i++ {
+		hash := sha256.New()
+		hash.Write([]byte("multisign H"))
+		hash.Write(sessionId.Bytes())
+		hash.Write(big.NewInt(i).Bytes())
+		x := new(big.Int).Mod(new(big.Int).SetBytes(hash.Sum(nil)), p)
+
+		// y^2 = x^3 + a*x + b, a = 0 on secp256k1 and -3 on P-256
+		y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+		if curves.GetCurveName(curve) == curves.P256 {
+			y2.Sub(y2, new(big.Int).Mul(big.NewInt(3), x))
+		}
+		y2.Add(y2, params.B)
+		y := new(big.Int).ModSqrt(y2.Mod(y2, p), p)
+		if y == nil {
+			continue
+		}
+		// even y as the 0x02 compressed point
+		if y.Bit(0) == 1 {
+			y.Sub(p, y)
+		}
+		point, err := curves.NewECPoint(curve, x, y)
+		if err == nil {
+			return point
+		}
+	}
+}
+
+// supportedCurve secp256k1 or P-256
+func supportedCurve(curve elliptic.Curve) bool {
+	name := curves.GetCurveName(curve)
+	return name == curves.Secp256k1 || name == curves.P256
+}
+
+func (info *SignInfo) others() []int {
+	var ids []int
+	for _, id := range info.partList {
+		if id != info.DeviceNumber {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,175 @@
+package multisign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/ecdsa/keygen"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiSign(t *testing.T) {
+	threshold, total := 2, 3
+	auxData := auxGen(t, total)
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hex.EncodeToString(hash.Sum(nil))
+
+	keyData := keyGen(t, secp256k1.S256(), threshold, total)
+	for _, partList := range [][]int{{1, 2}, {1, 3}, {2, 3}, {1, 2, 3}} {
+		multiSign(t, keyData, auxData, threshold, partList, message)
+	}
+	// the curve is taken from the key
+	keyData = keyGen(t, elliptic.P256(), threshold, total)
+	multiSign(t, keyData, auxData, threshold, []int{1, 3}, message)
+}
+
+// multiSign run all steps with the parties of partList and verify the signatures
+func multiSign(t *testing.T, keyData []*tss.KeyStep3Data, auxData []*keygen.AuxData, threshold int, partList []int, message string) {
+	X := keyData[0].PublicKey
+	publicKey := &ecdsa.PublicKey{Curve: X.Curve, X: X.X, Y: X.Y}
+	signers := make([]*SignInfo, len(partList))
+	for i, id := range partList {
+		data := keyData[id-1]
+		signers[i] = NewSignWithSession("multisign", id, threshold, partList, data.ShareI, publicKey, data.SharePubKeyMap, auxData[id-1], message)
+		require.NotNil(t, signers[i])
+	}
+
+	out := make([]map[int]*tss.Message, len(signers))
+	for i, signer := range signers {
+		msgs, err := signer.SignStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	steps := []func(*SignInfo, []*tss.Message) (map[int]*tss.Message, error){
+		(*SignInfo).SignStep2, (*SignInfo).SignStep3, (*SignInfo).SignStep4,
+	}
+	for _, step := range steps {
+		next := make([]map[int]*tss.Message, len(signers))
+		for i, signer := range signers {
+			msgs, err := step(signer, collectMessages(out, partList, signer.DeviceNumber))
+			require.NoError(t, err)
+			next[i] = msgs
+		}
+		out = next
+	}
+	for _, signer := range signers {
+		r, s, err := signer.SignStep5(collectMessages(out, partList, signer.DeviceNumber))
+		require.NoError(t, err)
+		msg, _ := hex.DecodeString(message)
+		require.True(t, ecdsa.Verify(publicKey, msg, r, s))
+	}
+}
+
+func TestNewSignParams(t *testing.T) {
+	curve := secp256k1.S256()
+	keyData := keyGen(t, curve, 3, 4)
+	publicKey := &ecdsa.PublicKey{Curve: curve, X: keyData[0].PublicKey.X, Y: keyData[0].PublicKey.Y}
+	aux := &keygen.AuxData{Id: 1}
+	// less than threshold participants
+	require.Nil(t, NewSignWithSession("multisign", 1, 3, []int{1, 2}, keyData[0].ShareI, publicKey, keyData[0].SharePubKeyMap, aux, "00"))
+	// missing aux information
+	require.Nil(t, NewSignWithSession("multisign", 1, 3, []int{1, 2, 3}, keyData[0].ShareI, publicKey, keyData[0].SharePubKeyMap, aux, "00"))
+	// ed25519 is not ecdsa
+	edKey := &ecdsa.PublicKey{Curve: edwards.Edwards(), X: keyData[0].PublicKey.X, Y: keyData[0].PublicKey.Y}
+	require.Nil(t, NewSignWithSession("multisign", 1, 3, []int{1, 2, 3}, keyData[0].ShareI, edKey, keyData[0].SharePubKeyMap, aux, "00"))
+}
+
+func keyGen(t *testing.T, curve elliptic.Curve, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	ids := setUps[0].Ids()
+	out := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	next := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collectMessages(out, ids, i+1))
+		require.NoError(t, err)
+		next[i] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collectMessages(next, ids, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
+
+// preKey paillier key and pedersen parameters of one party
+type preKey struct {
+	PaiPriKey *paillier.PrivateKey
+	PreParams *keygen.PreParamsWithDlnProof
+}
+
+// preKeys one per party, safe primes are slow so they come from GeneratePreParamsWithDlnProof and paillier.NewKeyPair once
+var preKeys []*preKey
+
+func TestMain(m *testing.M) {
+	bytes, err := os.ReadFile("../keygen/testdata/prekeys.json")
+	if err == nil {
+		err = json.Unmarshal(bytes, &preKeys)
+	}
+	if err != nil {
+		fmt.Println("load prekeys:", err)
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+func auxGen(t *testing.T, total int) []*keygen.AuxData {
+	require.LessOrEqual(t, total, len(preKeys))
+	setUps := make([]*keygen.AuxSetupInfo, total)
+	for i := range setUps {
+		setUps[i] = keygen.NewAuxSetUpWithSession("aux", i+1, total, preKeys[i].PaiPriKey, preKeys[i].PreParams)
+	}
+	ids := setUps[0].Ids()
+	out := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.AuxStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	next := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.AuxStep2(collectMessages(out, ids, i+1))
+		require.NoError(t, err)
+		next[i] = msgs
+	}
+	auxData := make([]*keygen.AuxData, total)
+	for i, setUp := range setUps {
+		data, err := setUp.AuxStep3(collectMessages(next, ids, i+1))
+		require.NoError(t, err)
+		auxData[i] = data
+	}
+	return auxData
+}
+
+// collectMessages pick up the messages sent to id, out is ordered as partList
+func collectMessages(out []map[int]*tss.Message, partList []int, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for i, msgMap := range out {
+		if partList[i] == id {
+			continue
+		}
+		msgs = append(msgs, msgMap[id])
+	}
+	return msgs
+}
@@ -0,0 +1,70 @@
+package multisign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
+)
+
+var securityParams = &zkp.SecurityParameter{
+	Q_bitlen: 64,
+	Epsilon:  128,
+}
+
+type Step1Data struct {
+	K     *big.Int                                      // paillier encrypt ki
+	Proof *zkp.GroupElementPaillierEncryptionRangeProof // ki in range, X = ki*H
+}
+
+// SignStep1 p2p send paillier encrypt ki and range proof under receiver's pedersen parameters
+func (info *SignInfo) SignStep1() (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	// random generate ki, gamma_i
+	q := info.curve.Params().N
+	info.ki = crypto.RandomNum(q)
+	info.gammaI = crypto.RandomNum(q)
+	paiPubKey := &info.aux.PaiPriKey.PublicKey
+	K, rho, err := paiPubKey.Encrypt(info.ki)
+	if err != nil {
+		return nil, err
+	}
+	info.rhoI = rho
+	info.H = hashToPoint(info.curve, info.sessionID)
+	info.kMap = map[int]*big.Int{info.DeviceNumber: K}
+	KH := info.H.ScalarMult(info.ki)
+	info.RoundNumber = 2
+
+	out := make(map[int]*tss.Message, len(info.partList)-1)
+	for _, id := range info.others() {
+		proof := zkp.NewGroupElementPaillierEncryptionRangeProof(paiPubKey.N, K, info.ki, rho, uint(q.BitLen()), KH, info.H, info.aux.Ped[id], securityParams)
+		data := Step1Data{K: K, Proof: proof}
+		bytes, err := info.envelope.MarshalData(data)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
+	}
+	return out, nil
+}
+
+// verifyRangeProof check range proof statement is exactly (N, C, X, G), then verify
+func verifyRangeProof(proof *zkp.GroupElementPaillierEncryptionRangeProof, N, C *big.Int, X, G *curves.ECPoint, ped *pedersen.PedersenParameters) bool {
+	if proof == nil || proof.N0 == nil || proof.C == nil || proof.SecurityParams == nil ||
+		proof.X == nil || proof.G == nil || proof.Y == nil {
+		return false
+	}
+	if proof.N0.Cmp(N) != 0 || proof.C.Cmp(C) != 0 || !proof.G.Equals(G) || (X != nil && !proof.X.Equals(X)) {
+		return false
+	}
+	if proof.L != uint(G.Curve.Params().N.BitLen()) || *proof.SecurityParams != *securityParams {
+		return false
+	}
+	return zkp.GroupElementPaillierEncryptionRangeVerify(proof, ped)
+}
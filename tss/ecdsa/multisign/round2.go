@@ -0,0 +1,142 @@
+package multisign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step2Data struct {
+	Gamma      *curves.ECPoint // gamma_i*G
+	GammaProof *schnorr.Proof
+	D          *big.Int // Kj^gamma_i * E(beta')
+	DProof     *zkp.AffGProof
+	DHat       *big.Int // Kj^wi * E(beta_hat')
+	DHatProof  *zkp.AffGProof
+}
+
+// SignStep2 verify Kj range, p2p send MtA response for gamma_i and wi
+func (info *SignInfo) SignStep2(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
+		paiPubKey, ok := info.aux.PaiPubKey[msg.From]
+		if !ok || !info.isParticipant(msg.From) {
+			return nil, fmt.Errorf("unknown participant %d", msg.From)
+		}
+		if _, ok := info.kMap[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, participant %d", msg.From)
+		}
+		var data Step1Data
+		err := tss.UnmarshalData([]byte(msg.Data), &data)
+		if err != nil {
+			return nil, err
+		}
+		if data.K == nil || !verifyRangeProof(data.Proof, paiPubKey.N, data.K, nil, info.H, info.aux.Ped[info.DeviceNumber]) {
+			return nil, fmt.Errorf("range proof verify fail, participant %d", msg.From)
+		}
+		info.kMap[msg.From] = data.K
+	}
+
+	Gamma := curves.ScalarToPoint(info.curve, info.gammaI)
+	gammaProof, err := schnorr.ProveWithId(info.sessionID, info.gammaI, Gamma)
+	if err != nil {
+		return nil, err
+	}
+	q := info.curve.Params().N
+	info.betaMap = make(map[int]*big.Int, len(msgs))
+	info.betaHatMap = make(map[int]*big.Int, len(msgs))
+	info.RoundNumber = 3
+
+	out := make(map[int]*tss.Message, len(msgs))
+	for _, id := range info.others() {
+		paiPubKey := info.aux.PaiPubKey[id]
+		ped := info.aux.Ped[id]
+		D, DProof, betaPrime, err := mtaResponse(paiPubKey, ped, info.kMap[id], info.gammaI, Gamma)
+		if err != nil {
+			return nil, err
+		}
+		DHat, DHatProof, betaHatPrime, err := mtaResponse(paiPubKey, ped, info.kMap[id], info.wi, curves.ScalarToPoint(info.curve, info.wi))
+		if err != nil {
+			return nil, err
+		}
+		// kj*gamma_i = alpha_ji + beta_ij, beta_ij = -beta'
+		info.betaMap[id] = new(big.Int).Mod(new(big.Int).Neg(betaPrime), q)
+		info.betaHatMap[id] = new(big.Int).Mod(new(big.Int).Neg(betaHatPrime), q)
+
+		data := Step2Data{
+			Gamma:      Gamma,
+			GammaProof: gammaProof,
+			D:          D,
+			DProof:     DProof,
+			DHat:       DHat,
+			DHatProof:  DHatProof,
+		}
+		bytes, err := info.envelope.MarshalData(data)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 2, bytes)
+	}
+	return out, nil
+}
+
+// mtaResponse D = K^x * E(beta'), prove with receiver's pedersen parameters
+func mtaResponse(paiPubKey *paillier.PublicKey, ped *pedersen.PedersenParameters, K, x *big.Int, X *curves.ECPoint) (*big.Int, *zkp.AffGProof, *big.Int, error) {
+	betaPrime := crypto.RandomNum(new(big.Int).Lsh(big.NewInt(1), uint(zkp.L1_Aff_G)))
+	Kx, err := paiPubKey.HomoMulPlain(K, x)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	E_beta, rho, err := paiPubKey.Encrypt(betaPrime)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	D, err := paiPubKey.HomoAdd(Kx, E_beta)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	st := &zkp.AffGStatement{
+		N: paiPubKey.N,
+		C: K,
+		D: D,
+		X: X,
+		Y: curves.ScalarToPoint(X.Curve, betaPrime),
+	}
+	wit := &zkp.AffGWitness{
+		X:   x,
+		Y:   betaPrime,
+		Rho: rho,
+	}
+	proof := zkp.PaillierAffineProve(ped, st, wit)
+	return D, proof, betaPrime, nil
+}
+
+func (info *SignInfo) isParticipant(id int) bool {
+	if id == info.DeviceNumber {
+		return false
+	}
+	for _, i := range info.partList {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package multisign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step3Data struct {
+	Delta      *big.Int        // delta_i
+	DeltaPoint *curves.ECPoint // ki*Gamma
+	Proof      *zkp.GroupElementPaillierEncryptionRangeProof
+}
+
+// SignStep3 verify MtA response and compute delta_i, chi_i
+func (info *SignInfo) SignStep3(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	q := info.curve.Params().N
+	paiPriKey := info.aux.PaiPriKey
+	ped := info.aux.Ped[info.DeviceNumber]
+
+	// delta_i = ki*gamma_i + sum(alpha_ij + beta_ij), chi_i = ki*wi + sum(alpha_hat_ij + beta_hat_ij)
+	deltaI := new(big.Int).Mul(info.ki, info.gammaI)
+	chiI := new(big.Int).Mul(info.ki, info.wi)
+	Gamma := curves.ScalarToPoint(info.curve, info.gammaI)
+	info.gammaMap = map[int]*curves.ECPoint{info.DeviceNumber: Gamma}
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, err
+		}
+		if !info.isParticipant(msg.From) {
+			return nil, fmt.Errorf("unknown participant %d", msg.From)
+		}
+		if _, ok := info.gammaMap[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, participant %d", msg.From)
+		}
+		var data Step2Data
+		err := tss.UnmarshalData([]byte(msg.Data), &data)
+		if err != nil {
+			return nil, err
+		}
+		if data.Gamma == nil || data.D == nil || data.DHat == nil {
+			return nil, fmt.Errorf("step2 data error, participant %d", msg.From)
+		}
+		Gammaj, err := curves.NewECPoint(info.curve, data.Gamma.X, data.Gamma.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !schnorr.VerifyWithId(info.sessionID, data.GammaProof, Gammaj) {
+			return nil, fmt.Errorf("schnorr verify fail, participant %d", msg.From)
+		}
+		K := info.kMap[info.DeviceNumber]
+		if !verifyAffG(ped, data.DProof, paiPriKey.N, K, data.D, Gammaj) {
+			return nil, fmt.Errorf("paillier affine verify fail, participant %d", msg.From)
+		}
+		if !verifyAffG(ped, data.DHatProof, paiPriKey.N, K, data.DHat, info.lagrangianPoint(msg.From)) {
+			return nil, fmt.Errorf("paillier affine verify fail, participant %d", msg.From)
+		}
+		alpha, err := paiPriKey.Decrypt(data.D)
+		if err != nil {
+			return nil, err
+		}
+		alphaHat, err := paiPriKey.Decrypt(data.DHat)
+		if err != nil {
+			return nil, err
+		}
+		deltaI.Add(deltaI, alpha)
+		deltaI.Add(deltaI, info.betaMap[msg.From])
+		chiI.Add(chiI, alphaHat)
+		chiI.Add(chiI, info.betaHatMap[msg.From])
+
+		info.gammaMap[msg.From] = Gammaj
+		Gamma, err = Gamma.Add(Gammaj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	info.deltaI = new(big.Int).Mod(deltaI, q)
+	info.chiI = new(big.Int).Mod(chiI, q)
+	info.gamma = Gamma
+	// Delta_i = ki*Gamma
+	DeltaI := Gamma.ScalarMult(info.ki)
+	info.RoundNumber = 4
+
+	out := make(map[int]*tss.Message, len(msgs))
+	for _, id := range info.others() {
+		proof := zkp.NewGroupElementPaillierEncryptionRangeProof(paiPriKey.N, info.kMap[info.DeviceNumber], info.ki, info.rhoI, uint(q.BitLen()), DeltaI, Gamma, info.aux.Ped[id], securityParams)
+		data := Step3Data{
+			Delta:      info.deltaI,
+			DeltaPoint: DeltaI,
+			Proof:      proof,
+		}
+		bytes, err := info.envelope.MarshalData(data)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 3, bytes)
+	}
+	return out, nil
+}
+
+// verifyAffG D = C^x * E(y), x*G = X
+func verifyAffG(ped *pedersen.PedersenParameters, proof *zkp.AffGProof, N, C, D *big.Int, X *curves.ECPoint) bool {
+	if proof == nil || proof.A == nil || proof.E == nil || proof.S == nil || proof.F == nil || proof.T == nil ||
+		proof.Z1 == nil || proof.Z2 == nil || proof.Z3 == nil || proof.Z4 == nil || proof.W == nil ||
+		proof.Bx == nil || proof.By == nil || proof.Y == nil {
+		return false
+	}
+	st := &zkp.AffGStatement{
+		N: N,
+		C: C,
+		D: D,
+		X: X,
+		Y: proof.Y,
+	}
+	return zkp.PaillierAffineVerify(ped, proof, st)
+}
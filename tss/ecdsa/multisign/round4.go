@@ -0,0 +1,95 @@
+package multisign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/ecdsa/sign"
+)
+
+type Step4Data struct {
+	Sigma *big.Int
+}
+
+// SignStep4 check delta*G = sum(Delta_j), R = delta^-1 * Gamma, send sigma_i = ki*h + r*chi_i
+func (info *SignInfo) SignStep4(msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 4 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	q := info.curve.Params().N
+	delta := new(big.Int).Set(info.deltaI)
+	DeltaSum := info.gamma.ScalarMult(info.ki)
+	received := make(map[int]bool, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 3); err != nil {
+			return nil, err
+		}
+		if !info.isParticipant(msg.From) || received[msg.From] {
+			return nil, fmt.Errorf("unknown or duplicate participant %d", msg.From)
+		}
+		received[msg.From] = true
+		var data Step3Data
+		err := tss.UnmarshalData([]byte(msg.Data), &data)
+		if err != nil {
+			return nil, err
+		}
+		if data.Delta == nil || data.DeltaPoint == nil {
+			return nil, fmt.Errorf("step3 data error, participant %d", msg.From)
+		}
+		DeltaJ, err := curves.NewECPoint(info.curve, data.DeltaPoint.X, data.DeltaPoint.Y)
+		if err != nil {
+			return nil, err
+		}
+		// Delta_j = kj*Gamma, kj is the same as paillier encrypt Kj
+		N := info.aux.PaiPubKey[msg.From].N
+		if !verifyRangeProof(data.Proof, N, info.kMap[msg.From], DeltaJ, info.gamma, info.aux.Ped[info.DeviceNumber]) {
+			return nil, fmt.Errorf("range proof verify fail, participant %d", msg.From)
+		}
+		delta.Add(delta, data.Delta)
+		DeltaSum, err = DeltaSum.Add(DeltaJ)
+		if err != nil {
+			return nil, err
+		}
+	}
+	delta.Mod(delta, q)
+	if delta.Sign() == 0 || !curves.ScalarToPoint(info.curve, delta).Equals(DeltaSum) {
+		return nil, fmt.Errorf("delta verify fail")
+	}
+	// R = delta^-1 * Gamma = k^-1 * G
+	R := info.gamma.ScalarMult(new(big.Int).ModInverse(delta, q))
+	info.r = new(big.Int).Mod(R.X, q)
+	if info.r.Sign() == 0 {
+		return nil, fmt.Errorf("calculated R is zero")
+	}
+
+	bytes, err := hex.DecodeString(info.message)
+	if err != nil {
+		return nil, err
+	}
+	h := sign.CalculateM(bytes)
+	// sigma_i = ki*h + r*chi_i
+	sigmaI := new(big.Int).Mul(info.ki, h)
+	sigmaI.Add(sigmaI, new(big.Int).Mul(info.r, info.chiI))
+	info.sigmaI = sigmaI.Mod(sigmaI, q)
+	info.RoundNumber = 5
+
+	out := make(map[int]*tss.Message, len(msgs))
+	for _, id := range info.others() {
+		data := Step4Data{Sigma: info.sigmaI}
+		bytes, err := info.envelope.MarshalData(data)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 4, bytes)
+	}
+	return out, nil
+}
@@ -0,0 +1,63 @@
+package multisign
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/tss"
+)
+
+// SignStep5 s = sum(sigma_j), return ecdsa signature (r, s)
+func (info *SignInfo) SignStep5(msgs []*tss.Message) (*big.Int, *big.Int, error) {
+	if info.RoundNumber != 5 {
+		return nil, nil, fmt.Errorf("round error")
+	}
+	info.RoundNumber = -1
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, nil, fmt.Errorf("messages number error")
+	}
+	q := info.curve.Params().N
+	s := new(big.Int).Set(info.sigmaI)
+	received := make(map[int]bool, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 4); err != nil {
+			return nil, nil, err
+		}
+		if !info.isParticipant(msg.From) || received[msg.From] {
+			return nil, nil, fmt.Errorf("unknown or duplicate participant %d", msg.From)
+		}
+		received[msg.From] = true
+		var data Step4Data
+		err := tss.UnmarshalData([]byte(msg.Data), &data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if data.Sigma == nil {
+			return nil, nil, fmt.Errorf("step4 data error, participant %d", msg.From)
+		}
+		s.Add(s, data.Sigma)
+	}
+	s.Mod(s, q)
+
+	halfOrder := new(big.Int).Rsh(q, 1)
+	if s.Cmp(halfOrder) == 1 {
+		s.Sub(q, s)
+	}
+	if s.Sign() == 0 {
+		return nil, nil, fmt.Errorf("calculated S is zero")
+	}
+	message, err := hex.DecodeString(info.message)
+	if err != nil {
+		return nil, nil, err
+	}
+	// check ecdsa signature
+	if !ecdsa.Verify(info.publicKey, message, info.r, s) {
+		return nil, nil, fmt.Errorf("ecdsa sign verify fail")
+	}
+	return info.r, s, nil
+}
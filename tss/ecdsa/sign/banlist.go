@@ -1,34 +1,132 @@
 package sign
 
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
 // After the signature verification fails, it is forbidden to continue to sign
 // prevent attacks described in CVE-2023-33242 https://www.cve.org/CVERecord?id=CVE-2023-33242
+
+const (
+	BanAffineProof = "paillier affine verify fail"
+	BanSignVerify  = "ecdsa sign verify fail"
+	BanCommitment  = "commitment DeCommit fail"
+	BanProof       = "schnorr verify fail"
+)
+
+// BanEntry ban reason and evidence for forensics
+type BanEntry struct {
+	Id         string    // hex compressed publicKey
+	Party      int       // banned by 1: P1, 2: P2
+	Reason     string    // Ban* reasons
+	SessionId  string    // hex sessionID of the failed signature
+	Time       time.Time // ban time
+	Transcript string    // json of the offending messages
+}
+
+// BanStore ban list backend, implementations must be safe for concurrent use.
+// Add must not return nil unless the ban is recorded, otherwise signing continues after a failure.
+type BanStore interface {
+	Add(entry *BanEntry) error
+	Remove(id string) error
+	Has(id string) bool
+	Get(id string) *BanEntry
+	Clear() error
+	Export() []*BanEntry
+}
+
+// newBanEntry transcript is encoded as json, encoding error does not prevent the ban
+func newBanEntry(party int, publicKey *ecdsa.PublicKey, sessionID *big.Int, reason string, transcript interface{}) *BanEntry {
+	bytes, _ := json.Marshal(transcript)
+	return &BanEntry{
+		Id:         banId(publicKey),
+		Party:      party,
+		Reason:     reason,
+		SessionId:  hex.EncodeToString(sessionID.Bytes()),
+		Time:       time.Now().UTC(),
+		Transcript: string(bytes),
+	}
+}
+
+// banId compressed publicKey, a key and its negation have different ids
+func banId(publicKey *ecdsa.PublicKey) string {
+	return hex.EncodeToString(elliptic.MarshalCompressed(publicKey.Curve, publicKey.X, publicKey.Y))
+}
+
+// legacyBanId X coordinate, id of the earlier versions, it still bans both keys of X
+func legacyBanId(publicKey *ecdsa.PublicKey) string {
+	return hex.EncodeToString(publicKey.X.Bytes())
+}
+
+// checkBan signing is forbidden once the publicKey is banned
+func checkBan(store BanStore, publicKey *ecdsa.PublicKey) error {
+	id := banId(publicKey)
+	if store.Has(id) || store.Has(legacyBanId(publicKey)) {
+		return fmt.Errorf("ecdsa sign forbidden, publicKey " + id)
+	}
+	return nil
+}
+
+// addBan record the entry, the returned error also reports a failed write of the store
+func addBan(store BanStore, entry *BanEntry) error {
+	cause := errors.New(entry.Reason)
+	err := store.Add(entry)
+	if err != nil {
+		return fmt.Errorf("%v, ban list write fail: %v", cause, err)
+	}
+	return cause
+}
+
+// BanList ban list of the earlier versions, ids are hex publicKey X.
+//
+// Deprecated: BanList is not safe for concurrent use and keeps no evidence, use a BanStore.
 type BanList map[string]struct{}
 
+// BanSignList bans of signing contexts without SetBanStore, DefaultBanStore records them here too,
+// so an exported list imported on restart keeps working.
+//
+// Deprecated: use DefaultBanStore or SetBanStore.
 var BanSignList BanList = make(map[string]struct{})
 
+// Deprecated: use BanStore.Add.
 func (s BanList) Add(id string) {
 	s[id] = struct{}{}
 }
 
+// Deprecated: use BanStore.Remove.
 func (s BanList) Remove(id string) {
 	delete(s, id)
 }
 
+// Deprecated: use BanStore.Has.
 func (s BanList) Has(id string) bool {
 	_, ok := s[id]
 	return ok
 }
 
+// Deprecated: use BanStore.Clear.
 func (s BanList) Clear() {
 	s = make(map[string]struct{})
 }
 
+// Deprecated: use NewFileBanList to load a list.
 func (s BanList) Import(list []string) {
 	for _, id := range list {
 		s.Add(id)
 	}
 }
 
+// Deprecated: use BanStore.Export.
 func (s BanList) Export() []string {
 	var list []string
 	for key := range s {
@@ -36,3 +134,229 @@ func (s BanList) Export() []string {
 	}
 	return list
 }
+
+// DefaultBanStore store of signing contexts without SetBanStore, lost on process restart
+var DefaultBanStore BanStore = &defaultBanList{entries: NewMemoryBanList()}
+
+// defaultBanList entries with evidence in memory, ids in the deprecated BanSignList
+type defaultBanList struct {
+	lock    sync.RWMutex
+	entries *MemoryBanList
+}
+
+func (s *defaultBanList) Add(entry *BanEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	BanSignList.Add(entry.Id)
+	return s.entries.Add(entry)
+}
+
+func (s *defaultBanList) Remove(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	BanSignList.Remove(id)
+	return s.entries.Remove(id)
+}
+
+func (s *defaultBanList) Has(id string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return BanSignList.Has(id)
+}
+
+// Get ids imported into BanSignList have no evidence
+func (s *defaultBanList) Get(id string) *BanEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if !BanSignList.Has(id) {
+		return nil
+	}
+	if entry := s.entries.Get(id); entry != nil {
+		return entry
+	}
+	return &BanEntry{Id: id}
+}
+
+func (s *defaultBanList) Clear() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for id := range BanSignList {
+		delete(BanSignList, id)
+	}
+	return s.entries.Clear()
+}
+
+func (s *defaultBanList) Export() []*BanEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var list []*BanEntry
+	for id := range BanSignList {
+		entry := s.entries.Get(id)
+		if entry == nil {
+			entry = &BanEntry{Id: id}
+		}
+		list = append(list, entry)
+	}
+	return list
+}
+
+// MemoryBanList in-memory BanStore, lost on process restart
+type MemoryBanList struct {
+	lock sync.RWMutex
+	list map[string]*BanEntry
+}
+
+func NewMemoryBanList() *MemoryBanList {
+	return &MemoryBanList{list: make(map[string]*BanEntry)}
+}
+
+// Add the first entry of a publicKey is kept
+func (s *MemoryBanList) Add(entry *BanEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.list[entry.Id]; !ok {
+		s.list[entry.Id] = entry
+	}
+	return nil
+}
+
+func (s *MemoryBanList) Remove(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.list, id)
+	return nil
+}
+
+func (s *MemoryBanList) Has(id string) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, ok := s.list[id]
+	return ok
+}
+
+func (s *MemoryBanList) Get(id string) *BanEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.list[id]
+}
+
+func (s *MemoryBanList) Clear() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.list = make(map[string]*BanEntry)
+	return nil
+}
+
+func (s *MemoryBanList) Import(list []*BanEntry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, entry := range list {
+		s.list[entry.Id] = entry
+	}
+}
+
+func (s *MemoryBanList) Export() []*BanEntry {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var list []*BanEntry
+	for _, entry := range s.list {
+		list = append(list, entry)
+	}
+	return list
+}
+
+// FileBanList file-backed BanStore, every change is written to disk before it returns
+type FileBanList struct {
+	lock sync.Mutex
+	path string
+	mem  *MemoryBanList
+}
+
+// NewFileBanList load the ban list from path, a missing file is an empty list
+func NewFileBanList(path string) (*FileBanList, error) {
+	s := &FileBanList{path: path, mem: NewMemoryBanList()}
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*BanEntry
+	err = json.Unmarshal(bytes, &list)
+	if err != nil {
+		// list of publicKey ids without evidence
+		var ids []string
+		if json.Unmarshal(bytes, &ids) != nil {
+			return nil, fmt.Errorf("ban list file %s corrupted: %v", path, err)
+		}
+		for _, id := range ids {
+			list = append(list, &BanEntry{Id: id})
+		}
+	}
+	s.mem.Import(list)
+	return s, nil
+}
+
+func (s *FileBanList) Add(entry *BanEntry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// record in memory even if the write fails, the ban holds until restart at least
+	_ = s.mem.Add(entry)
+	return s.save()
+}
+
+func (s *FileBanList) Remove(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_ = s.mem.Remove(id)
+	return s.save()
+}
+
+func (s *FileBanList) Has(id string) bool {
+	return s.mem.Has(id)
+}
+
+func (s *FileBanList) Get(id string) *BanEntry {
+	return s.mem.Get(id)
+}
+
+func (s *FileBanList) Clear() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_ = s.mem.Clear()
+	return s.save()
+}
+
+func (s *FileBanList) Export() []*BanEntry {
+	return s.mem.Export()
+}
+
+// save write to a temporary file and rename, the file on disk is never partially written
+func (s *FileBanList) save() error {
+	list := s.mem.Export()
+	if list == nil {
+		list = []*BanEntry{}
+	}
+	bytes, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(bytes); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
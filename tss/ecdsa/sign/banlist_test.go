@@ -0,0 +1,139 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanList(t *testing.T) {
+	s := NewMemoryBanList()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = s.Add(&BanEntry{Id: fmt.Sprint(i)})
+			s.Has(fmt.Sprint(i))
+			s.Export()
+		}(i)
+	}
+	wg.Wait()
+	require.Len(t, s.Export(), 50)
+	require.NoError(t, s.Remove("1"))
+	require.False(t, s.Has("1"))
+	require.NoError(t, s.Clear())
+	require.Len(t, s.Export(), 0)
+}
+
+func TestFileBanList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banlist.json")
+	s, err := NewFileBanList(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(&BanEntry{Id: "a", Reason: BanSignVerify}))
+	require.NoError(t, s.Add(&BanEntry{Id: "b"}))
+	require.NoError(t, s.Remove("b"))
+
+	// restart keeps the ban
+	s, err = NewFileBanList(path)
+	require.NoError(t, err)
+	require.True(t, s.Has("a"))
+	require.Equal(t, BanSignVerify, s.Get("a").Reason)
+	require.False(t, s.Has("b"))
+
+	require.NoError(t, s.Clear())
+	s, err = NewFileBanList(path)
+	require.NoError(t, err)
+	require.False(t, s.Has("a"))
+
+	_, err = NewFileBanList(filepath.Join(t.TempDir(), "missing", "banlist.json"))
+	require.NoError(t, err)
+}
+
+func TestSetBanStore(t *testing.T) {
+	point := curves.ScalarToPoint(curve, crypto.RandomNum(curve.N))
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: point.X, Y: point.Y}
+	store, err := NewFileBanList(filepath.Join(t.TempDir(), "banlist.json"))
+	require.NoError(t, err)
+	require.NoError(t, store.Add(&BanEntry{Id: banId(pubKey)}))
+
+	p1 := NewP1WithSession("ban", pubKey, "00", nil, nil, nil).SetBanStore(store)
+	_, err = p1.SessionStep1()
+	require.Error(t, err)
+	// the default store is not affected
+	_, err = NewP1WithSession("ban", pubKey, "00", nil, nil, nil).SessionStep1()
+	require.NoError(t, err)
+
+	// the negation of a banned key has the same X and is not banned
+	negKey := &ecdsa.PublicKey{Curve: curve, X: pubKey.X, Y: new(big.Int).Sub(curve.P, pubKey.Y)}
+	_, err = NewP1WithSession("ban", negKey, "00", nil, nil, nil).SetBanStore(store).Step1()
+	require.NoError(t, err)
+	// X coordinate ids of earlier versions ban both keys
+	require.NoError(t, store.Add(&BanEntry{Id: hex.EncodeToString(pubKey.X.Bytes())}))
+	_, err = NewP1WithSession("ban", negKey, "00", nil, nil, nil).SetBanStore(store).Step1()
+	require.Error(t, err)
+}
+
+func TestBanSignList(t *testing.T) {
+	point := curves.ScalarToPoint(curve, crypto.RandomNum(curve.N))
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: point.X, Y: point.Y}
+	defer func() { require.NoError(t, DefaultBanStore.Clear()) }()
+
+	// a list exported by an earlier version is imported on restart
+	BanSignList.Import([]string{hex.EncodeToString(pubKey.X.Bytes())})
+	_, err := NewP1WithSession("ban", pubKey, "00", nil, nil, nil).Step1()
+	require.Error(t, err)
+	require.NoError(t, DefaultBanStore.Clear())
+	require.Empty(t, BanSignList.Export())
+
+	// bans of the default store are kept in BanSignList for export
+	require.NoError(t, DefaultBanStore.Add(&BanEntry{Id: banId(pubKey), Reason: BanSignVerify}))
+	require.True(t, BanSignList.Has(banId(pubKey)))
+	require.Equal(t, BanSignVerify, DefaultBanStore.Get(banId(pubKey)).Reason)
+	require.Len(t, DefaultBanStore.Export(), 1)
+	_, err = NewP1WithSession("ban", pubKey, "00", nil, nil, nil).SessionStep1()
+	require.Error(t, err)
+}
+
+func TestP2BanCommitment(t *testing.T) {
+	point := curves.ScalarToPoint(curve, crypto.RandomNum(curve.N))
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: point.X, Y: point.Y}
+	store := NewMemoryBanList()
+	p1 := NewP1WithSession("ban", pubKey, "00", nil, nil, nil).SetBanStore(NewMemoryBanList())
+	p2 := NewP2WithSession("ban", big.NewInt(1), nil, pubKey, nil, "00", nil).SetBanStore(store)
+
+	msg1, err := p1.SessionStep1()
+	require.NoError(t, err)
+	msg2, err := p2.SessionStep1(msg1)
+	require.NoError(t, err)
+	msg3, err := p1.SessionStep2(msg2)
+	require.NoError(t, err)
+
+	// P1 opens a different R1
+	var content Step3Data
+	require.NoError(t, tss.UnmarshalData([]byte(msg3.Data), &content))
+	content.Witness[2] = new(big.Int).Add(content.Witness[2], big.NewInt(1))
+	data, err := tss.MarshalData(tss.WireJSON, content)
+	require.NoError(t, err)
+	msg3.Data = string(data)
+	_, err = p2.SessionStep2(msg3)
+	require.Error(t, err)
+	entry := store.Get(banId(pubKey))
+	require.NotNil(t, entry)
+	require.Equal(t, 2, entry.Party)
+	require.Equal(t, BanCommitment, entry.Reason)
+	require.Equal(t, hex.EncodeToString(p2.sessionID.Bytes()), entry.SessionId)
+	require.NotEmpty(t, entry.Transcript)
+
+	_, err = p2.SessionStep1(msg1)
+	require.Error(t, err)
+}
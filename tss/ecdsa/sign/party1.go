@@ -2,6 +2,7 @@ package sign
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -14,14 +15,42 @@ import (
 	"github.com/okx/threshold-lib/crypto/pedersen"
 	"github.com/okx/threshold-lib/crypto/schnorr"
 	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
 )
 
 var (
-	curve = secp256k1.S256()
+	curve = secp256k1.S256() // default curve, contexts use the curve of publicKey
 )
 
+// protocol id of the message envelope, P1 is participant 1 and P2 is participant 2
+const (
+	protocol = "ecdsa/sign"
+	p1Id     = 1
+	p2Id     = 2
+)
+
+type Step1Data struct {
+	Commitment commitment.Commitment // commitment of R1 = k1*G
+}
+
+type Step2Data struct {
+	Proof *schnorr.Proof // proof of k2
+	R2    *curves.ECPoint
+}
+
+type Step3Data struct {
+	Proof   *schnorr.Proof // proof of k1
+	Witness commitment.Witness
+}
+
+type Step4Data struct {
+	E_k2_h_xr *big.Int // E[(h+xr)/k2]
+	AffGProof *zkp.AffGProof
+}
+
 type P1Context struct {
 	sessionID *big.Int
+	curve     elliptic.Curve // secp256k1 or P-256
 
 	publicKey *ecdsa.PublicKey
 	paiPriKey *paillier.PrivateKey
@@ -32,41 +61,103 @@ type P1Context struct {
 	cmtD    *commitment.Witness
 	E_x1    *big.Int
 	p1_ped  *pedersen.PedersenParameters
+
+	presign   bool   // presign context has no message, Step3 is replaced by P1Presignature.Sign
+	presignId string // cleared after presignature output
+
+	banStore BanStore     // DefaultBanStore if not set
+	envelope tss.Envelope // protocol and session of the messages
 }
 
-// NewP1 2-party signature, P1 init
+// NewP1 2-party signature, P1 init, the signature is on the curve of publicKey
+//
+// Deprecated: the session is not bound, use NewP1WithSession and the SessionStep methods.
 func NewP1(publicKey *ecdsa.PublicKey, message string, paiPriKey *paillier.PrivateKey, E_x1 *big.Int, p1_ped *pedersen.PedersenParameters) *P1Context {
+	return newP1("", publicKey, message, paiPriKey, E_x1, p1_ped)
+}
+
+// NewP1WithSession 2-party signature, P1 init, P1 and P2 use the same unique sessionId, messages of SessionStep are bound to it
+func NewP1WithSession(sessionId string, publicKey *ecdsa.PublicKey, message string, paiPriKey *paillier.PrivateKey, E_x1 *big.Int, p1_ped *pedersen.PedersenParameters) *P1Context {
+	if sessionId == "" {
+		return nil
+	}
+	return newP1(sessionId, publicKey, message, paiPriKey, E_x1, p1_ped)
+}
+
+func newP1(sessionId string, publicKey *ecdsa.PublicKey, message string, paiPriKey *paillier.PrivateKey, E_x1 *big.Int, p1_ped *pedersen.PedersenParameters) *P1Context {
+	if !supportedCurve(publicKey) {
+		return nil
+	}
 	msg, err := hex.DecodeString(message)
 	if err != nil {
 		return nil
 	}
 	data := new(big.Int).SetBytes(msg)
-	sessionId := crypto.SHA256Int(publicKey.X, publicKey.Y, data)
 
 	p1Context := &P1Context{
+		curve:     publicKey.Curve,
 		publicKey: publicKey,
 		message:   message,
 		paiPriKey: paiPriKey,
-		sessionID: sessionId,
+		sessionID: signSessionId(publicKey, sessionId, data),
 		E_x1:      E_x1,
 		p1_ped:    p1_ped,
+		envelope:  tss.Envelope{Protocol: protocol, SessionId: sessionId},
 	}
 	return p1Context
 }
 
+// SetBanStore use store instead of the in-memory DefaultBanStore, e.g. FileBanList to keep bans over restart
+func (p1 *P1Context) SetBanStore(store BanStore) *P1Context {
+	p1.banStore = store
+	return p1
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (p1 *P1Context) SetWireFormat(format tss.WireFormat) error {
+	return p1.envelope.SetWireFormat(format)
+}
+
+func (p1 *P1Context) bans() BanStore {
+	if p1.banStore != nil {
+		return p1.banStore
+	}
+	return DefaultBanStore
+}
+
+// signSessionId sessionId of commitment and proofs, bind publicKey, the session and message or presignId,
+// without session it is the one of earlier versions
+func signSessionId(publicKey *ecdsa.PublicKey, sessionId string, data *big.Int) *big.Int {
+	if sessionId == "" {
+		return crypto.SHA256Int(publicKey.X, publicKey.Y, data)
+	}
+	return crypto.SHA256Int(publicKey.X, publicKey.Y, data, new(big.Int).SetBytes([]byte(sessionId)))
+}
+
+// Step1 commit R1 = k1*G, send to P2
 func (p1 *P1Context) Step1() (*commitment.Commitment, error) {
-	if BanSignList.Has(hex.EncodeToString(p1.publicKey.X.Bytes())) {
-		return nil, fmt.Errorf("ecdsa sign forbidden, publicKey " + hex.EncodeToString(p1.publicKey.X.Bytes()))
+	if err := checkBan(p1.bans(), p1.publicKey); err != nil {
+		return nil, err
 	}
 	// random generate k1, k=k1*k2
-	p1.k1 = crypto.RandomNum(curve.N)
-	R1 := curves.ScalarToPoint(curve, p1.k1)
+	p1.k1 = crypto.RandomNum(p1.curve.Params().N)
+	R1 := curves.ScalarToPoint(p1.curve, p1.k1)
 	cmt := commitment.NewCommitment(p1.sessionID, R1.X, R1.Y)
 	p1.cmtD = &cmt.Msg
 	return &cmt.C, nil
 }
 
+// Step2 verify proof of k2, send proof of k1 and open the commitment
 func (p1 *P1Context) Step2(p2Proof *schnorr.Proof, R2 *curves.ECPoint) (*schnorr.Proof, *commitment.Witness, error) {
+	if p1.k1 == nil {
+		return nil, nil, fmt.Errorf("p1 step error, no nonce")
+	}
+	if p2Proof == nil {
+		return nil, nil, fmt.Errorf("step2 data error")
+	}
+	if R2 == nil || R2.Curve != p1.curve {
+		return nil, nil, fmt.Errorf("R2 curve mismatch")
+	}
 	// zk schnorr verify k2
 	verify := schnorr.VerifyWithId(p1.sessionID, p2Proof, R2)
 	if !verify {
@@ -74,7 +165,7 @@ func (p1 *P1Context) Step2(p2Proof *schnorr.Proof, R2 *curves.ECPoint) (*schnorr
 	}
 	p1.R2 = R2
 	// zk schnorr prove k1
-	R1 := curves.ScalarToPoint(curve, p1.k1)
+	R1 := curves.ScalarToPoint(p1.curve, p1.k1)
 	proof, err := schnorr.ProveWithId(p1.sessionID, p1.k1, R1)
 	if err != nil {
 		return nil, nil, err
@@ -83,7 +174,75 @@ func (p1 *P1Context) Step2(p2Proof *schnorr.Proof, R2 *curves.ECPoint) (*schnorr
 }
 
 func (p1 *P1Context) Step3(E_k2_h_xr *big.Int, affGProof *zkp.AffGProof) (*big.Int, *big.Int, error) {
-	q := curve.N
+	r, s, _, err := p1.Step3WithRecoveryId(E_k2_h_xr, affGProof)
+	return r, s, err
+}
+
+// Step3WithRecoveryId signature (r, s) and recovery id v, bit 0 is the parity of R.y after low-S, bit 1 is set if R.x >= N
+func (p1 *P1Context) Step3WithRecoveryId(E_k2_h_xr *big.Int, affGProof *zkp.AffGProof) (*big.Int, *big.Int, byte, error) {
+	if p1.presign {
+		return nil, nil, 0, fmt.Errorf("presign context, use P1Presignature.Sign")
+	}
+	if p1.k1 == nil || p1.R2 == nil {
+		return nil, nil, 0, fmt.Errorf("p1 step error, no nonce")
+	}
+	// R = k1*k2*G, k = k1*k2
+	R := p1.R2.ScalarMult(p1.k1)
+	return p1.finalizeSign(R, p1.message, E_k2_h_xr, affGProof)
+}
+
+// SessionStep1 Step1 as a message of the session
+func (p1 *P1Context) SessionStep1() (*tss.Message, error) {
+	if err := checkSession(&p1.envelope); err != nil {
+		return nil, err
+	}
+	cmtC, err := p1.Step1()
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(&p1.envelope, p1Id, p2Id, 1, Step1Data{Commitment: *cmtC})
+}
+
+// SessionStep2 Step2 on the message of P2 SessionStep1
+func (p1 *P1Context) SessionStep2(msg *tss.Message) (*tss.Message, error) {
+	var content Step2Data
+	if err := signContent(&p1.envelope, msg, p2Id, p1Id, 2, &content); err != nil {
+		return nil, err
+	}
+	proof, cmtD, err := p1.Step2(content.Proof, content.R2)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(&p1.envelope, p1Id, p2Id, 3, Step3Data{Proof: proof, Witness: *cmtD})
+}
+
+// SessionStep3 Step3 on the message of P2 SessionStep2
+func (p1 *P1Context) SessionStep3(msg *tss.Message) (*big.Int, *big.Int, error) {
+	r, s, _, err := p1.SessionStep3WithRecoveryId(msg)
+	return r, s, err
+}
+
+// SessionStep3WithRecoveryId Step3WithRecoveryId on the message of P2 SessionStep2
+func (p1 *P1Context) SessionStep3WithRecoveryId(msg *tss.Message) (*big.Int, *big.Int, byte, error) {
+	var content Step4Data
+	if err := signContent(&p1.envelope, msg, p2Id, p1Id, 4, &content); err != nil {
+		return nil, nil, 0, err
+	}
+	return p1.Step3WithRecoveryId(content.E_k2_h_xr, content.AffGProof)
+}
+
+// finalizeSign verify affine proof, decrypt s and check ecdsa signature
+func (p1 *P1Context) finalizeSign(R *curves.ECPoint, message string, E_k2_h_xr *big.Int, affGProof *zkp.AffGProof) (*big.Int, *big.Int, byte, error) {
+	q := p1.curve.Params().N
+	if p1.k1 == nil {
+		return nil, nil, 0, fmt.Errorf("p1 step error, no nonce")
+	}
+	if E_k2_h_xr == nil {
+		return nil, nil, 0, fmt.Errorf("step4 data error")
+	}
+	if affGProof == nil || affGProof.X == nil || affGProof.X.Curve != p1.curve {
+		return nil, nil, 0, fmt.Errorf("affine proof curve mismatch")
+	}
 	statement := &zkp.AffGStatement{
 		N: p1.paiPriKey.N,
 		C: p1.E_x1,
@@ -94,39 +253,75 @@ func (p1 *P1Context) Step3(E_k2_h_xr *big.Int, affGProof *zkp.AffGProof) (*big.I
 
 	verify := zkp.PaillierAffineVerify(p1.p1_ped, affGProof, statement)
 	if !verify {
-		BanSignList.Add(hex.EncodeToString(p1.publicKey.X.Bytes()))
-		return nil, nil, fmt.Errorf("paillier affine verify fail")
+		transcript := map[string]interface{}{"R": R, "E_k2_h_xr": E_k2_h_xr, "AffGProof": affGProof}
+		return nil, nil, 0, addBan(p1.bans(), newBanEntry(1, p1.publicKey, p1.sessionID, BanAffineProof, transcript))
 	}
 
-	// R = k1*k2*G, k = k1*k2
-	Rx, _ := curve.ScalarMult(p1.R2.X, p1.R2.Y, p1.k1.Bytes())
-	r := new(big.Int).Mod(Rx, q)
+	r := new(big.Int).Mod(R.X, q)
 	// paillier Decrypt (h+xr)/k2
 	k2_h_xr, err := p1.paiPriKey.Decrypt(E_k2_h_xr)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 	k1_1 := new(big.Int).ModInverse(p1.k1, q)
 	// s = (h+r*(x1+x2))/(k1*k2)
 	s := new(big.Int).Mod(new(big.Int).Mul(k2_h_xr, k1_1), q)
 
+	v := byte(R.Y.Bit(0))
+	if R.X.Cmp(q) >= 0 {
+		v |= 2
+	}
 	halfOrder := new(big.Int).Rsh(q, 1)
 	if s.Cmp(halfOrder) == 1 {
+		// -s is the signature of -R
 		s.Sub(q, s)
+		v ^= 1
 	}
 	if s.Sign() == 0 {
-		return nil, nil, fmt.Errorf("calculated S is zero")
+		return nil, nil, 0, fmt.Errorf("calculated S is zero")
 	}
-	message, err := hex.DecodeString(p1.message)
+	hash, err := hex.DecodeString(message)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, 0, err
 	}
 	// check ecdsa signature
-	ok := ecdsa.Verify(p1.publicKey, message, r, s)
+	ok := ecdsa.Verify(p1.publicKey, hash, r, s)
 	if !ok {
 		// IMPORTANT: If Verify fails, actively disallow signing to prevent attacks described in CVE-2023-33242
-		BanSignList.Add(hex.EncodeToString(p1.publicKey.X.Bytes()))
-		return nil, nil, fmt.Errorf("ecdsa sign verify fail")
+		transcript := map[string]interface{}{"R": R, "Message": message, "E_k2_h_xr": E_k2_h_xr, "AffGProof": affGProof, "r": r, "s": s}
+		return nil, nil, 0, addBan(p1.bans(), newBanEntry(1, p1.publicKey, p1.sessionID, BanSignVerify, transcript))
+	}
+	return r, s, v, nil
+}
+
+// checkSession messages of SessionStep need a context with session
+func checkSession(envelope *tss.Envelope) error {
+	if envelope.SessionId == "" {
+		return fmt.Errorf("session id is empty, use NewP1WithSession and NewP2WithSession")
+	}
+	return nil
+}
+
+func signMessage(envelope *tss.Envelope, from, to, round int, content interface{}) (*tss.Message, error) {
+	if err := checkSession(envelope); err != nil {
+		return nil, err
+	}
+	bytes, err := envelope.MarshalData(content)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.NewMessage(from, to, round, bytes), nil
+}
+
+func signContent(envelope *tss.Envelope, msg *tss.Message, from, to, round int, content interface{}) error {
+	if err := checkSession(envelope); err != nil {
+		return err
+	}
+	if msg == nil || msg.From != from || msg.To != to {
+		return fmt.Errorf("message mismatch")
+	}
+	if err := envelope.CheckMessage(msg, round); err != nil {
+		return err
 	}
-	return r, s, nil
+	return tss.UnmarshalData([]byte(msg.Data), content)
 }
@@ -2,6 +2,7 @@ package sign
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -13,10 +14,12 @@ import (
 	"github.com/okx/threshold-lib/crypto/pedersen"
 	"github.com/okx/threshold-lib/crypto/schnorr"
 	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
 )
 
 type P2Context struct {
 	sessionID *big.Int
+	curve     elliptic.Curve // secp256k1 or P-256
 
 	x2        *big.Int // x = x1 + x2
 	E_x1      *big.Int
@@ -26,35 +29,84 @@ type P2Context struct {
 	k2        *big.Int
 	cmtC      *commitment.Commitment
 	p1_ped    *pedersen.PedersenParameters
+
+	presign   bool   // presign context has no message, Step2 is replaced by PresignStep2
+	presignId string // cleared after presignature output
+
+	banStore BanStore     // DefaultBanStore if not set
+	envelope tss.Envelope // protocol and session of the messages
 }
 
-// NewP1 2-party signature, P2 init
+// NewP2 2-party signature, P2 init, the signature is on the curve of publicKey
+//
+// Deprecated: the session is not bound, use NewP2WithSession and the SessionStep methods.
 func NewP2(bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, paiPub *paillier.PublicKey, message string, p1_ped *pedersen.PedersenParameters) *P2Context {
+	return newP2("", bobPri, E_x1, publicKey, paiPub, message, p1_ped)
+}
+
+// NewP2WithSession 2-party signature, P2 init, P1 and P2 use the same unique sessionId, messages of SessionStep are bound to it
+func NewP2WithSession(sessionId string, bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, paiPub *paillier.PublicKey, message string, p1_ped *pedersen.PedersenParameters) *P2Context {
+	if sessionId == "" {
+		return nil
+	}
+	return newP2(sessionId, bobPri, E_x1, publicKey, paiPub, message, p1_ped)
+}
+
+func newP2(sessionId string, bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, paiPub *paillier.PublicKey, message string, p1_ped *pedersen.PedersenParameters) *P2Context {
+	if !supportedCurve(publicKey) {
+		return nil
+	}
 	msg, err := hex.DecodeString(message)
 	if err != nil {
 		return nil
 	}
 	data := new(big.Int).SetBytes(msg)
-	sessionId := crypto.SHA256Int(publicKey.X, publicKey.Y, data)
 
 	p2Context := &P2Context{
+		curve:     publicKey.Curve,
 		x2:        bobPri,
 		E_x1:      E_x1,
 		paiPub:    paiPub,
 		PublicKey: publicKey,
 		message:   message,
-		sessionID: sessionId,
+		sessionID: signSessionId(publicKey, sessionId, data),
 		p1_ped:    p1_ped,
+		envelope:  tss.Envelope{Protocol: protocol, SessionId: sessionId},
 	}
 	return p2Context
 }
 
+// SetBanStore use store instead of the in-memory DefaultBanStore, e.g. FileBanList to keep bans over restart
+func (p2 *P2Context) SetBanStore(store BanStore) *P2Context {
+	p2.banStore = store
+	return p2
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (p2 *P2Context) SetWireFormat(format tss.WireFormat) error {
+	return p2.envelope.SetWireFormat(format)
+}
+
+func (p2 *P2Context) bans() BanStore {
+	if p2.banStore != nil {
+		return p2.banStore
+	}
+	return DefaultBanStore
+}
+
+// Step1 receive the commitment of R1, send R2 = k2*G and proof of k2
 func (p2 *P2Context) Step1(cmtC *commitment.Commitment) (*schnorr.Proof, *curves.ECPoint, error) {
+	if err := checkBan(p2.bans(), p2.PublicKey); err != nil {
+		return nil, nil, err
+	}
+	if cmtC == nil || *cmtC == nil {
+		return nil, nil, fmt.Errorf("step1 data error")
+	}
 	p2.cmtC = cmtC
 
 	// random generate k2, k=k1*k2
-	p2.k2 = crypto.RandomNum(curve.N)
-	R2 := curves.ScalarToPoint(curve, p2.k2)
+	p2.k2 = crypto.RandomNum(p2.curve.Params().N)
+	R2 := curves.ScalarToPoint(p2.curve, p2.k2)
 	proof, err := schnorr.ProveWithId(p2.sessionID, p2.k2, R2)
 	if err != nil {
 		return nil, nil, err
@@ -62,38 +114,91 @@ func (p2 *P2Context) Step1(cmtC *commitment.Commitment) (*schnorr.Proof, *curves
 	return proof, R2, nil
 }
 
-// Step2 paillier encrypt compute, return E[(h+xr)/k2]
+// Step2 paillier encrypt compute, return E[(h+xr)/k2] and affine proof
 func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*big.Int, *zkp.AffGProof, error) {
-	q := curve.N
-	// check R1=k1*G commitment
+	if p2.presign {
+		return nil, nil, fmt.Errorf("presign context, use PresignStep2")
+	}
+	if p2.k2 == nil || p2.cmtC == nil {
+		return nil, nil, fmt.Errorf("p2 step error, no nonce")
+	}
+	R1, err := p2.openR1(cmtD, p1Proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	// R = k1*k2*G, k = k1*k2
+	R := R1.ScalarMult(p2.k2)
+	return p2.affineSignShare(R, p2.message)
+}
+
+// SessionStep1 Step1 on the message of P1 SessionStep1
+func (p2 *P2Context) SessionStep1(msg *tss.Message) (*tss.Message, error) {
+	var content Step1Data
+	if err := signContent(&p2.envelope, msg, p1Id, p2Id, 1, &content); err != nil {
+		return nil, err
+	}
+	proof, R2, err := p2.Step1(&content.Commitment)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(&p2.envelope, p2Id, p1Id, 2, Step2Data{Proof: proof, R2: R2})
+}
+
+// SessionStep2 Step2 on the message of P1 SessionStep2
+func (p2 *P2Context) SessionStep2(msg *tss.Message) (*tss.Message, error) {
+	var content Step3Data
+	if err := signContent(&p2.envelope, msg, p1Id, p2Id, 3, &content); err != nil {
+		return nil, err
+	}
+	E_k2_h_xr, affGProof, err := p2.Step2(&content.Witness, content.Proof)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(&p2.envelope, p2Id, p1Id, 4, Step4Data{E_k2_h_xr: E_k2_h_xr, AffGProof: affGProof})
+}
+
+// openR1 check R1=k1*G commitment and schnorr proof
+func (p2 *P2Context) openR1(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*curves.ECPoint, error) {
+	if p1Proof == nil || cmtD == nil || len(*cmtD) == 0 {
+		return nil, fmt.Errorf("step3 data error")
+	}
 	commit := commitment.HashCommitment{}
 	commit.C = *p2.cmtC
 	commit.Msg = *cmtD
 	ok, commitD := commit.Open()
 	if !ok {
-		return nil, nil, fmt.Errorf("commitment DeCommit fail")
+		transcript := map[string]interface{}{"C": p2.cmtC, "D": cmtD}
+		return nil, addBan(p2.bans(), newBanEntry(2, p2.PublicKey, p2.sessionID, BanCommitment, transcript))
 	}
-	if commitD[0].Cmp(p2.sessionID) != 0 {
-		return nil, nil, fmt.Errorf("p2 Step2 commitment sessionId error")
+	if len(commitD) != 3 || commitD[0].Cmp(p2.sessionID) != 0 {
+		return nil, fmt.Errorf("p2 Step2 commitment sessionId error")
 	}
-	R1, err := curves.NewECPoint(curve, commitD[1], commitD[2])
+	R1, err := curves.NewECPoint(p2.curve, commitD[1], commitD[2])
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	verify := schnorr.VerifyWithId(p2.sessionID, p1Proof, R1)
 	if !verify {
-		return nil, nil, fmt.Errorf("schnorr verify fail")
+		transcript := map[string]interface{}{"C": p2.cmtC, "D": cmtD, "Proof": p1Proof}
+		return nil, addBan(p2.bans(), newBanEntry(2, p2.PublicKey, p2.sessionID, BanProof, transcript))
 	}
-	// R = k1*k2*G, k = k1*k2
-	Rx, _ := curve.ScalarMult(R1.X, R1.Y, p2.k2.Bytes())
-	r := new(big.Int).Mod(Rx, q)
-	bytes, err := hex.DecodeString(p2.message)
+	return R1, nil
+}
+
+// affineSignShare R = k1*k2*G, return E[(h+xr)/k2] and affine proof
+func (p2 *P2Context) affineSignShare(R *curves.ECPoint, message string) (*big.Int, *zkp.AffGProof, error) {
+	if p2.k2 == nil {
+		return nil, nil, fmt.Errorf("p2 step error, no nonce")
+	}
+	q := p2.curve.Params().N
+	r := new(big.Int).Mod(R.X, q)
+	bytes, err := hex.DecodeString(message)
 	if err != nil {
 		return nil, nil, err
 	}
 	k2_1 := new(big.Int).ModInverse(p2.k2, q)
 
-	h := CalculateM(bytes)
+	h := hashToInt(q, bytes)
 	h = new(big.Int).Mul(h, k2_1) // h/k2
 
 	rho := crypto.RandomNum(new(big.Int).Mul(q, q))
@@ -112,8 +217,8 @@ func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*b
 	a_x1, _ := paiPubKey.HomoMulPlain(p2.E_x1, a)
 	a_x1_b, _ := paiPubKey.HomoAddPlain(a_x1, b)
 	E_k2_h_xr := new(big.Int).Mod(new(big.Int).Mul(a_x1_b, new(big.Int).Exp(rnd, paiPubKey.N, N2)), N2)
-	A := curves.ScalarToPoint(curve, a)
-	B := curves.ScalarToPoint(curve, b)
+	A := curves.ScalarToPoint(p2.curve, a)
+	B := curves.ScalarToPoint(p2.curve, b)
 
 	st := &zkp.AffGStatement{
 		N: paiPubKey.N,
@@ -133,8 +238,14 @@ func (p2 *P2Context) Step2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*b
 	return E_k2_h_xr, aff_g_proof, nil
 }
 
+// CalculateM message hash to integer for secp256k1
 func CalculateM(hash []byte) *big.Int {
-	orderBits := curve.N.BitLen()
+	return hashToInt(curve.N, hash)
+}
+
+// hashToInt leftmost bits of hash as crypto/ecdsa, q is the curve order
+func hashToInt(q *big.Int, hash []byte) *big.Int {
+	orderBits := q.BitLen()
 	orderBytes := (orderBits + 7) / 8
 	if len(hash) > orderBytes {
 		hash = hash[:orderBytes]
@@ -146,3 +257,12 @@ func CalculateM(hash []byte) *big.Int {
 	}
 	return ret
 }
+
+// supportedCurve publicKey on a registered curve, secp256k1 or P-256
+func supportedCurve(publicKey *ecdsa.PublicKey) bool {
+	if publicKey == nil || publicKey.Curve == nil {
+		return false
+	}
+	name := curves.GetCurveName(publicKey.Curve)
+	return name == curves.Secp256k1 || name == curves.P256
+}
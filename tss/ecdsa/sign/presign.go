@@ -0,0 +1,229 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// Presignature offline phase of 2-party signature: nonce commitment and schnorr proofs are message independent,
+// P1.Step1, P2.Step1, P1.Step2 and P2.PresignStep2 run ahead of time, online phase is one message P2 -> P1.
+// The presignId is the session of the SessionStep messages.
+// A presignature must be used only once, otherwise the private key leaks.
+
+// P1Presignature P1 presignature record, single-use
+type P1Presignature struct {
+	Id string
+	R  *curves.ECPoint // k1*k2*G
+
+	p1   *P1Context
+	lock sync.Mutex
+	used bool
+}
+
+// P2Presignature P2 presignature record, single-use
+type P2Presignature struct {
+	Id string
+	R  *curves.ECPoint // k1*k2*G
+
+	p2   *P2Context
+	lock sync.Mutex
+	used bool
+}
+
+// presignSessionId sessionId bind publicKey and presignId instead of message
+func presignSessionId(publicKey *ecdsa.PublicKey, presignId string) *big.Int {
+	return crypto.SHA256Int(publicKey.X, publicKey.Y, new(big.Int).SetBytes([]byte(presignId)))
+}
+
+// NewP1Presign 2-party presignature, P1 init, presignId must be unique and the same for P1 and P2
+func NewP1Presign(publicKey *ecdsa.PublicKey, presignId string, paiPriKey *paillier.PrivateKey, E_x1 *big.Int, p1_ped *pedersen.PedersenParameters) *P1Context {
+	if presignId == "" || !supportedCurve(publicKey) {
+		return nil
+	}
+	return &P1Context{
+		curve:     publicKey.Curve,
+		publicKey: publicKey,
+		paiPriKey: paiPriKey,
+		sessionID: presignSessionId(publicKey, presignId),
+		E_x1:      E_x1,
+		p1_ped:    p1_ped,
+		presign:   true,
+		presignId: presignId,
+		envelope:  tss.Envelope{Protocol: protocol, SessionId: presignId},
+	}
+}
+
+// NewP2Presign 2-party presignature, P2 init, presignId must be unique and the same for P1 and P2
+func NewP2Presign(bobPri, E_x1 *big.Int, publicKey *ecdsa.PublicKey, paiPub *paillier.PublicKey, presignId string, p1_ped *pedersen.PedersenParameters) *P2Context {
+	if presignId == "" || !supportedCurve(publicKey) {
+		return nil
+	}
+	return &P2Context{
+		curve:     publicKey.Curve,
+		x2:        bobPri,
+		E_x1:      E_x1,
+		paiPub:    paiPub,
+		PublicKey: publicKey,
+		sessionID: presignSessionId(publicKey, presignId),
+		p1_ped:    p1_ped,
+		presign:   true,
+		presignId: presignId,
+		envelope:  tss.Envelope{Protocol: protocol, SessionId: presignId},
+	}
+}
+
+// Presignature after Step2, P1 output presignature record
+func (p1 *P1Context) Presignature() (*P1Presignature, error) {
+	if p1.presignId == "" {
+		return nil, fmt.Errorf("not a presign context")
+	}
+	if p1.k1 == nil || p1.R2 == nil {
+		return nil, fmt.Errorf("presign step error")
+	}
+	presignature := &P1Presignature{
+		Id: p1.presignId,
+		R:  p1.R2.ScalarMult(p1.k1),
+		p1: p1,
+	}
+	// the context can not be used again
+	p1.presignId = ""
+	return presignature, nil
+}
+
+// PresignStep2 check R1 commitment and output presignature record, instead of Step2
+func (p2 *P2Context) PresignStep2(cmtD *commitment.Witness, p1Proof *schnorr.Proof) (*P2Presignature, error) {
+	if p2.presignId == "" {
+		return nil, fmt.Errorf("not a presign context")
+	}
+	if p2.k2 == nil || p2.cmtC == nil {
+		return nil, fmt.Errorf("presign step error")
+	}
+	R1, err := p2.openR1(cmtD, p1Proof)
+	if err != nil {
+		return nil, err
+	}
+	presignature := &P2Presignature{
+		Id: p2.presignId,
+		R:  R1.ScalarMult(p2.k2),
+		p2: p2,
+	}
+	p2.presignId = ""
+	return presignature, nil
+}
+
+// SessionPresignStep2 PresignStep2 on the message of P1 SessionStep2
+func (p2 *P2Context) SessionPresignStep2(msg *tss.Message) (*P2Presignature, error) {
+	var content Step3Data
+	if err := signContent(&p2.envelope, msg, p1Id, p2Id, 3, &content); err != nil {
+		return nil, err
+	}
+	return p2.PresignStep2(&content.Witness, content.Proof)
+}
+
+// Sign online phase, P2 return E[(h+xr)/k2] for message, consume the presignature
+func (pre *P2Presignature) Sign(message string) (*big.Int, *zkp.AffGProof, error) {
+	if err := pre.consume(); err != nil {
+		return nil, nil, err
+	}
+	if err := checkBan(pre.p2.bans(), pre.p2.PublicKey); err != nil {
+		return nil, nil, err
+	}
+	// nonce is never used again
+	defer func() { pre.p2.k2 = nil }()
+	return pre.p2.affineSignShare(pre.R, message)
+}
+
+// SessionSign Sign as a message of the presign session
+func (pre *P2Presignature) SessionSign(message string) (*tss.Message, error) {
+	if pre.p2 == nil {
+		return nil, fmt.Errorf("presignature %s already used", pre.Id)
+	}
+	E_k2_h_xr, affGProof, err := pre.Sign(message)
+	if err != nil {
+		return nil, err
+	}
+	return signMessage(&pre.p2.envelope, p2Id, p1Id, 4, Step4Data{E_k2_h_xr: E_k2_h_xr, AffGProof: affGProof})
+}
+
+// Sign online phase, P1 return signature (r, s) for message, consume the presignature
+func (pre *P1Presignature) Sign(message string, E_k2_h_xr *big.Int, affGProof *zkp.AffGProof) (*big.Int, *big.Int, error) {
+	r, s, _, err := pre.SignWithRecoveryId(message, E_k2_h_xr, affGProof)
+	return r, s, err
+}
+
+// SignWithRecoveryId like Sign, also return the recovery id v
+func (pre *P1Presignature) SignWithRecoveryId(message string, E_k2_h_xr *big.Int, affGProof *zkp.AffGProof) (*big.Int, *big.Int, byte, error) {
+	if err := pre.consume(); err != nil {
+		return nil, nil, 0, err
+	}
+	p1 := pre.p1
+	if err := checkBan(p1.bans(), p1.publicKey); err != nil {
+		return nil, nil, 0, err
+	}
+	defer func() { p1.k1 = nil }()
+	return p1.finalizeSign(pre.R, message, E_k2_h_xr, affGProof)
+}
+
+// SessionSign Sign on the message of P2Presignature.SessionSign
+func (pre *P1Presignature) SessionSign(message string, msg *tss.Message) (*big.Int, *big.Int, error) {
+	r, s, _, err := pre.SessionSignWithRecoveryId(message, msg)
+	return r, s, err
+}
+
+// SessionSignWithRecoveryId SignWithRecoveryId on the message of P2Presignature.SessionSign
+func (pre *P1Presignature) SessionSignWithRecoveryId(message string, msg *tss.Message) (*big.Int, *big.Int, byte, error) {
+	if pre.p1 == nil {
+		return nil, nil, 0, fmt.Errorf("presignature %s already used", pre.Id)
+	}
+	var content Step4Data
+	if err := signContent(&pre.p1.envelope, msg, p2Id, p1Id, 4, &content); err != nil {
+		return nil, nil, 0, err
+	}
+	return pre.SignWithRecoveryId(message, content.E_k2_h_xr, content.AffGProof)
+}
+
+// Used whether the presignature has been consumed
+func (pre *P1Presignature) Used() bool {
+	pre.lock.Lock()
+	defer pre.lock.Unlock()
+	return pre.used
+}
+
+// Used whether the presignature has been consumed
+func (pre *P2Presignature) Used() bool {
+	pre.lock.Lock()
+	defer pre.lock.Unlock()
+	return pre.used
+}
+
+// consume mark used before signing, a failed signature also burns the nonce
+func (pre *P1Presignature) consume() error {
+	pre.lock.Lock()
+	defer pre.lock.Unlock()
+	if pre.used || pre.p1 == nil {
+		return fmt.Errorf("presignature %s already used", pre.Id)
+	}
+	pre.used = true
+	return nil
+}
+
+func (pre *P2Presignature) consume() error {
+	pre.lock.Lock()
+	defer pre.lock.Unlock()
+	if pre.used || pre.p2 == nil {
+		return fmt.Errorf("presignature %s already used", pre.Id)
+	}
+	pre.used = true
+	return nil
+}
@@ -0,0 +1,58 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// RecoverPublicKey public key of signature (r, s) with recovery id v over hash, secp256k1 or P-256
+func RecoverPublicKey(curve elliptic.Curve, hash []byte, r, s *big.Int, v byte) (*ecdsa.PublicKey, error) {
+	if !supportedCurve(&ecdsa.PublicKey{Curve: curve}) {
+		return nil, fmt.Errorf("unsupported curve")
+	}
+	if v > 3 {
+		return nil, fmt.Errorf("invalid recovery id")
+	}
+	params := curve.Params()
+	q := params.N
+	if r == nil || s == nil || r.Sign() <= 0 || s.Sign() <= 0 || r.Cmp(q) >= 0 || s.Cmp(q) >= 0 {
+		return nil, fmt.Errorf("invalid signature")
+	}
+	// R.x = r + (v >> 1)*N, y^2 = x^3 + a*x + b
+	x := new(big.Int).Set(r)
+	if v&2 != 0 {
+		x.Add(x, q)
+	}
+	if x.Cmp(params.P) >= 0 {
+		return nil, fmt.Errorf("invalid recovery id")
+	}
+	y2 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+	if curves.GetCurveName(curve) == curves.P256 {
+		y2.Sub(y2, new(big.Int).Mul(x, big.NewInt(3)))
+	}
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+	y := new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil, fmt.Errorf("invalid signature, R not on curve")
+	}
+	if y.Bit(0) != uint(v&1) {
+		y.Sub(params.P, y)
+	}
+	// Q = r^-1 * (s*R - e*G)
+	rInv := new(big.Int).ModInverse(r, q)
+	e := hashToInt(q, hash)
+	u1 := new(big.Int).Mod(new(big.Int).Mul(new(big.Int).Neg(e), rInv), q)
+	u2 := new(big.Int).Mod(new(big.Int).Mul(s, rInv), q)
+	gx, gy := curve.ScalarBaseMult(u1.Bytes())
+	rx, ry := curve.ScalarMult(x, y, u2.Bytes())
+	qx, qy := curve.Add(gx, gy, rx, ry)
+	if qx.Sign() == 0 && qy.Sign() == 0 {
+		return nil, fmt.Errorf("invalid signature, public key is infinity")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: qx, Y: qy}, nil
+}
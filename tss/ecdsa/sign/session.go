@@ -0,0 +1,168 @@
+package sign
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/paillier"
+	"github.com/okx/threshold-lib/crypto/pedersen"
+	"github.com/okx/threshold-lib/tss"
+)
+
+const (
+	p1SessionKind = "ecdsa/sign/p1"
+	p2SessionKind = "ecdsa/sign/p2"
+)
+
+type p1State struct {
+	SessionId string // session of the messages
+	Format    tss.WireFormat
+	SessionID *big.Int
+	PublicKey *curves.ECPoint
+	PaiPriKey *paillier.PrivateKey
+	K1        *big.Int
+	Message   string
+	R2        *curves.ECPoint
+	CmtD      *commitment.Witness
+	E_x1      *big.Int
+	P1_ped    *pedersen.PedersenParameters
+	Presign   bool
+	PresignId string
+}
+
+type p2State struct {
+	SessionId string // session of the messages
+	Format    tss.WireFormat
+	SessionID *big.Int
+	X2        *big.Int
+	E_x1      *big.Int
+	PaiPub    *paillier.PublicKey
+	PublicKey *curves.ECPoint
+	Message   string
+	K2        *big.Int
+	CmtC      *commitment.Commitment
+	P1_ped    *pedersen.PedersenParameters
+	Presign   bool
+	PresignId string
+}
+
+// Export seal P1 session state with key, k1 is cleared, the session continues only from ImportP1.
+// The ban store is not exported, set it again after import
+func (p1 *P1Context) Export(key []byte) ([]byte, error) {
+	if p1.k1 == nil {
+		return nil, fmt.Errorf("no session state to export")
+	}
+	if p1.presign && p1.presignId == "" {
+		return nil, fmt.Errorf("presignature output, context can not be exported")
+	}
+	state := &p1State{
+		SessionId: p1.envelope.SessionId,
+		Format:    p1.envelope.Format,
+		SessionID: p1.sessionID,
+		PublicKey: &curves.ECPoint{Curve: p1.curve, X: p1.publicKey.X, Y: p1.publicKey.Y},
+		PaiPriKey: p1.paiPriKey,
+		K1:        p1.k1,
+		Message:   p1.message,
+		R2:        p1.R2,
+		CmtD:      p1.cmtD,
+		E_x1:      p1.E_x1,
+		P1_ped:    p1.p1_ped,
+		Presign:   p1.presign,
+		PresignId: p1.presignId,
+	}
+	sealed, err := tss.SealSession(key, p1SessionKind, state)
+	if err != nil {
+		return nil, err
+	}
+	p1.k1 = nil
+	return sealed, nil
+}
+
+// ImportP1 restore P1 session state, each sealed state is imported once
+func ImportP1(key, sealed []byte, replay tss.ReplayStore) (*P1Context, error) {
+	var state p1State
+	err := tss.OpenSession(key, p1SessionKind, sealed, replay, &state)
+	if err != nil {
+		return nil, err
+	}
+	if state.Format.Check() != nil || state.PublicKey == nil || state.K1 == nil || state.SessionID == nil {
+		return nil, fmt.Errorf("p1 session state error")
+	}
+	return &P1Context{
+		sessionID: state.SessionID,
+		curve:     state.PublicKey.Curve,
+		publicKey: &ecdsa.PublicKey{Curve: state.PublicKey.Curve, X: state.PublicKey.X, Y: state.PublicKey.Y},
+		paiPriKey: state.PaiPriKey,
+		k1:        state.K1,
+		message:   state.Message,
+		R2:        state.R2,
+		cmtD:      state.CmtD,
+		E_x1:      state.E_x1,
+		p1_ped:    state.P1_ped,
+		presign:   state.Presign,
+		presignId: state.PresignId,
+		envelope:  tss.Envelope{Protocol: protocol, SessionId: state.SessionId, Format: state.Format},
+	}, nil
+}
+
+// Export seal P2 session state with key, k2 is cleared, the session continues only from ImportP2.
+// The ban store is not exported, set it again after import
+func (p2 *P2Context) Export(key []byte) ([]byte, error) {
+	if p2.k2 == nil {
+		return nil, fmt.Errorf("no session state to export")
+	}
+	if p2.presign && p2.presignId == "" {
+		return nil, fmt.Errorf("presignature output, context can not be exported")
+	}
+	state := &p2State{
+		SessionId: p2.envelope.SessionId,
+		Format:    p2.envelope.Format,
+		SessionID: p2.sessionID,
+		X2:        p2.x2,
+		E_x1:      p2.E_x1,
+		PaiPub:    p2.paiPub,
+		PublicKey: &curves.ECPoint{Curve: p2.curve, X: p2.PublicKey.X, Y: p2.PublicKey.Y},
+		Message:   p2.message,
+		K2:        p2.k2,
+		CmtC:      p2.cmtC,
+		P1_ped:    p2.p1_ped,
+		Presign:   p2.presign,
+		PresignId: p2.presignId,
+	}
+	sealed, err := tss.SealSession(key, p2SessionKind, state)
+	if err != nil {
+		return nil, err
+	}
+	p2.k2 = nil
+	return sealed, nil
+}
+
+// ImportP2 restore P2 session state, each sealed state is imported once
+func ImportP2(key, sealed []byte, replay tss.ReplayStore) (*P2Context, error) {
+	var state p2State
+	err := tss.OpenSession(key, p2SessionKind, sealed, replay, &state)
+	if err != nil {
+		return nil, err
+	}
+	if state.Format.Check() != nil || state.PublicKey == nil || state.K2 == nil || state.SessionID == nil {
+		return nil, fmt.Errorf("p2 session state error")
+	}
+	return &P2Context{
+		sessionID: state.SessionID,
+		curve:     state.PublicKey.Curve,
+		x2:        state.X2,
+		E_x1:      state.E_x1,
+		paiPub:    state.PaiPub,
+		PublicKey: &ecdsa.PublicKey{Curve: state.PublicKey.Curve, X: state.PublicKey.X, Y: state.PublicKey.Y},
+		message:   state.Message,
+		k2:        state.K2,
+		cmtC:      state.CmtC,
+		p1_ped:    state.P1_ped,
+		presign:   state.Presign,
+		presignId: state.PresignId,
+		envelope:  tss.Envelope{Protocol: protocol, SessionId: state.SessionId, Format: state.Format},
+	}, nil
+}
@@ -2,10 +2,16 @@ package sign
 
 import (
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"os"
+	"sync"
 
+	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/paillier"
 	"github.com/stretchr/testify/require"
@@ -18,13 +24,47 @@ import (
 	"github.com/okx/threshold-lib/tss/key/dkg"
 )
 
+var (
+	testKeysOnce sync.Once
+	preParams    *keygen.PreParamsWithDlnProof
+	paiPrivate   *paillier.PrivateKey
+)
+
+// testKeys safe primes are slow, pre-params and paillier key are loaded once from the keygen testdata
+func testKeys() {
+	testKeysOnce.Do(func() {
+		bytes, err := os.ReadFile("../keygen/testdata/prekeys.json")
+		if err != nil {
+			panic(err)
+		}
+		var preKeys []struct {
+			PaiPriKey *paillier.PrivateKey
+			PreParams *keygen.PreParamsWithDlnProof
+		}
+		if err = json.Unmarshal(bytes, &preKeys); err != nil {
+			panic(err)
+		}
+		preParams, paiPrivate = preKeys[0].PreParams, preKeys[0].PaiPriKey
+	})
+}
+
+func testPreParams() *keygen.PreParamsWithDlnProof {
+	testKeys()
+	return preParams
+}
+
+func testPaillierKey() *paillier.PrivateKey {
+	testKeys()
+	return paiPrivate
+}
+
 func TestEcdsaSign(t *testing.T) {
 	p1Data, p2Data, _ := KeyGen()
 
 	fmt.Println("=========2/2 keygen==========")
-	paiPrivate, _, _ := paillier.NewKeyPair(8)
+	paiPrivate := testPaillierKey()
 
-	p1PreParamsAndProof := keygen.GeneratePreParamsWithDlnProof() // this step should be locally done by P1
+	p1PreParamsAndProof := testPreParams() // this step should be locally done by P1
 
 	// this step should be locally done by P2. To save time, we assume both setup are the same.
 	p2PreParamsAndProof := &keygen.PreParamsWithDlnProof{
@@ -64,9 +104,223 @@ func TestEcdsaSign(t *testing.T) {
 	r, s, err := p1.Step3(E_k2_h_xr, affine_proof)
 	require.NoError(t, err)
 	fmt.Println(r, s)
+
+	fmt.Println("=========2/2 sign with session==========")
+	p1 = NewP1WithSession("sign", pubKey, hex.EncodeToString(message), paiPrivate, E_x1, p1PreParamsAndProof.PedersonParameters())
+	p2 = NewP2WithSession("sign", x2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, hex.EncodeToString(message), p2SaveData.Ped1)
+
+	msg1, err := p1.SessionStep1()
+	require.NoError(t, err)
+	// message of another session
+	other := NewP2WithSession("other", x2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, hex.EncodeToString(message), p2SaveData.Ped1)
+	_, err = other.SessionStep1(msg1)
+	require.Error(t, err)
+	msg2, err := p2.SessionStep1(msg1)
+	require.NoError(t, err)
+
+	msg3, _ := p1.SessionStep2(msg2)
+	msg4, err := p2.SessionStep2(msg3)
+	require.NoError(t, err)
+
+	r, s, v, err := p1.SessionStep3WithRecoveryId(msg4)
+	require.NoError(t, err)
+	fmt.Println(r, s, v)
+	recovered, err := RecoverPublicKey(curve, message, r, s, v)
+	require.NoError(t, err)
+	require.True(t, recovered.Equal(pubKey))
+}
+
+func TestRecoverPublicKey(t *testing.T) {
+	for _, c := range []elliptic.Curve{curve, elliptic.P256()} {
+		q := c.Params().N
+		for i := 0; i < 20; i++ {
+			x := crypto.RandomNum(q)
+			pubX, pubY := c.ScalarBaseMult(x.Bytes())
+			pubKey := &ecdsa.PublicKey{Curve: c, X: pubX, Y: pubY}
+			hash := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+			// plain ecdsa with the recovery id of finalizeSign
+			k := crypto.RandomNum(q)
+			Rx, Ry := c.ScalarBaseMult(k.Bytes())
+			r := new(big.Int).Mod(Rx, q)
+			s := new(big.Int).Mul(r, x)
+			s.Add(s, hashToInt(q, hash[:]))
+			s.Mul(s, new(big.Int).ModInverse(k, q))
+			s.Mod(s, q)
+			v := byte(Ry.Bit(0))
+			if s.Cmp(new(big.Int).Rsh(q, 1)) == 1 {
+				s.Sub(q, s)
+				v ^= 1
+			}
+			recovered, err := RecoverPublicKey(c, hash[:], r, s, v)
+			require.NoError(t, err)
+			require.True(t, recovered.Equal(pubKey))
+			recovered, err = RecoverPublicKey(c, hash[:], r, s, v^1)
+			require.NoError(t, err)
+			require.False(t, recovered.Equal(pubKey))
+		}
+	}
+}
+
+func TestEcdsaSignP256(t *testing.T) {
+	p256 := elliptic.P256()
+	p1Data, p2Data, _ := keyGenWithCurve(p256)
+	paiPrivate := testPaillierKey()
+	p1PreParamsAndProof := testPreParams()
+	p1Dto, E_x1, err := keygen.P1WithCurve(p256, p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, p1PreParamsAndProof, p1PreParamsAndProof.PedersonParameters(), p1PreParamsAndProof.Proof)
+	require.NoError(t, err)
+	p2SaveData, err := keygen.P2(p2Data.ShareI, p2Data.PublicKey, p1Dto, p1Data.Id, p2Data.Id, p1PreParamsAndProof.PedersonParameters())
+	require.NoError(t, err)
+	pubKey := &ecdsa.PublicKey{Curve: p256, X: p2Data.PublicKey.X, Y: p2Data.PublicKey.Y}
+
+	hash := sha256.Sum256([]byte("hello"))
+	message := hex.EncodeToString(hash[:])
+	p1 := NewP1WithSession("sign", pubKey, message, paiPrivate, E_x1, p1PreParamsAndProof.PedersonParameters())
+	p2 := NewP2WithSession("sign", p2SaveData.X2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, message, p2SaveData.Ped1)
+	require.NotNil(t, p1)
+	require.NotNil(t, p2)
+
+	msg1, err := p1.SessionStep1()
+	require.NoError(t, err)
+	msg2, err := p2.SessionStep1(msg1)
+	require.NoError(t, err)
+	msg3, err := p1.SessionStep2(msg2)
+	require.NoError(t, err)
+	msg4, err := p2.SessionStep2(msg3)
+	require.NoError(t, err)
+	r, s, v, err := p1.SessionStep3WithRecoveryId(msg4)
+	require.NoError(t, err)
+	require.True(t, ecdsa.Verify(pubKey, hash[:], r, s))
+	recovered, err := RecoverPublicKey(p256, hash[:], r, s, v)
+	require.NoError(t, err)
+	require.True(t, recovered.Equal(pubKey))
+
+	// ed25519 keys are not ecdsa keys
+	edCurve, _ := curves.GetCurveByName(curves.Ed25519)
+	edKey := &ecdsa.PublicKey{Curve: edCurve, X: pubKey.X, Y: pubKey.Y}
+	require.Nil(t, NewP1WithSession("sign", edKey, message, paiPrivate, E_x1, p1PreParamsAndProof.PedersonParameters()))
+	require.Nil(t, NewP1WithSession("", pubKey, message, paiPrivate, E_x1, p1PreParamsAndProof.PedersonParameters()))
+}
+
+func TestEcdsaPresign(t *testing.T) {
+	p1Data, p2Data, _ := KeyGen()
+	paiPrivate := testPaillierKey()
+	p1PreParamsAndProof := testPreParams()
+	p1Dto, E_x1, _ := keygen.P1(p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, p1PreParamsAndProof, p1PreParamsAndProof.PedersonParameters(), p1PreParamsAndProof.Proof)
+	publicKey, _ := curves.NewECPoint(curve, p2Data.PublicKey.X, p2Data.PublicKey.Y)
+	p2SaveData, err := keygen.P2(p2Data.ShareI, publicKey, p1Dto, p1Data.Id, p2Data.Id, p1PreParamsAndProof.PedersonParameters())
+	require.NoError(t, err)
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: publicKey.X, Y: publicKey.Y}
+
+	// offline phase
+	p1 := NewP1Presign(pubKey, "presign-1", paiPrivate, E_x1, p1PreParamsAndProof.PedersonParameters())
+	p2 := NewP2Presign(p2SaveData.X2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, "presign-1", p2SaveData.Ped1)
+	msg1, err := p1.SessionStep1()
+	require.NoError(t, err)
+	msg2, err := p2.SessionStep1(msg1)
+	require.NoError(t, err)
+	msg3, err := p1.SessionStep2(msg2)
+	require.NoError(t, err)
+	p2Pre, err := p2.SessionPresignStep2(msg3)
+	require.NoError(t, err)
+	p1Pre, err := p1.Presignature()
+	require.NoError(t, err)
+	require.True(t, p1Pre.R.Equals(p2Pre.R))
+
+	// presign context can not sign directly
+	_, err = p2.SessionStep2(msg3)
+	require.Error(t, err)
+	_, err = p1.Presignature()
+	require.Error(t, err)
+	// the presignature holds the nonce, the context can not be exported and restored
+	_, err = p1.Export(make([]byte, 32))
+	require.Error(t, err)
+	_, err = p2.Export(make([]byte, 32))
+	require.Error(t, err)
+
+	// online phase
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hex.EncodeToString(hash.Sum(nil))
+	msg4, err := p2Pre.SessionSign(message)
+	require.NoError(t, err)
+	r, s, err := p1Pre.SessionSign(message, msg4)
+	require.NoError(t, err)
+	msg, _ := hex.DecodeString(message)
+	require.True(t, ecdsa.Verify(pubKey, msg, r, s))
+
+	// single-use
+	require.True(t, p1Pre.Used())
+	require.True(t, p2Pre.Used())
+	_, err = p2Pre.SessionSign(message)
+	require.Error(t, err)
+	_, _, err = p1Pre.SessionSign(message, msg4)
+	require.Error(t, err)
+}
+
+func TestEcdsaSignSession(t *testing.T) {
+	p1Data, p2Data, _ := KeyGen()
+	paiPrivate := testPaillierKey()
+	p1PreParamsAndProof := testPreParams()
+	p1Dto, E_x1, _ := keygen.P1(p1Data.ShareI, paiPrivate, p1Data.Id, p2Data.Id, p1PreParamsAndProof, p1PreParamsAndProof.PedersonParameters(), p1PreParamsAndProof.Proof)
+	publicKey, _ := curves.NewECPoint(curve, p2Data.PublicKey.X, p2Data.PublicKey.Y)
+	p2SaveData, err := keygen.P2(p2Data.ShareI, publicKey, p1Dto, p1Data.Id, p2Data.Id, p1PreParamsAndProof.PedersonParameters())
+	require.NoError(t, err)
+	pubKey := &ecdsa.PublicKey{Curve: curve, X: publicKey.X, Y: publicKey.Y}
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hex.EncodeToString(hash.Sum(nil))
+
+	key := make([]byte, 32)
+	replay := tss.NewReplayList()
+	p1 := NewP1WithSession("sign", pubKey, message, paiPrivate, E_x1, p1PreParamsAndProof.PedersonParameters())
+	p2 := NewP2WithSession("sign", p2SaveData.X2, p2SaveData.E_x1, pubKey, p2SaveData.PaiPubKey, message, p2SaveData.Ped1)
+
+	msg1, err := p1.SessionStep1()
+	require.NoError(t, err)
+	p1Sealed, err := p1.Export(key)
+	require.NoError(t, err)
+	msg2, err := p2.SessionStep1(msg1)
+	require.NoError(t, err)
+	p2Sealed, err := p2.Export(key)
+	require.NoError(t, err)
+
+	// exported context can not continue
+	_, err = p1.SessionStep2(msg2)
+	require.Error(t, err)
+
+	p1, err = ImportP1(key, p1Sealed, replay)
+	require.NoError(t, err)
+	msg3, err := p1.SessionStep2(msg2)
+	require.NoError(t, err)
+	p1Sealed, err = p1.Export(key)
+	require.NoError(t, err)
+
+	p2, err = ImportP2(key, p2Sealed, replay)
+	require.NoError(t, err)
+	msg4, err := p2.SessionStep2(msg3)
+	require.NoError(t, err)
+
+	p1, err = ImportP1(key, p1Sealed, replay)
+	require.NoError(t, err)
+	r, s, err := p1.SessionStep3(msg4)
+	require.NoError(t, err)
+	msg, _ := hex.DecodeString(message)
+	require.True(t, ecdsa.Verify(pubKey, msg, r, s))
+
+	// replay
+	_, err = ImportP1(key, p1Sealed, replay)
+	require.Error(t, err)
+	_, err = ImportP2(key, p2Sealed, tss.NewReplayList())
+	require.NoError(t, err)
+	_, err = ImportP1(key, p2Sealed, tss.NewReplayList())
+	require.Error(t, err)
 }
 
 func KeyGen() (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
+	return keyGenWithCurve(curve)
+}
+
+func keyGenWithCurve(curve elliptic.Curve) (*tss.KeyStep3Data, *tss.KeyStep3Data, *tss.KeyStep3Data) {
 	setUp1 := dkg.NewSetUp(1, 3, curve)
 	setUp2 := dkg.NewSetUp(2, 3, curve)
 	setUp3 := dkg.NewSetUp(3, 3, curve)
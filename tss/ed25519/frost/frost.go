@@ -0,0 +1,209 @@
+package frost
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// FROST(Ed25519, SHA-512) https://www.rfc-editor.org/rfc/rfc9591
+// Step1 publish nonce commitments (D, E), it does not depend on the message and can be preprocessed.
+// Step2 compute binding factors and signature share zi = di + ei*rho_i + lambda_i*xi*c.
+// Step3 verify every share and aggregate, the signature verifies with crypto/ed25519.
+
+const contextString = "FROST-ED25519-SHA512-v1"
+
+var (
+	curve = edwards.Edwards()
+)
+
+// protocol id of the message envelope
+const protocol = "ed25519/frost"
+
+type FrostSign struct {
+	DeviceNumber int
+	Threshold    int
+	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
+	PublicKey    *edwards.PublicKey
+
+	partList       []int // participating signers, at least threshold
+	shareI         *big.Int
+	sharePubKeyMap map[int]*curves.ECPoint
+
+	hidingNonce  *big.Int // di, single-use
+	bindingNonce *big.Int // ei, single-use
+	commitments  map[int]*NonceCommitment
+
+	R      *curves.ECPoint  // group commitment
+	c      *big.Int         // challenge
+	rhos   map[int]*big.Int // binding factors
+	shares map[int]*big.Int // signature shares zi
+}
+
+// NewFrostSign partList is any subset of at least threshold participants, sharePubKeyMap from dkg verifies the shares
+//
+// Deprecated: messages are not bound to a session, use NewFrostSignWithSession.
+func NewFrostSign(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, sharePubKeyMap map[int]*curves.ECPoint) *FrostSign {
+	return newFrostSign("", deviceNumber, threshold, partList, ShareI, PublicKey, sharePubKeyMap)
+}
+
+// NewFrostSignWithSession partList is any subset of at least threshold participants, sharePubKeyMap from dkg verifies the shares,
+// signers use the same unique sessionId
+func NewFrostSignWithSession(sessionId string, deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, sharePubKeyMap map[int]*curves.ECPoint) *FrostSign {
+	if sessionId == "" {
+		return nil
+	}
+	return newFrostSign(sessionId, deviceNumber, threshold, partList, ShareI, PublicKey, sharePubKeyMap)
+}
+
+func newFrostSign(sessionId string, deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, sharePubKeyMap map[int]*curves.ECPoint) *FrostSign {
+	if threshold < 2 || len(partList) < threshold || ShareI == nil || PublicKey == nil {
+		return nil
+	}
+	seen := make(map[int]bool, len(partList))
+	for _, id := range partList {
+		if id <= 0 || seen[id] || sharePubKeyMap[id] == nil {
+			return nil
+		}
+		seen[id] = true
+	}
+	if !seen[deviceNumber] {
+		return nil
+	}
+	ids := make([]int, len(partList))
+	copy(ids, partList)
+	sort.Ints(ids)
+	return &FrostSign{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		envelope:       tss.Envelope{Protocol: protocol, SessionId: sessionId},
+		PublicKey:      PublicKey,
+		partList:       ids,
+		shareI:         ShareI,
+		sharePubKeyMap: sharePubKeyMap,
+	}
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *FrostSign) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+// lagrangian coefficient of id over partList at 0
+func (info *FrostSign) lagrangian(id int) *big.Int {
+	xList := make([]*big.Int, len(info.partList))
+	for i, x := range info.partList {
+		xList[i] = big.NewInt(int64(x))
+	}
+	return vss.CalLagrangian(curve, big.NewInt(int64(id)), big.NewInt(1), xList)
+}
+
+// nonceGenerate H3(random_bytes || SerializeScalar(secret))
+func nonceGenerate(random []byte, secret *big.Int) *big.Int {
+	return hashToScalar("nonce", random, serializeScalar(secret))
+}
+
+// bindingFactors rho_i = H1(PK || H4(msg) || H5(commitment list) || SerializeScalar(i))
+func bindingFactors(publicKey *edwards.PublicKey, commitments map[int]*NonceCommitment, ids []int, message []byte) map[int]*big.Int {
+	var encoded []byte
+	for _, id := range ids {
+		cmt := commitments[id]
+		encoded = append(encoded, serializeScalar(big.NewInt(int64(id)))...)
+		encoded = append(encoded, serializePoint(cmt.D)...)
+		encoded = append(encoded, serializePoint(cmt.E)...)
+	}
+	prefix := append([]byte{}, publicKey.Serialize()...)
+	prefix = append(prefix, hash("msg", message)...)
+	prefix = append(prefix, hash("com", encoded)...)
+
+	rhos := make(map[int]*big.Int, len(ids))
+	for _, id := range ids {
+		rhos[id] = hashToScalar("rho", prefix, serializeScalar(big.NewInt(int64(id))))
+	}
+	return rhos
+}
+
+// groupCommitment R = sum(Di + rho_i*Ei)
+func groupCommitment(commitments map[int]*NonceCommitment, ids []int, rhos map[int]*big.Int) (*curves.ECPoint, error) {
+	var R *curves.ECPoint
+	for _, id := range ids {
+		cmt := commitments[id]
+		Ri, err := cmt.D.Add(cmt.E.ScalarMult(rhos[id]))
+		if err != nil {
+			return nil, err
+		}
+		if R == nil {
+			R = Ri
+			continue
+		}
+		R, err = R.Add(Ri)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return R, nil
+}
+
+// challenge c = H2(R || PK || msg), same as ed25519
+func challenge(R *curves.ECPoint, publicKey *edwards.PublicKey, message []byte) *big.Int {
+	h := sha512.New()
+	h.Write(serializePoint(R))
+	h.Write(publicKey.Serialize())
+	h.Write(message)
+	return scalarFromBytes(h.Sum(nil))
+}
+
+// hash H3, H4, H5 with context string and tag
+func hash(tag string, in ...[]byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(contextString + tag))
+	for _, b := range in {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+func hashToScalar(tag string, in ...[]byte) *big.Int {
+	return scalarFromBytes(hash(tag, in...))
+}
+
+// scalarFromBytes little endian bytes mod L
+func scalarFromBytes(in []byte) *big.Int {
+	bytes := make([]byte, len(in))
+	for i, b := range in {
+		bytes[len(in)-1-i] = b
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(bytes), curve.N)
+}
+
+// serializeScalar 32 bytes little endian
+func serializeScalar(k *big.Int) []byte {
+	bytes := new(big.Int).Mod(k, curve.N).FillBytes(make([]byte, 32))
+	for i, j := 0, len(bytes)-1; i < j; i, j = i+1, j-1 {
+		bytes[i], bytes[j] = bytes[j], bytes[i]
+	}
+	return bytes
+}
+
+func serializePoint(p *curves.ECPoint) []byte {
+	return edwards.NewPublicKey(p.X, p.Y).Serialize()
+}
+
+// checkPoint on curve and not the identity
+func checkPoint(p *curves.ECPoint) error {
+	if p == nil || p.X == nil || p.Y == nil || !curve.IsOnCurve(p.X, p.Y) {
+		return fmt.Errorf("invalid point")
+	}
+	if p.X.Sign() == 0 && p.Y.Cmp(big.NewInt(1)) == 0 {
+		return fmt.Errorf("identity point")
+	}
+	return nil
+}
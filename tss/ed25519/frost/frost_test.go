@@ -0,0 +1,250 @@
+package frost
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrost(t *testing.T) {
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hash.Sum(nil)
+
+	keyData := keyGen(t, 2, 3)
+	publicKey := edwards.NewPublicKey(keyData[0].PublicKey.X, keyData[0].PublicKey.Y)
+	for _, partList := range [][]int{{1, 2}, {1, 3}, {3, 2}, {1, 2, 3}} {
+		signatures := sign(t, 2, keyData, partList, message, nil)
+		for _, signature := range signatures {
+			require.True(t, ed25519.Verify(publicKey.Serialize(), message, signature))
+		}
+	}
+
+	keyData = keyGen(t, 3, 5)
+	publicKey = edwards.NewPublicKey(keyData[0].PublicKey.X, keyData[0].PublicKey.Y)
+	signatures := sign(t, 3, keyData, []int{5, 2, 4, 1}, message, nil)
+	require.True(t, ed25519.Verify(publicKey.Serialize(), message, signatures[0]))
+}
+
+func TestFrostBlame(t *testing.T) {
+	keyData := keyGen(t, 2, 3)
+	message := []byte("hello")
+	_, err := signErr(t, 2, keyData, []int{1, 2}, message, func(msgs map[int]*tss.Message) {
+		// signer 2 sends a wrong share to 1
+		msg := msgs[1]
+		if msg == nil || msg.From != 2 {
+			return
+		}
+		var content Step2Data
+		require.NoError(t, json.Unmarshal([]byte(msg.Data), &content))
+		content.Share = new(big.Int).Add(content.Share, big.NewInt(1))
+		bytes, _ := json.Marshal(content)
+		msg.Data = string(bytes)
+	})
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 2, blame.Culprit)
+	require.Equal(t, tss.BlameShare, blame.Reason)
+}
+
+func TestFrostParams(t *testing.T) {
+	keyData := keyGen(t, 3, 4)
+	publicKey := edwards.NewPublicKey(keyData[0].PublicKey.X, keyData[0].PublicKey.Y)
+	require.Nil(t, NewFrostSignWithSession("frost", 1, 3, []int{1, 2}, keyData[0].ShareI, publicKey, keyData[0].SharePubKeyMap))
+	require.Nil(t, NewFrostSignWithSession("frost", 1, 3, []int{2, 3, 4}, keyData[0].ShareI, publicKey, keyData[0].SharePubKeyMap))
+	require.Nil(t, NewFrostSignWithSession("frost", 1, 3, []int{1, 2, 2}, keyData[0].ShareI, publicKey, keyData[0].SharePubKeyMap))
+
+	// nonce is single-use
+	info := NewFrostSignWithSession("frost", 1, 3, []int{1, 2, 3}, keyData[0].ShareI, publicKey, keyData[0].SharePubKeyMap)
+	_, err := info.SignStep1()
+	require.NoError(t, err)
+	_, err = info.SignStep2(hex.EncodeToString([]byte("m")), nil)
+	require.Error(t, err)
+	_, err = info.SignStep2(hex.EncodeToString([]byte("m")), nil)
+	require.Error(t, err)
+}
+
+func sign(t *testing.T, threshold int, keyData []*tss.KeyStep3Data, partList []int, message []byte, tamper func(map[int]*tss.Message)) [][]byte {
+	signatures, err := signErr(t, threshold, keyData, partList, message, tamper)
+	require.NoError(t, err)
+	return signatures
+}
+
+func signErr(t *testing.T, threshold int, keyData []*tss.KeyStep3Data, partList []int, message []byte, tamper func(map[int]*tss.Message)) ([][]byte, error) {
+	publicKey := edwards.NewPublicKey(keyData[0].PublicKey.X, keyData[0].PublicKey.Y)
+	infos := make(map[int]*FrostSign, len(partList))
+	for _, id := range partList {
+		data := keyData[id-1]
+		infos[id] = NewFrostSignWithSession("frost", id, threshold, partList, data.ShareI, publicKey, data.SharePubKeyMap)
+		require.NotNil(t, infos[id])
+	}
+	// round 1 runs before the message is known
+	out1 := make(map[int]map[int]*tss.Message, len(partList))
+	for id, info := range infos {
+		msgs, err := info.SignStep1()
+		require.NoError(t, err)
+		out1[id] = msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message, len(partList))
+	for id, info := range infos {
+		msgs, err := info.SignStep2(hex.EncodeToString(message), collect(out1, id))
+		require.NoError(t, err)
+		if tamper != nil {
+			tamper(msgs)
+		}
+		out2[id] = msgs
+	}
+	var signatures [][]byte
+	for id, info := range infos {
+		signature, err := info.SignStep3(collect(out2, id))
+		if err != nil {
+			return nil, err
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+func collect(out map[int]map[int]*tss.Message, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for from, msgMap := range out {
+		if from != id {
+			msgs = append(msgs, msgMap[id])
+		}
+	}
+	return msgs
+}
+
+func keyGen(t *testing.T, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, edwards.Edwards())
+	}
+	out1 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out1[i+1] = msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out1, i+1))
+		require.NoError(t, err)
+		out2[i+1] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(out2, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
+
+// RFC 9591 appendix E.1 FROST(Ed25519, SHA-512), participants 1 and 3 of 2-of-3
+func TestFrostRFCVectors(t *testing.T) {
+	groupSecretKey := vectorScalar("7b1c33d3f5291d85de664833beb1ad469f7fb6025a0ec78b3a790c6e13a98304")
+	coefficient := vectorScalar("178199860edd8c62f5212ee91eff1295d0d670ab4ed4506866bae57e7030b204")
+	groupPublicKey := vectorBytes("15d21ccd7ee42959562fc8aa63224c8851fb3ec85a3faf66040d380fb9738673")
+	message := vectorBytes("74657374")
+	shares := map[int]string{
+		1: "929dcc590407aae7d388761cddb0c0db6f5627aea8e217f4a033f2ec83d93509",
+		2: "a91e66e012e4364ac9aaa405fcafd370402d9859f7b6685c07eed76bf409e80d",
+		3: "d3cb090a075eb154e82fdb4b3cb507f110040905468bb9c46da8bdea643a9a02",
+	}
+	type signerVector struct {
+		hidingRandomness, bindingRandomness string
+		hidingNonce, bindingNonce           string
+		hidingCommitment, bindingCommitment string
+		bindingFactor, sigShare             string
+	}
+	signers := map[int]signerVector{
+		1: {
+			hidingRandomness:  "0fd2e39e111cdc266f6c0f4d0fd45c947761f1f5d3cb583dfcb9bbaf8d4c9fec",
+			bindingRandomness: "69cd85f631d5f7f2721ed5e40519b1366f340a87c2f6856363dbdcda348a7501",
+			hidingNonce:       "812d6104142944d5a55924de6d49940956206909f2acaeedecda2b726e630407",
+			bindingNonce:      "b1110165fc2334149750b28dd813a39244f315cff14d4e89e6142f262ed83301",
+			hidingCommitment:  "b5aa8ab305882a6fc69cbee9327e5a45e54c08af61ae77cb8207be3d2ce13de3",
+			bindingCommitment: "67e98ab55aa310c3120418e5050c9cf76cf387cb20ac9e4b6fdb6f82a469f932",
+			bindingFactor:     "f2cb9d7dd9beff688da6fcc83fa89046b3479417f47f55600b106760eb3b5603",
+			sigShare:          "001719ab5a53ee1a12095cd088fd149702c0720ce5fd2f29dbecf24b7281b603",
+		},
+		3: {
+			hidingRandomness:  "86d64a260059e495d0fb4fcc17ea3da7452391baa494d4b00321098ed2a0062f",
+			bindingRandomness: "13e6b25afb2eba51716a9a7d44130c0dbae0004a9ef8d7b5550c8a0e07c61775",
+			hidingNonce:       "c256de65476204095ebdc01bd11dc10e57b36bc96284595b8215222374f99c0e",
+			bindingNonce:      "243d71944d929063bc51205714ae3c2218bd3451d0214dfb5aeec2a90c35180d",
+			hidingCommitment:  "cfbdb165bd8aad6eb79deb8d287bcc0ab6658ae57fdcc98ed12c0669e90aec91",
+			bindingCommitment: "7487bc41a6e712eea2f2af24681b58b1cf1da278ea11fe4e8b78398965f13552",
+			bindingFactor:     "b087686bf35a13f3dc78e780a34b0fe8a77fef1b9938c563f5573d71d8d7890f",
+			sigShare:          "bd86125de990acc5e1f13781d8e32c03a9bbd4c53539bbc106058bfd14326007",
+		},
+	}
+	signature := vectorBytes("36282629c383bb820a88b71cae937d41f2f2adfcc3d02e55507e2fb9e2dd3cbe" +
+		"bd9d2b0844e49ae0f3fa935161e1419aab7b47d21a37ebeae1f17d4987b3160b")
+
+	// key shares of f(x) = s + a1*x
+	require.Equal(t, groupPublicKey, serializePoint(curves.ScalarToPoint(curve, groupSecretKey)))
+	sharePubKeyMap := make(map[int]*curves.ECPoint, len(shares))
+	for id, share := range shares {
+		shareI := new(big.Int).Mul(coefficient, big.NewInt(int64(id)))
+		shareI.Add(shareI, groupSecretKey)
+		require.Equal(t, share, hex.EncodeToString(serializeScalar(shareI)))
+		sharePubKeyMap[id] = curves.ScalarToPoint(curve, vectorScalar(share))
+	}
+	publicKey, err := edwards.ParsePubKey(groupPublicKey)
+	require.NoError(t, err)
+
+	partList := []int{1, 3}
+	infos := make(map[int]*FrostSign, len(partList))
+	out1 := make(map[int]map[int]*tss.Message, len(partList))
+	for _, id := range partList {
+		infos[id] = NewFrostSignWithSession("frost", id, 2, partList, vectorScalar(shares[id]), publicKey, sharePubKeyMap)
+		require.NotNil(t, infos[id])
+		v := signers[id]
+		out1[id], err = infos[id].signStep1(vectorBytes(v.hidingRandomness), vectorBytes(v.bindingRandomness))
+		require.NoError(t, err)
+		require.Equal(t, v.hidingNonce, hex.EncodeToString(serializeScalar(infos[id].hidingNonce)))
+		require.Equal(t, v.bindingNonce, hex.EncodeToString(serializeScalar(infos[id].bindingNonce)))
+		cmt := infos[id].commitments[id]
+		require.Equal(t, v.hidingCommitment, hex.EncodeToString(serializePoint(cmt.D)))
+		require.Equal(t, v.bindingCommitment, hex.EncodeToString(serializePoint(cmt.E)))
+	}
+	out2 := make(map[int]map[int]*tss.Message, len(partList))
+	for _, id := range partList {
+		out2[id], err = infos[id].SignStep2(hex.EncodeToString(message), collect(out1, id))
+		require.NoError(t, err)
+		for signer, v := range signers {
+			require.Equal(t, v.bindingFactor, hex.EncodeToString(serializeScalar(infos[id].rhos[signer])))
+		}
+		require.Equal(t, signers[id].sigShare, hex.EncodeToString(serializeScalar(infos[id].shares[id])))
+	}
+	for _, id := range partList {
+		sig, err := infos[id].SignStep3(collect(out2, id))
+		require.NoError(t, err)
+		require.Equal(t, signature, sig)
+	}
+	require.True(t, ed25519.Verify(groupPublicKey, message, signature))
+}
+
+// vectorScalar little endian scalar
+func vectorScalar(s string) *big.Int {
+	return scalarFromBytes(vectorBytes(s))
+}
+
+func vectorBytes(s string) []byte {
+	bytes, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return bytes
+}
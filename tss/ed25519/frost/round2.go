@@ -0,0 +1,93 @@
+package frost
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step2Data struct {
+	Share *big.Int // zi
+}
+
+// SignStep2 binding factors, group commitment and signature share zi = di + ei*rho_i + lambda_i*xi*c,
+// nonces are deleted after use
+func (info *FrostSign) SignStep2(message string, msgs []*tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	msg, err := hex.DecodeString(message)
+	if err != nil {
+		return nil, err
+	}
+	// nonce is never used twice, even if this step fails
+	info.RoundNumber = -1
+	di, ei := info.hidingNonce, info.bindingNonce
+	info.hidingNonce, info.bindingNonce = nil, nil
+
+	for _, m := range msgs {
+		if m.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(m, 1); err != nil {
+			return nil, err
+		}
+		if !info.isSigner(m.From) || m.From == info.DeviceNumber {
+			return nil, fmt.Errorf("unknown signer %d", m.From)
+		}
+		if _, ok := info.commitments[m.From]; ok {
+			return nil, fmt.Errorf("duplicate message, signer %d", m.From)
+		}
+		var content Step1Data
+		err = tss.UnmarshalData([]byte(m.Data), &content)
+		if err != nil || content.Commitment == nil || checkPoint(content.Commitment.D) != nil || checkPoint(content.Commitment.E) != nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, m)
+		}
+		info.commitments[m.From] = content.Commitment
+	}
+
+	info.rhos = bindingFactors(info.PublicKey, info.commitments, info.partList, msg)
+	info.R, err = groupCommitment(info.commitments, info.partList, info.rhos)
+	if err != nil {
+		return nil, err
+	}
+	info.c = challenge(info.R, info.PublicKey, msg)
+
+	q := curve.N
+	zi := new(big.Int).Mul(ei, info.rhos[info.DeviceNumber])
+	zi.Add(zi, di)
+	lambdaXiC := new(big.Int).Mul(info.lagrangian(info.DeviceNumber), info.shareI)
+	lambdaXiC.Mul(lambdaXiC, info.c)
+	zi.Add(zi, lambdaXiC)
+	zi.Mod(zi, q)
+	info.RoundNumber = 3
+
+	bytes, err := info.envelope.MarshalData(Step2Data{Share: zi})
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]*tss.Message, len(info.partList)-1)
+	for _, id := range info.partList {
+		if id == info.DeviceNumber {
+			continue
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 2, bytes)
+	}
+	// own share is kept for aggregation
+	info.shares = map[int]*big.Int{info.DeviceNumber: zi}
+	return out, nil
+}
+
+func (info *FrostSign) isSigner(id int) bool {
+	for _, i := range info.partList {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
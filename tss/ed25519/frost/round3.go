@@ -0,0 +1,70 @@
+package frost
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// SignStep3 verify signature shares zi*G = Di + rho_i*Ei + lambda_i*c*Xi, return 64 bytes ed25519 signature R || z
+func (info *FrostSign) SignStep3(msgs []*tss.Message) ([]byte, error) {
+	if info.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.partList) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	q := curve.N
+	z := new(big.Int).Set(info.shares[info.DeviceNumber])
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, err
+		}
+		if !info.isSigner(msg.From) || msg.From == info.DeviceNumber {
+			return nil, fmt.Errorf("unknown signer %d", msg.From)
+		}
+		if _, ok := info.shares[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, signer %d", msg.From)
+		}
+		var content Step2Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil || content.Share == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		if !info.verifyShare(msg.From, content.Share) {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
+		}
+		info.shares[msg.From] = content.Share
+		z.Add(z, content.Share)
+	}
+	z.Mod(z, q)
+	info.RoundNumber = -1
+
+	signature := make([]byte, 0, 64)
+	signature = append(signature, serializePoint(info.R)...)
+	signature = append(signature, serializeScalar(z)...)
+	return signature, nil
+}
+
+// verifyShare zi*G = Di + rho_i*Ei + lambda_i*c*Xi
+func (info *FrostSign) verifyShare(id int, zi *big.Int) bool {
+	if zi.Sign() < 0 || zi.Cmp(curve.N) >= 0 {
+		return false
+	}
+	cmt := info.commitments[id]
+	lambdaC := new(big.Int).Mul(info.lagrangian(id), info.c)
+	expected, err := cmt.D.Add(cmt.E.ScalarMult(info.rhos[id]))
+	if err != nil {
+		return false
+	}
+	expected, err = expected.Add(info.sharePubKeyMap[id].ScalarMult(new(big.Int).Mod(lambdaC, curve.N)))
+	if err != nil {
+		return false
+	}
+	return curves.ScalarToPoint(curve, zi).Equals(expected)
+}
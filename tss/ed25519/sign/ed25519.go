@@ -5,13 +5,18 @@ import (
 
 	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
 )
 
 var (
 	curve = edwards.Edwards()
 )
 
+// protocol id of the message envelope
+const protocol = "ed25519/sign"
+
 type Ed25519Sign struct {
 	DeviceNumber int
 	Threshold    int
@@ -19,15 +24,34 @@ type Ed25519Sign struct {
 	wi           *big.Int
 	PublicKey    *edwards.PublicKey
 	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
 	ki           *big.Int
 	message      string
 
 	cmtD          commitment.Witness
 	CommitmentMap map[int]commitment.Commitment
+
+	r     *big.Int                // encoded R of SignStep3
+	h     *big.Int                // challenge hash512(R || Pub || M) mod L
+	riMap map[int]*curves.ECPoint // nonce Ri of every signer
 }
 
 // NewEd25519Sign
+//
+// Deprecated: messages are not bound to a session, use NewEd25519SignWithSession.
 func NewEd25519Sign(deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, message string) *Ed25519Sign {
+	return newEd25519Sign("", deviceNumber, threshold, partList, ShareI, PublicKey, message)
+}
+
+// NewEd25519SignWithSession signers use the same unique sessionId
+func NewEd25519SignWithSession(sessionId string, deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, message string) *Ed25519Sign {
+	if sessionId == "" {
+		return nil
+	}
+	return newEd25519Sign(sessionId, deviceNumber, threshold, partList, ShareI, PublicKey, message)
+}
+
+func newEd25519Sign(sessionId string, deviceNumber, threshold int, partList []int, ShareI *big.Int, PublicKey *edwards.PublicKey, message string) *Ed25519Sign {
 	if len(partList) != threshold {
 		return nil
 	}
@@ -46,6 +70,12 @@ func NewEd25519Sign(deviceNumber, threshold int, partList []int, ShareI *big.Int
 		PublicKey:    PublicKey,
 		message:      message,
 		RoundNumber:  1,
+		envelope:     tss.Envelope{Protocol: protocol, SessionId: sessionId},
 	}
 	return ed25519
 }
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (ed25519 *Ed25519Sign) SetWireFormat(format tss.WireFormat) error {
+	return ed25519.envelope.SetWireFormat(format)
+}
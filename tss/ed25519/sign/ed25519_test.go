@@ -1,13 +1,17 @@
 package sign
 
 import (
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/bip32"
 	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
 	"math/big"
 	"testing"
 )
@@ -27,6 +31,135 @@ func TestEd25519(t *testing.T) {
 	}
 }
 
+func TestEd25519Session(t *testing.T) {
+	p1Data, p2Data, _ := keyGen(curve)
+	hash := sha256.New()
+	hash.Write([]byte("hello"))
+	message := hash.Sum(nil)
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+
+	key := make([]byte, 32)
+	replay := tss.NewReplayList()
+	partList := []int{1, 2}
+	p1 := NewEd25519Sign(1, 2, partList, p1Data.ShareI, publicKey, hex.EncodeToString(message))
+	p2 := NewEd25519Sign(2, 2, partList, p2Data.ShareI, publicKey, hex.EncodeToString(message))
+	restore := func(info *Ed25519Sign) *Ed25519Sign {
+		sealed, err := info.Export(key)
+		require.NoError(t, err)
+		info, err = ImportEd25519Sign(key, sealed, replay)
+		require.NoError(t, err)
+		_, err = ImportEd25519Sign(key, sealed, replay)
+		require.Error(t, err)
+		return info
+	}
+
+	p1Step1, err := p1.SignStep1()
+	require.NoError(t, err)
+	p2Step1, err := p2.SignStep1()
+	require.NoError(t, err)
+	p1, p2 = restore(p1), restore(p2)
+
+	p1Step2, err := p1.SignStep2([]*tss.Message{p2Step1[1]})
+	require.NoError(t, err)
+	p2Step2, err := p2.SignStep2([]*tss.Message{p1Step1[2]})
+	require.NoError(t, err)
+	p1, p2 = restore(p1), restore(p2)
+
+	si_1, r, err := p1.SignStep3([]*tss.Message{p2Step2[1]})
+	require.NoError(t, err)
+	si_2, _, err := p2.SignStep3([]*tss.Message{p1Step2[2]})
+	require.NoError(t, err)
+	signature := edwards.NewSignature(r, new(big.Int).Add(si_1, si_2))
+	require.True(t, signature.Verify(message, publicKey))
+	combined, err := CombineSignature(r, []*big.Int{si_1, si_2})
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(publicKey.Serialize(), message, combined))
+}
+
+func TestEd25519Combine(t *testing.T) {
+	p1Data, _, p3Data := keyGen(curve)
+	message := sha256.Sum256([]byte("hello"))
+	publicKey := edwards.NewPublicKey(p1Data.PublicKey.X, p1Data.PublicKey.Y)
+
+	partList := []int{1, 3}
+	p1 := NewEd25519SignWithSession("ed25519", 1, 2, partList, p1Data.ShareI, publicKey, hex.EncodeToString(message[:]))
+	p3 := NewEd25519SignWithSession("ed25519", 3, 2, partList, p3Data.ShareI, publicKey, hex.EncodeToString(message[:]))
+	_, err := p1.Combine(nil, p1Data.SharePubKeyMap)
+	require.Error(t, err)
+
+	p1Step1, err := p1.SignStep1()
+	require.NoError(t, err)
+	p3Step1, err := p3.SignStep1()
+	require.NoError(t, err)
+	p1Step2, err := p1.SignStep2([]*tss.Message{p3Step1[1]})
+	require.NoError(t, err)
+	p3Step2, err := p3.SignStep2([]*tss.Message{p1Step1[3]})
+	require.NoError(t, err)
+	si_1, _, err := p1.SignStep3([]*tss.Message{p3Step2[1]})
+	require.NoError(t, err)
+	si_3, _, err := p3.SignStep3([]*tss.Message{p1Step2[3]})
+	require.NoError(t, err)
+
+	signature, err := p1.Combine(map[int]*big.Int{1: si_1, 3: si_3}, p1Data.SharePubKeyMap)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(publicKey.Serialize(), message[:], signature))
+	signature, err = p3.Combine(map[int]*big.Int{1: si_1, 3: si_3}, p3Data.SharePubKeyMap)
+	require.NoError(t, err)
+	require.True(t, ed25519.Verify(publicKey.Serialize(), message[:], signature))
+
+	bad := new(big.Int).Add(si_3, big.NewInt(1))
+	_, err = p1.Combine(map[int]*big.Int{1: si_1, 3: bad}, p1Data.SharePubKeyMap)
+	var blame *tss.BlameError
+	require.ErrorAs(t, err, &blame)
+	require.Equal(t, 3, blame.Culprit)
+	require.Equal(t, tss.BlameShare, blame.Reason)
+}
+
+func TestEd25519ChildKey(t *testing.T) {
+	p1Data, _, p3Data := keyGen(curve)
+	message := sha256.Sum256([]byte("hello"))
+	for _, newTssKey := range []func(*big.Int, *curves.ECPoint, string) (*bip32.TssKey, error){bip32.NewTssKey, bip32.NewTssKeyEd25519} {
+		p1Key, err := newTssKey(p1Data.ShareI, p1Data.PublicKey, p1Data.ChainCode)
+		require.NoError(t, err)
+		p3Key, err := newTssKey(p3Data.ShareI, p3Data.PublicKey, p3Data.ChainCode)
+		require.NoError(t, err)
+		signChildKeys(t, p1Key, p3Key, message[:])
+	}
+}
+
+func signChildKeys(t *testing.T, p1Key, p3Key *bip32.TssKey, message []byte) {
+	p1Path, err := p1Key.DerivePath("m/44/501/0")
+	require.NoError(t, err)
+	p3Path, err := p3Key.DerivePath("m/44/501/0")
+	require.NoError(t, err)
+
+	for i := range p1Path {
+		childPub := p1Path[i].PublicKey()
+		require.True(t, childPub.Equals(p3Path[i].PublicKey()))
+		require.Equal(t, 0, p1Path[i].PrivateKeyOffset().Cmp(p3Path[i].PrivateKeyOffset()))
+		publicKey := edwards.NewPublicKey(childPub.X, childPub.Y)
+
+		partList := []int{1, 3}
+		p1 := NewEd25519SignWithSession("ed25519", 1, 2, partList, p1Path[i].ShareI(), publicKey, hex.EncodeToString(message))
+		p3 := NewEd25519SignWithSession("ed25519", 3, 2, partList, p3Path[i].ShareI(), publicKey, hex.EncodeToString(message))
+		p1Step1, err := p1.SignStep1()
+		require.NoError(t, err)
+		p3Step1, err := p3.SignStep1()
+		require.NoError(t, err)
+		p1Step2, err := p1.SignStep2([]*tss.Message{p3Step1[1]})
+		require.NoError(t, err)
+		p3Step2, err := p3.SignStep2([]*tss.Message{p1Step1[3]})
+		require.NoError(t, err)
+		si_1, r, err := p1.SignStep3([]*tss.Message{p3Step2[1]})
+		require.NoError(t, err)
+		si_3, _, err := p3.SignStep3([]*tss.Message{p1Step2[3]})
+		require.NoError(t, err)
+		s := new(big.Int).Mod(new(big.Int).Add(si_1, si_3), curve.N)
+		signature := edwards.NewSignature(r, s)
+		require.True(t, signature.Verify(message, publicKey))
+	}
+}
+
 func sign_p1_p2(p1Data, p2Data *tss.KeyStep3Data, publicKey *edwards.PublicKey, message []byte) {
 	fmt.Println("=========sign_p1_p2========")
 	partList := []int{1, 2}
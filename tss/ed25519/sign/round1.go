@@ -1,7 +1,6 @@
 package sign
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/commitment"
@@ -32,15 +31,11 @@ func (ed25519 *Ed25519Sign) SignStep1() (map[int]*tss.Message, error) {
 		}
 		// p2p send message
 		data := Step1Data{C: cmt.C}
-		bytes, err := json.Marshal(data)
+		bytes, err := ed25519.envelope.MarshalData(data)
 		if err != nil {
 			return nil, err
 		}
-		message := &tss.Message{
-			From: ed25519.DeviceNumber,
-			To:   i,
-			Data: string(bytes),
-		}
+		message := ed25519.envelope.NewMessage(ed25519.DeviceNumber, i, 1, bytes)
 		out[i] = message
 	}
 	return out, nil
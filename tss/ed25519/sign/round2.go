@@ -1,7 +1,6 @@
 package sign
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/okx/threshold-lib/crypto/commitment"
 	"github.com/okx/threshold-lib/crypto/curves"
@@ -28,8 +27,11 @@ func (ed25519 *Ed25519Sign) SignStep2(msgs []*tss.Message) (map[int]*tss.Message
 		if msg.To != ed25519.DeviceNumber {
 			return nil, fmt.Errorf("message sending error")
 		}
+		if err := ed25519.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
 		var content Step1Data
-		err := json.Unmarshal([]byte(msg.Data), &content)
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
 		if err != nil {
 			return nil, err
 		}
@@ -52,15 +54,11 @@ func (ed25519 *Ed25519Sign) SignStep2(msgs []*tss.Message) (map[int]*tss.Message
 			Witness: ed25519.cmtD,
 			Proof:   proof,
 		}
-		bytes, err := json.Marshal(data)
+		bytes, err := ed25519.envelope.MarshalData(data)
 		if err != nil {
 			return nil, err
 		}
-		message := &tss.Message{
-			From: ed25519.DeviceNumber,
-			To:   i,
-			Data: string(bytes),
-		}
+		message := ed25519.envelope.NewMessage(ed25519.DeviceNumber, i, 2, bytes)
 		out[i] = message
 	}
 	return out, nil
@@ -3,7 +3,6 @@ package sign
 import (
 	"crypto/sha512"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -26,12 +25,16 @@ func (ed25519 *Ed25519Sign) SignStep3(msgs []*tss.Message) (*big.Int, *big.Int,
 	}
 	// R = sum(Ri)
 	R := curves.ScalarToPoint(curve, ed25519.ki)
+	riMap := map[int]*curves.ECPoint{ed25519.DeviceNumber: R}
 	for _, msg := range msgs {
 		if msg.To != ed25519.DeviceNumber {
 			return nil, nil, fmt.Errorf("message sending error")
 		}
+		if err := ed25519.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, nil, err
+		}
 		var data Step2Data
-		err := json.Unmarshal([]byte(msg.Data), &data)
+		err := tss.UnmarshalData([]byte(msg.Data), &data)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -56,6 +59,7 @@ func (ed25519 *Ed25519Sign) SignStep3(msgs []*tss.Message) (*big.Int, *big.Int,
 		if err != nil {
 			return nil, nil, err
 		}
+		riMap[msg.From] = Rj
 	}
 	RR := edwards.NewPublicKey(R.X, R.Y)
 
@@ -84,6 +88,7 @@ func (ed25519 *Ed25519Sign) SignStep3(msgs []*tss.Message) (*big.Int, *big.Int,
 	si := encodedBytesToBigInt(&sBytes)
 	var RBytes = copyBytes(RR.Serialize())
 	r := encodedBytesToBigInt(RBytes)
+	ed25519.r, ed25519.h, ed25519.riMap = r, encodedBytesToBigInt(&lambdaReduced), riMap
 
 	return si, r, nil
 }
@@ -0,0 +1,81 @@
+package sign
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+const sessionKind = "ed25519/sign"
+
+type signState struct {
+	DeviceNumber  int
+	Threshold     int
+	PartList      []int
+	Wi            *big.Int
+	PublicKey     *curves.ECPoint
+	RoundNumber   int
+	Ki            *big.Int
+	Message       string
+	CmtD          commitment.Witness
+	CommitmentMap map[int]commitment.Commitment
+	SessionId     string
+	Format        tss.WireFormat
+}
+
+// Export seal session state with key, ki is cleared, the session continues only from ImportEd25519Sign
+func (ed25519 *Ed25519Sign) Export(key []byte) ([]byte, error) {
+	if ed25519.ki == nil {
+		return nil, fmt.Errorf("no session state to export")
+	}
+	state := &signState{
+		DeviceNumber:  ed25519.DeviceNumber,
+		Threshold:     ed25519.Threshold,
+		PartList:      ed25519.partList,
+		Wi:            ed25519.wi,
+		PublicKey:     &curves.ECPoint{Curve: curve, X: ed25519.PublicKey.X, Y: ed25519.PublicKey.Y},
+		RoundNumber:   ed25519.RoundNumber,
+		Ki:            ed25519.ki,
+		Message:       ed25519.message,
+		CmtD:          ed25519.cmtD,
+		CommitmentMap: ed25519.CommitmentMap,
+		SessionId:     ed25519.envelope.SessionId,
+		Format:        ed25519.envelope.Format,
+	}
+	sealed, err := tss.SealSession(key, sessionKind, state)
+	if err != nil {
+		return nil, err
+	}
+	ed25519.ki = nil
+	ed25519.RoundNumber = -1
+	return sealed, nil
+}
+
+// ImportEd25519Sign restore session state, each sealed state is imported once
+func ImportEd25519Sign(key, sealed []byte, replay tss.ReplayStore) (*Ed25519Sign, error) {
+	var state signState
+	err := tss.OpenSession(key, sessionKind, sealed, replay, &state)
+	if err != nil {
+		return nil, err
+	}
+	if state.Format.Check() != nil || state.PublicKey == nil || state.Ki == nil || state.Wi == nil {
+		return nil, fmt.Errorf("ed25519 session state error")
+	}
+	return &Ed25519Sign{
+		DeviceNumber:  state.DeviceNumber,
+		Threshold:     state.Threshold,
+		partList:      state.PartList,
+		wi:            state.Wi,
+		PublicKey:     edwards.NewPublicKey(state.PublicKey.X, state.PublicKey.Y),
+		RoundNumber:   state.RoundNumber,
+		ki:            state.Ki,
+		message:       state.Message,
+		cmtD:          state.CmtD,
+		CommitmentMap: state.CommitmentMap,
+		envelope:      tss.Envelope{Protocol: protocol, SessionId: state.SessionId, Format: state.Format},
+	}, nil
+}
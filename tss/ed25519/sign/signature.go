@@ -0,0 +1,82 @@
+package sign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// CombineSignature 64 bytes ed25519 signature R | S, r is from SignStep3, S = sum(si) mod L
+func CombineSignature(r *big.Int, partials []*big.Int) ([]byte, error) {
+	if r == nil || len(partials) == 0 {
+		return nil, fmt.Errorf("parameter error")
+	}
+	s := new(big.Int)
+	for _, si := range partials {
+		if si == nil {
+			return nil, fmt.Errorf("nil partial signature")
+		}
+		s.Add(s, si)
+	}
+	s.Mod(s, curve.N)
+	signature := make([]byte, 0, 64)
+	signature = append(signature, bigIntToEncodedBytes(r)[:]...)
+	signature = append(signature, bigIntToEncodedBytes(s)[:]...)
+	return signature, nil
+}
+
+// Combine check every partial si*G == Ri + h*lambda_i*Xi, blame the faulty signer, return the verified 64 bytes signature.
+// partials holds si of every signer including this party, sharePubKeyMap is the dkg share publicKey Xi,
+// for a derived key Xi is shifted by offset*G.
+func (ed25519 *Ed25519Sign) Combine(partials map[int]*big.Int, sharePubKeyMap map[int]*curves.ECPoint) ([]byte, error) {
+	if ed25519.riMap == nil {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(partials) != len(ed25519.partList) {
+		return nil, fmt.Errorf("partial signatures number error")
+	}
+	xList := make([]*big.Int, len(ed25519.partList))
+	for i, x := range ed25519.partList {
+		xList[i] = big.NewInt(int64(x))
+	}
+	list := make([]*big.Int, 0, len(partials))
+	s := new(big.Int)
+	for _, id := range ed25519.partList {
+		si, Xi := partials[id], sharePubKeyMap[id]
+		if Xi == nil {
+			return nil, fmt.Errorf("share publicKey %d missing", id)
+		}
+		if si == nil || si.Sign() < 0 || si.Cmp(curve.N) >= 0 {
+			return nil, &tss.BlameError{Culprit: id, Reason: tss.BlameShare}
+		}
+		// si*G == Ri + h*lambda_i*Xi
+		lambda := vss.CalLagrangian(curve, big.NewInt(int64(id)), big.NewInt(1), xList)
+		hLambda := new(big.Int).Mod(new(big.Int).Mul(ed25519.h, lambda), curve.N)
+		right, err := ed25519.riMap[id].Add(Xi.ScalarMult(hLambda))
+		if err != nil {
+			return nil, err
+		}
+		if !curves.ScalarToPoint(curve, si).Equals(right) {
+			return nil, &tss.BlameError{Culprit: id, Reason: tss.BlameShare}
+		}
+		list = append(list, si)
+		s.Add(s, si)
+	}
+	signature, err := CombineSignature(ed25519.r, list)
+	if err != nil {
+		return nil, err
+	}
+	message, err := hex.DecodeString(ed25519.message)
+	if err != nil {
+		return nil, err
+	}
+	if !edwards.NewSignature(ed25519.r, s.Mod(s, curve.N)).Verify(message, ed25519.PublicKey) {
+		return nil, fmt.Errorf("signature verify fail")
+	}
+	return signature, nil
+}
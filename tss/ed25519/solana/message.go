@@ -0,0 +1,256 @@
+package solana
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/okx/threshold-lib/crypto/base58"
+)
+
+// Solana legacy and v0 message serialization, https://solana.com/docs/core/transactions
+// The message bytes are signed directly with Ed25519Sign, CombineSignature gives the 64 bytes signature.
+
+type Pubkey [32]byte
+
+var SystemProgramID = Pubkey{}
+
+// PubkeyFromBase58 base58 address
+func PubkeyFromBase58(s string) (Pubkey, error) {
+	var pubkey Pubkey
+	b, err := base58.Decode(s)
+	if err != nil {
+		return pubkey, err
+	}
+	if len(b) != len(pubkey) {
+		return pubkey, fmt.Errorf("invalid pubkey length")
+	}
+	copy(pubkey[:], b)
+	return pubkey, nil
+}
+
+func (p Pubkey) String() string {
+	return base58.Encode(p[:])
+}
+
+const (
+	LegacyMessage = -1
+	V0Message     = 0
+)
+
+type MessageHeader struct {
+	NumRequiredSignatures       uint8
+	NumReadonlySignedAccounts   uint8
+	NumReadonlyUnsignedAccounts uint8
+}
+
+type CompiledInstruction struct {
+	ProgramIDIndex uint8
+	Accounts       []uint8
+	Data           []byte
+}
+
+// AddressTableLookup v0 only
+type AddressTableLookup struct {
+	AccountKey      Pubkey
+	WritableIndexes []uint8
+	ReadonlyIndexes []uint8
+}
+
+type Message struct {
+	Version             int // LegacyMessage or V0Message
+	Header              MessageHeader
+	AccountKeys         []Pubkey
+	RecentBlockhash     [32]byte
+	Instructions        []CompiledInstruction
+	AddressTableLookups []AddressTableLookup
+}
+
+// NewTransferMessage legacy system program transfer of lamports
+func NewTransferMessage(from, to Pubkey, lamports uint64, recentBlockhash [32]byte) *Message {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data, 2) // transfer
+	binary.LittleEndian.PutUint64(data[4:], lamports)
+	return &Message{
+		Version:         LegacyMessage,
+		Header:          MessageHeader{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 1},
+		AccountKeys:     []Pubkey{from, to, SystemProgramID},
+		RecentBlockhash: recentBlockhash,
+		Instructions:    []CompiledInstruction{{ProgramIDIndex: 2, Accounts: []uint8{0, 1}, Data: data}},
+	}
+}
+
+// Serialize the bytes to sign
+func (m *Message) Serialize() ([]byte, error) {
+	if m.Version != LegacyMessage && m.Version != V0Message {
+		return nil, fmt.Errorf("unsupported message version %d", m.Version)
+	}
+	if m.Version == LegacyMessage && len(m.AddressTableLookups) != 0 {
+		return nil, fmt.Errorf("legacy message with address table lookups")
+	}
+	if int(m.Header.NumRequiredSignatures) > len(m.AccountKeys) {
+		return nil, fmt.Errorf("more signers than accounts")
+	}
+	var buf bytes.Buffer
+	if m.Version == V0Message {
+		buf.WriteByte(0x80)
+	}
+	buf.Write([]byte{m.Header.NumRequiredSignatures, m.Header.NumReadonlySignedAccounts, m.Header.NumReadonlyUnsignedAccounts})
+	buf.Write(compactU16(len(m.AccountKeys)))
+	for _, key := range m.AccountKeys {
+		buf.Write(key[:])
+	}
+	buf.Write(m.RecentBlockhash[:])
+	buf.Write(compactU16(len(m.Instructions)))
+	for _, ix := range m.Instructions {
+		buf.WriteByte(ix.ProgramIDIndex)
+		buf.Write(compactU16(len(ix.Accounts)))
+		buf.Write(ix.Accounts)
+		buf.Write(compactU16(len(ix.Data)))
+		buf.Write(ix.Data)
+	}
+	if m.Version == V0Message {
+		buf.Write(compactU16(len(m.AddressTableLookups)))
+		for _, lookup := range m.AddressTableLookups {
+			buf.Write(lookup.AccountKey[:])
+			buf.Write(compactU16(len(lookup.WritableIndexes)))
+			buf.Write(lookup.WritableIndexes)
+			buf.Write(compactU16(len(lookup.ReadonlyIndexes)))
+			buf.Write(lookup.ReadonlyIndexes)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseMessage legacy or v0 message
+func ParseMessage(b []byte) (*Message, error) {
+	r := bytes.NewReader(b)
+	m, err := readMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("trailing message bytes")
+	}
+	return m, nil
+}
+
+func readMessage(r *bytes.Reader) (*Message, error) {
+	m := &Message{Version: LegacyMessage}
+	prefix, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if prefix&0x80 != 0 {
+		m.Version = int(prefix & 0x7f)
+		if m.Version != V0Message {
+			return nil, fmt.Errorf("unsupported message version %d", m.Version)
+		}
+		if prefix, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+	}
+	m.Header.NumRequiredSignatures = prefix
+	if m.Header.NumReadonlySignedAccounts, err = r.ReadByte(); err != nil {
+		return nil, err
+	}
+	if m.Header.NumReadonlyUnsignedAccounts, err = r.ReadByte(); err != nil {
+		return nil, err
+	}
+	n, err := readCompactU16(r)
+	if err != nil {
+		return nil, err
+	}
+	m.AccountKeys = make([]Pubkey, n)
+	for i := range m.AccountKeys {
+		if _, err = io.ReadFull(r, m.AccountKeys[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = io.ReadFull(r, m.RecentBlockhash[:]); err != nil {
+		return nil, err
+	}
+	if n, err = readCompactU16(r); err != nil {
+		return nil, err
+	}
+	m.Instructions = make([]CompiledInstruction, n)
+	for i := range m.Instructions {
+		ix := &m.Instructions[i]
+		if ix.ProgramIDIndex, err = r.ReadByte(); err != nil {
+			return nil, err
+		}
+		if ix.Accounts, err = readBytes(r); err != nil {
+			return nil, err
+		}
+		if ix.Data, err = readBytes(r); err != nil {
+			return nil, err
+		}
+	}
+	if m.Version == V0Message {
+		if n, err = readCompactU16(r); err != nil {
+			return nil, err
+		}
+		m.AddressTableLookups = make([]AddressTableLookup, n)
+		for i := range m.AddressTableLookups {
+			lookup := &m.AddressTableLookups[i]
+			if _, err = io.ReadFull(r, lookup.AccountKey[:]); err != nil {
+				return nil, err
+			}
+			if lookup.WritableIndexes, err = readBytes(r); err != nil {
+				return nil, err
+			}
+			if lookup.ReadonlyIndexes, err = readBytes(r); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if int(m.Header.NumRequiredSignatures) > len(m.AccountKeys) {
+		return nil, fmt.Errorf("more signers than accounts")
+	}
+	return m, nil
+}
+
+// compactU16 shortvec length, 7 bits per byte little endian
+func compactU16(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+func readCompactU16(r *bytes.Reader) (int, error) {
+	n := 0
+	for i := 0; i < 3; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b&0x7f) << (7 * uint(i))
+		if b&0x80 == 0 {
+			if n > 0xffff || (i > 0 && b == 0) {
+				return 0, fmt.Errorf("invalid compact-u16")
+			}
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid compact-u16")
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readCompactU16(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
@@ -0,0 +1,141 @@
+package solana
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/ed25519/sign"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactU16(t *testing.T) {
+	vectors := map[int]string{0: "00", 0x7f: "7f", 0x80: "8001", 0x3fff: "ff7f", 0x4000: "808001", 0xffff: "ffff03"}
+	for n, encoded := range vectors {
+		require.Equal(t, encoded, hex.EncodeToString(compactU16(n)))
+		b, _ := hex.DecodeString(encoded)
+		m, err := readCompactU16(bytes.NewReader(b))
+		require.NoError(t, err)
+		require.Equal(t, n, m)
+	}
+	_, err := readCompactU16(bytes.NewReader([]byte{0x80, 0x00}))
+	require.Error(t, err)
+}
+
+func TestSolanaTransfer(t *testing.T) {
+	system, err := PubkeyFromBase58("11111111111111111111111111111111")
+	require.NoError(t, err)
+	require.Equal(t, SystemProgramID, system)
+
+	keyData := keyGen(t)
+	publicKey := edwards.NewPublicKey(keyData[0].PublicKey.X, keyData[0].PublicKey.Y)
+	var from, to Pubkey
+	copy(from[:], publicKey.Serialize())
+	to[0] = 7
+	var blockhash [32]byte
+	blockhash[31] = 1
+
+	message := NewTransferMessage(from, to, 1000000, blockhash)
+	serialized, err := message.Serialize()
+	require.NoError(t, err)
+	expected := "010001" + "03" + hex.EncodeToString(from[:]) + hex.EncodeToString(to[:]) + hex.EncodeToString(system[:]) +
+		hex.EncodeToString(blockhash[:]) + "01" + "02" + "020001" + "0c" + "02000000" + "40420f0000000000"
+	require.Equal(t, expected, hex.EncodeToString(serialized))
+
+	tx := NewTransaction(message)
+	require.NoError(t, tx.Sign(from, thresholdSigner(t, keyData, []int{1, 3})))
+	raw, err := tx.Serialize()
+	require.NoError(t, err)
+	parsed, err := ParseTransaction(raw)
+	require.NoError(t, err)
+	require.Equal(t, tx, parsed)
+	require.Error(t, tx.Sign(to, thresholdSigner(t, keyData, []int{1, 3})))
+
+	// v0 message with an address table lookup
+	v0 := &Message{
+		Version:             V0Message,
+		Header:              MessageHeader{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 1},
+		AccountKeys:         []Pubkey{from, SystemProgramID},
+		RecentBlockhash:     blockhash,
+		Instructions:        []CompiledInstruction{{ProgramIDIndex: 1, Accounts: []uint8{0, 2}, Data: message.Instructions[0].Data}},
+		AddressTableLookups: []AddressTableLookup{{AccountKey: to, WritableIndexes: []uint8{3}, ReadonlyIndexes: []uint8{}}},
+	}
+	tx = NewTransaction(v0)
+	require.NoError(t, tx.Sign(from, thresholdSigner(t, keyData, []int{2, 3})))
+	raw, err = tx.Serialize()
+	require.NoError(t, err)
+	require.Equal(t, byte(0x80), raw[1+64])
+	parsed, err = ParseTransaction(raw)
+	require.NoError(t, err)
+	require.Equal(t, tx, parsed)
+}
+
+// thresholdSigner runs Ed25519Sign between partList and combines the partial signatures
+func thresholdSigner(t *testing.T, keyData []*tss.KeyStep3Data, partList []int) SignFunc {
+	return func(message []byte) ([]byte, error) {
+		publicKey := edwards.NewPublicKey(keyData[0].PublicKey.X, keyData[0].PublicKey.Y)
+		infos := make(map[int]*sign.Ed25519Sign)
+		out1 := make(map[int]map[int]*tss.Message)
+		for _, id := range partList {
+			infos[id] = sign.NewEd25519SignWithSession("ed25519", id, len(partList), partList, keyData[id-1].ShareI, publicKey, hex.EncodeToString(message))
+			msgs, err := infos[id].SignStep1()
+			require.NoError(t, err)
+			out1[id] = msgs
+		}
+		out2 := make(map[int]map[int]*tss.Message)
+		for id, info := range infos {
+			msgs, err := info.SignStep2(collect(out1, id))
+			require.NoError(t, err)
+			out2[id] = msgs
+		}
+		var r *big.Int
+		var partials []*big.Int
+		for id, info := range infos {
+			si, ri, err := info.SignStep3(collect(out2, id))
+			require.NoError(t, err)
+			r, partials = ri, append(partials, si)
+		}
+		return sign.CombineSignature(r, partials)
+	}
+}
+
+func collect(out map[int]map[int]*tss.Message, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for from, msgMap := range out {
+		if from != id {
+			msgs = append(msgs, msgMap[id])
+		}
+	}
+	return msgs
+}
+
+func keyGen(t *testing.T) []*tss.KeyStep3Data {
+	curve := edwards.Edwards()
+	setUps := make([]*dkg.SetupInfo, 3)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, 2, 3, curve)
+	}
+	out1 := make(map[int]map[int]*tss.Message)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out1[i+1] = msgs
+	}
+	out2 := make(map[int]map[int]*tss.Message)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out1, i+1))
+		require.NoError(t, err)
+		out2[i+1] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, 3)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(out2, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
@@ -0,0 +1,95 @@
+package solana
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"io"
+)
+
+// SignFunc runs threshold Ed25519Sign over message and returns the 64 bytes signature of CombineSignature
+type SignFunc func(message []byte) ([]byte, error)
+
+type Transaction struct {
+	Signatures [][64]byte // one per required signer, in account key order
+	Message    *Message
+}
+
+// NewTransaction unsigned transaction, signatures are zero
+func NewTransaction(message *Message) *Transaction {
+	return &Transaction{
+		Signatures: make([][64]byte, message.Header.NumRequiredSignatures),
+		Message:    message,
+	}
+}
+
+// Sign signature of signer, verified against the signer pubkey before it is set
+func (tx *Transaction) Sign(signer Pubkey, sign SignFunc) error {
+	index := -1
+	for i := 0; i < int(tx.Message.Header.NumRequiredSignatures) && i < len(tx.Message.AccountKeys); i++ {
+		if tx.Message.AccountKeys[i] == signer {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%s is not a signer", signer)
+	}
+	message, err := tx.Message.Serialize()
+	if err != nil {
+		return err
+	}
+	signature, err := sign(message)
+	if err != nil {
+		return err
+	}
+	if len(signature) != 64 || !ed25519.Verify(signer[:], message, signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	copy(tx.Signatures[index][:], signature)
+	return nil
+}
+
+// Serialize wire transaction, base58 or base64 of it is sent to sendTransaction
+func (tx *Transaction) Serialize() ([]byte, error) {
+	if len(tx.Signatures) != int(tx.Message.Header.NumRequiredSignatures) {
+		return nil, fmt.Errorf("signature count mismatch")
+	}
+	message, err := tx.Message.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	out := compactU16(len(tx.Signatures))
+	for _, sig := range tx.Signatures {
+		out = append(out, sig[:]...)
+	}
+	return append(out, message...), nil
+}
+
+// ParseTransaction wire transaction, e.g. built by a dapp, signatures may be zero
+func ParseTransaction(b []byte) (*Transaction, error) {
+	r := bytes.NewReader(b)
+	n, err := readCompactU16(r)
+	if err != nil {
+		return nil, err
+	}
+	if n*64 > r.Len() {
+		return nil, fmt.Errorf("invalid signature count")
+	}
+	tx := &Transaction{Signatures: make([][64]byte, n)}
+	for i := range tx.Signatures {
+		if _, err = io.ReadFull(r, tx.Signatures[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	if tx.Message, err = readMessage(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("trailing transaction bytes")
+	}
+	if n != int(tx.Message.Header.NumRequiredSignatures) {
+		return nil, fmt.Errorf("signature count mismatch")
+	}
+	return tx, nil
+}
@@ -0,0 +1,63 @@
+package tss
+
+import "fmt"
+
+// MessageVersion version of the message envelope, messages of other versions are rejected
+const MessageVersion = 1
+
+// Envelope binds messages to one protocol run: a message of another protocol, session, round or version is rejected,
+// so messages can't be replayed across sessions or rounds. All participants of a run must use the same SessionId,
+// a SessionId must be unique per run. The WithSession constructors require it, an empty SessionId of the deprecated
+// constructors binds protocol and round only.
+type Envelope struct {
+	Protocol  string
+	SessionId string
+	Format    WireFormat // encoding of outgoing payloads, json by default
+}
+
+// SetWireFormat choose the encoding of outgoing payloads of the run, incoming payloads are accepted in both formats
+func (e *Envelope) SetWireFormat(format WireFormat) error {
+	if err := format.Check(); err != nil {
+		return err
+	}
+	e.Format = format
+	return nil
+}
+
+// MarshalData encode a round payload with the wire format of the run
+func (e *Envelope) MarshalData(v interface{}) ([]byte, error) {
+	return MarshalData(e.Format, v)
+}
+
+// NewMessage message produced by step round of the run
+func (e *Envelope) NewMessage(from, to, round int, data []byte) *Message {
+	return &Message{
+		From:      from,
+		To:        to,
+		Data:      string(data),
+		Protocol:  e.Protocol,
+		SessionId: e.SessionId,
+		Round:     round,
+		Version:   MessageVersion,
+	}
+}
+
+// CheckMessage check msg was produced by step round of the run
+func (e *Envelope) CheckMessage(msg *Message, round int) error {
+	if msg == nil {
+		return fmt.Errorf("message is nil")
+	}
+	if msg.Version != MessageVersion {
+		return fmt.Errorf("message version %d not supported", msg.Version)
+	}
+	if msg.Protocol != e.Protocol {
+		return fmt.Errorf("message of protocol %q, expected %q", msg.Protocol, e.Protocol)
+	}
+	if msg.SessionId != e.SessionId {
+		return fmt.Errorf("message of another session")
+	}
+	if msg.Round != round {
+		return fmt.Errorf("message of round %d, expected %d", msg.Round, round)
+	}
+	return nil
+}
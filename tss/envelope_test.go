@@ -0,0 +1,31 @@
+package tss
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	envelope := &Envelope{Protocol: "dkg", SessionId: "s1"}
+	msg := envelope.NewMessage(1, 2, 1, []byte("data"))
+	require.Equal(t, "data", msg.Data)
+	require.NoError(t, envelope.CheckMessage(msg, 1))
+
+	// another round
+	require.Error(t, envelope.CheckMessage(msg, 2))
+	// another session
+	require.Error(t, (&Envelope{Protocol: "dkg", SessionId: "s2"}).CheckMessage(msg, 1))
+	// another protocol
+	require.Error(t, (&Envelope{Protocol: "reshare", SessionId: "s1"}).CheckMessage(msg, 1))
+	// another version
+	other := *msg
+	other.Version = MessageVersion + 1
+	require.Error(t, envelope.CheckMessage(&other, 1))
+	require.Error(t, envelope.CheckMessage(nil, 1))
+	// messages without session only pass runs without session
+	empty := &Envelope{Protocol: "dkg"}
+	require.NoError(t, empty.CheckMessage(empty.NewMessage(1, 2, 1, []byte("data")), 1))
+	require.Error(t, envelope.CheckMessage(empty.NewMessage(1, 2, 1, []byte("data")), 1))
+	require.Error(t, empty.CheckMessage(msg, 1))
+}
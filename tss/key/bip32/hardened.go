@@ -0,0 +1,963 @@
+package bip32
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/garble"
+	"github.com/okx/threshold-lib/crypto/ot"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// Hardened derivation by 2-party garbled circuits, I = HMAC-SHA512(chaincode, 0x00 | ser256(k) | ser32(i)), i >= 2^31.
+// k = x1 + x2 mod N, xi = lambda_i*shareI over {from, to}, P1 garbles with input x1, P2 evaluates with input x2.
+// Only keys of NewTssKeyBip32 with threshold 2 are derived, lambda_1*X1 + lambda_2*X2 = publicKey is checked on the share publicKeys,
+// the child follows standard bip32 like its unhardened children.
+// The HMAC key is the public chaincode, so the ipad and opad blocks are compressed in the clear,
+// only the block of k and the block of the inner hash are garbled.
+// Malicious security by cut-and-choose of Lindell-Pinkas with the checked fraction of shelat-Shen:
+//   - P1 commits to circuits garbled from seeds, P2 opens a random subset and garbles them again, the others are
+//     evaluated and the majority output is taken.
+//   - Evaluator labels of all circuits are derived from one OT key per input bit, so P2 uses the same input everywhere,
+//     the opened circuits check the transferred keys.
+//   - x2 enters as y with x2 = y[:256] ^ M*y[256:] for a public probe resistant M, an abort caused by
+//     selectively wrong keys is independent of x2.
+//   - Labels of the P1 input are checked against committed hashes.
+//   - P2 returns I with a Toeplitz mac of I under a key of P1 computed in the circuits, output labels never leave P2.
+//   - Inputs are bound to the share publicKeys, the circuits output u1 = c2*x1 + r1 to P2 and u2 = c1*x2 + r2 to P1,
+//     ci is a secret 40 bits challenge of Pi, Rj = rj*G is sent before, u1*G = c2*lambda_1*X1 + R1 fails for another x1
+//     except with probability 2^-40, rj hides c*xj statistically.
+// Both learn I = IL | IR, nobody learns k. Child share is shareI + IL, other share holders derive the same child with HardenedChildKey(I).
+
+const (
+	HardenedKeyStart = uint32(0x80000000) // 2^31
+
+	// HardenedCircuits and HardenedChecked default cut-and-choose, 75 of 125 garbled circuits are opened and
+	// the majority of the other 50 is the output, a cheating garbler succeeds with probability about 2^-40.
+	// A circuit has 192k AND gates, 6.2 MB of tables, Step2 of P1 sends the 50 evaluated circuits, about 310 MB,
+	// and both garble all 125. SetCutAndChoose trades security for cost, e.g. 40 of 70 about 2^-22 and 185 MB.
+	HardenedCircuits = 125
+	HardenedChecked  = 75
+)
+
+const (
+	hardenedProtocol = "bip32/hardened" // protocol id of the message envelope
+	probeBits        = 512              // random bits of the encoding of x2
+	challengeBits    = 40               // challenge of the input binding
+	maskBits         = 256 + challengeBits + 40
+	bindingBits      = maskBits + 1 // u = c*x + r
+	macBits          = 40           // tag of I | u2 for P1
+	p1OutputBits     = intermediaryBits + bindingBits
+	macKeyBits       = macBits + p1OutputBits - 1 // Toeplitz matrix of macBits x p1OutputBits
+	intermediaryBits = 512
+)
+
+// circuit input layout, garbler x1 | r1 | c1 | mac key | mac mask, evaluator encoded x2 | r2 | c2
+const (
+	garblerMask      = 256
+	garblerChallenge = garblerMask + maskBits
+	garblerMac       = garblerChallenge + challengeBits
+	garblerInputs    = garblerMac + macKeyBits + macBits
+
+	evaluatorMask      = 256 + probeBits
+	evaluatorChallenge = evaluatorMask + maskBits
+	evaluatorInputs    = evaluatorChallenge + challengeBits
+)
+
+type HardenedStep1Data struct {
+	A           *curves.ECPoint // OT sender
+	R           *curves.ECPoint // r1*G, mask of the binding of x1
+	Commitments [][]byte        // one per garbled circuit
+}
+
+type HardenedStep2Data struct {
+	B       []*curves.ECPoint // OT receiver, choice bits are the encoded x2, r2 and c2
+	R       *curves.ECPoint   // r2*G, mask of the binding of x2
+	Checked []int             // circuits to open, ascending
+}
+
+type HardenedStep3Data struct {
+	E         [][2][]byte         // OT encrypted key pairs
+	Opened    []*HardenedOpening  // checked circuits
+	Evaluated []*HardenedGarbling // other circuits
+}
+
+// HardenedOpening seed of a checked circuit, P2 garbles it again
+type HardenedOpening struct {
+	Index       int
+	Seed        []byte
+	Translation []byte
+}
+
+// HardenedGarbling circuit to evaluate, Translation is label ^ PRF(OT key, index) for both bits of every evaluator input,
+// LabelCommitments are the sorted hashes of both labels of every garbler input
+type HardenedGarbling struct {
+	Index            int
+	Tables           []byte
+	Decode           []bool
+	LabelCommitments []byte
+	Translation      []byte
+	GarblerLabels    []byte
+}
+
+type HardenedStep4Data struct {
+	Intermediary []byte   // I = IL | IR
+	Binding      *big.Int // u2 = c1*x2 + r2
+	Tag          []byte   // mac of I | u2 under the key of P1
+}
+
+// HardenedP1Context garbler
+type HardenedP1Context struct {
+	from, to     int
+	childIdx     uint32
+	tssKey       *TssKey
+	x1           *big.Int
+	share2       *curves.ECPoint // lambda_2*X2, x2*G of P2
+	r1, c1       *big.Int        // binding mask of x1 and challenge of x2
+	mask2        *curves.ECPoint // r2*G
+	mac          []bool          // Toeplitz key and mask
+	circuits     int
+	checked      int
+	circuit      *garble.Circuit
+	seeds        [][]byte
+	translations [][]byte
+	keys         [][2][]byte // OT keys of the evaluator inputs
+	sender       *ot.Sender
+	envelope     tss.Envelope // protocol and session of the messages
+	RoundNumber  int
+}
+
+// HardenedP2Context evaluator
+type HardenedP2Context struct {
+	from, to    int
+	childIdx    uint32
+	tssKey      *TssKey
+	x2          *big.Int
+	share1      *curves.ECPoint // lambda_1*X1, x1*G of P1
+	r2, c2      *big.Int        // binding mask of x2 and challenge of x1
+	mask1       *curves.ECPoint // r1*G
+	y           []bool          // encoded x2 | r2 | c2
+	circuits    int
+	checked     []int
+	circuit     *garble.Circuit
+	commitments [][]byte
+	receiver    *ot.Receiver
+	envelope    tss.Envelope // protocol and session of the messages
+	RoundNumber int
+}
+
+// NewHardenedP1 from is P1 id, to is P2 id, tssKey is the parent key with the share of P1,
+// sharePubKeyMap are the share publicKeys of dkg, both use the same unique sessionId
+func NewHardenedP1(sessionId string, tssKey *TssKey, sharePubKeyMap map[int]*curves.ECPoint, from, to int, childIdx uint32) (*HardenedP1Context, error) {
+	if sessionId == "" {
+		return nil, fmt.Errorf("session id is empty")
+	}
+	x1, share2, err := hardenedShare(tssKey, sharePubKeyMap, from, to, childIdx)
+	if err != nil {
+		return nil, err
+	}
+	return &HardenedP1Context{
+		from:        from,
+		to:          to,
+		childIdx:    childIdx,
+		tssKey:      tssKey,
+		x1:          x1,
+		share2:      share2,
+		circuits:    HardenedCircuits,
+		checked:     HardenedChecked,
+		envelope:    tss.Envelope{Protocol: hardenedProtocol, SessionId: sessionId},
+		RoundNumber: 1,
+	}, nil
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (p1 *HardenedP1Context) SetWireFormat(format tss.WireFormat) error {
+	return p1.envelope.SetWireFormat(format)
+}
+
+// SetCutAndChoose garbled and opened circuits before Step1, P2 must use the same values
+func (p1 *HardenedP1Context) SetCutAndChoose(circuits, checked int) error {
+	if p1.RoundNumber != 1 {
+		return fmt.Errorf("round error")
+	}
+	if err := checkCutAndChoose(circuits, checked); err != nil {
+		return err
+	}
+	p1.circuits, p1.checked = circuits, checked
+	return nil
+}
+
+// NewHardenedP2 from is P1 id, to is P2 id, tssKey is the parent key with the share of P2,
+// sharePubKeyMap are the share publicKeys of dkg, both use the same unique sessionId
+func NewHardenedP2(sessionId string, tssKey *TssKey, sharePubKeyMap map[int]*curves.ECPoint, from, to int, childIdx uint32) (*HardenedP2Context, error) {
+	if sessionId == "" {
+		return nil, fmt.Errorf("session id is empty")
+	}
+	x2, share1, err := hardenedShare(tssKey, sharePubKeyMap, to, from, childIdx)
+	if err != nil {
+		return nil, err
+	}
+	return &HardenedP2Context{
+		from:        from,
+		to:          to,
+		childIdx:    childIdx,
+		tssKey:      tssKey,
+		x2:          x2,
+		share1:      share1,
+		circuits:    HardenedCircuits,
+		checked:     make([]int, HardenedChecked),
+		envelope:    tss.Envelope{Protocol: hardenedProtocol, SessionId: sessionId},
+		RoundNumber: 1,
+	}, nil
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (p2 *HardenedP2Context) SetWireFormat(format tss.WireFormat) error {
+	return p2.envelope.SetWireFormat(format)
+}
+
+// SetCutAndChoose garbled and opened circuits before Step1, P1 must use the same values
+func (p2 *HardenedP2Context) SetCutAndChoose(circuits, checked int) error {
+	if p2.RoundNumber != 1 {
+		return fmt.Errorf("round error")
+	}
+	if err := checkCutAndChoose(circuits, checked); err != nil {
+		return err
+	}
+	p2.circuits, p2.checked = circuits, make([]int, checked)
+	return nil
+}
+
+func checkCutAndChoose(circuits, checked int) error {
+	if checked <= 0 || checked >= circuits {
+		return fmt.Errorf("cut-and-choose parameter error")
+	}
+	return nil
+}
+
+// hardenedShare additive share lambda_id*shareI and lambda_peer*X_peer, lambda over {id, peer}.
+// Share publicKeys of the parent are those of dkg plus the offset of tssKey, the key must be 2/n,
+// lambda_id*X_id + lambda_peer*X_peer = publicKey.
+func hardenedShare(tssKey *TssKey, sharePubKeyMap map[int]*curves.ECPoint, id, peer int, childIdx uint32) (*big.Int, *curves.ECPoint, error) {
+	if tssKey == nil || tssKey.shareI == nil || id == peer || id <= 0 || peer <= 0 {
+		return nil, nil, fmt.Errorf("parameter error")
+	}
+	if !tssKey.standard {
+		return nil, nil, fmt.Errorf("hardened derivation requires a bip32 key, use NewTssKeyBip32")
+	}
+	if childIdx < HardenedKeyStart {
+		return nil, nil, fmt.Errorf("not a hardened index")
+	}
+	curve := tssKey.publicKey.Curve
+	offset := curves.ScalarToPoint(curve, tssKey.offsetSonPri)
+	points := make([]*curves.ECPoint, 2)
+	for i, j := range []int{id, peer} {
+		X := sharePubKeyMap[j]
+		if X == nil || X.Curve != curve {
+			return nil, nil, fmt.Errorf("share publicKey %d error", j)
+		}
+		point, err := X.Add(offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		points[i] = point
+	}
+	if !curves.ScalarToPoint(curve, tssKey.shareI).Equals(points[0]) {
+		return nil, nil, fmt.Errorf("share publicKey %d mismatch", id)
+	}
+	xList := []*big.Int{big.NewInt(int64(id)), big.NewInt(int64(peer))}
+	x := vss.CalLagrangian(curve, big.NewInt(int64(id)), tssKey.shareI, xList)
+	peerShare := points[1].ScalarMult(vss.CalLagrangian(curve, big.NewInt(int64(peer)), big.NewInt(1), xList))
+	publicKey, err := curves.ScalarToPoint(curve, x).Add(peerShare)
+	if err != nil || !publicKey.Equals(tssKey.publicKey) {
+		return nil, nil, fmt.Errorf("2-party derivation requires a 2/n key")
+	}
+	return x, peerShare, nil
+}
+
+// Step1 P1 garble every circuit from a fresh seed, send the commitments and the OT sender point
+func (p1 *HardenedP1Context) Step1() (*tss.Message, error) {
+	if p1.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	p1.circuit = hardenedCircuit(p1.tssKey, p1.childIdx)
+	mac, err := randomBytes((macKeyBits + macBits + 7) / 8)
+	if err != nil {
+		return nil, err
+	}
+	p1.mac = unpackBits(mac)[:macKeyBits+macBits]
+	if p1.r1, err = randomInt(maskBits); err != nil {
+		return nil, err
+	}
+	if p1.c1, err = randomInt(challengeBits); err != nil {
+		return nil, err
+	}
+	p1.keys = make([][2][]byte, p1.circuit.EvaluatorInputs)
+	for w := range p1.keys {
+		for bit := range p1.keys[w] {
+			if p1.keys[w][bit], err = randomBytes(ot.MaxMessageSize); err != nil {
+				return nil, err
+			}
+		}
+	}
+	p1.seeds = make([][]byte, p1.circuits)
+	p1.translations = make([][]byte, p1.circuits)
+	commitments := make([][]byte, p1.circuits)
+	for j := range p1.seeds {
+		if p1.seeds[j], err = randomBytes(garble.SeedSize); err != nil {
+			return nil, err
+		}
+		_, g, err := p1.garble(j)
+		if err != nil {
+			return nil, err
+		}
+		p1.translations[j] = g.Translation
+		commitments[j] = g.commitment(p1.envelope.SessionId)
+	}
+	sender, A := ot.NewSender(p1.circuit.EvaluatorInputs)
+	p1.sender = sender
+	p1.RoundNumber = 2
+	R := curves.ScalarToPoint(p1.tssKey.publicKey.Curve, p1.r1)
+	return hardenedMessage(&p1.envelope, p1.from, p1.to, 1, HardenedStep1Data{A: A, R: R, Commitments: commitments})
+}
+
+// Step1 P2 choose the circuits to open and the OT keys of the encoded x2
+func (p2 *HardenedP2Context) Step1(msg *tss.Message) (*tss.Message, error) {
+	if p2.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	var content HardenedStep1Data
+	if err := hardenedContent(&p2.envelope, msg, p2.from, p2.to, 1, &content); err != nil {
+		return nil, err
+	}
+	if len(content.Commitments) != p2.circuits {
+		return nil, fmt.Errorf("garbled circuit number error")
+	}
+	if content.R == nil || content.R.Curve != p2.tssKey.publicKey.Curve || !content.R.IsOnCurve() {
+		return nil, fmt.Errorf("binding mask error")
+	}
+	p2.circuit = hardenedCircuit(p2.tssKey, p2.childIdx)
+	y, err := encodeShare(p2.x2)
+	if err != nil {
+		return nil, err
+	}
+	if p2.r2, err = randomInt(maskBits); err != nil {
+		return nil, err
+	}
+	if p2.c2, err = randomInt(challengeBits); err != nil {
+		return nil, err
+	}
+	y = append(append(y, intBits(p2.r2, maskBits)...), intBits(p2.c2, challengeBits)...)
+	receiver, B, err := ot.NewReceiver(content.A, y)
+	if err != nil {
+		return nil, err
+	}
+	// random subset of the circuits
+	order := make([]int, p2.circuits)
+	for j := range order {
+		order[j] = j
+	}
+	for j := len(order) - 1; j > 0; j-- {
+		k, err := rand.Int(rand.Reader, big.NewInt(int64(j+1)))
+		if err != nil {
+			return nil, err
+		}
+		order[j], order[k.Int64()] = order[k.Int64()], order[j]
+	}
+	copy(p2.checked, order)
+	sort.Ints(p2.checked)
+	p2.y, p2.receiver, p2.commitments, p2.mask1 = y, receiver, content.Commitments, content.R
+	p2.RoundNumber = 2
+	R := curves.ScalarToPoint(p2.tssKey.publicKey.Curve, p2.r2)
+	return hardenedMessage(&p2.envelope, p2.to, p2.from, 2, HardenedStep2Data{B: B, R: R, Checked: p2.checked})
+}
+
+// Step2 P1 transfer the OT keys, open the checked circuits and send the others with the labels of x1 and the mac key
+func (p1 *HardenedP1Context) Step2(msg *tss.Message) (*tss.Message, error) {
+	if p1.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	var content HardenedStep2Data
+	if err := hardenedContent(&p1.envelope, msg, p1.to, p1.from, 2, &content); err != nil {
+		return nil, err
+	}
+	if len(content.Checked) != p1.checked {
+		return nil, fmt.Errorf("checked circuit number error")
+	}
+	if content.R == nil || content.R.Curve != p1.tssKey.publicKey.Curve || !content.R.IsOnCurve() {
+		return nil, fmt.Errorf("binding mask error")
+	}
+	p1.mask2 = content.R
+	checked := make([]bool, p1.circuits)
+	for i, j := range content.Checked {
+		if j < 0 || j >= p1.circuits || (i > 0 && j <= content.Checked[i-1]) {
+			return nil, fmt.Errorf("checked circuit index error")
+		}
+		checked[j] = true
+	}
+	m0, m1 := make([][]byte, len(p1.keys)), make([][]byte, len(p1.keys))
+	for w, pair := range p1.keys {
+		m0[w], m1[w] = pair[0], pair[1]
+	}
+	E, err := p1.sender.Encrypt(content.B, m0, m1)
+	if err != nil {
+		return nil, err
+	}
+	input := append(append(append(intBits(p1.x1, 256), intBits(p1.r1, maskBits)...), intBits(p1.c1, challengeBits)...), p1.mac...)
+	data := HardenedStep3Data{E: E}
+	for j := range p1.seeds {
+		if checked[j] {
+			data.Opened = append(data.Opened, &HardenedOpening{Index: j, Seed: p1.seeds[j], Translation: p1.translations[j]})
+			continue
+		}
+		garbler, g, err := p1.garble(j)
+		if err != nil {
+			return nil, err
+		}
+		labels, err := garbler.GarblerLabels(input)
+		if err != nil {
+			return nil, err
+		}
+		g.GarblerLabels = joinLabels(labels)
+		data.Evaluated = append(data.Evaluated, g)
+	}
+	p1.x1, p1.r1 = nil, nil
+	p1.RoundNumber = 3
+	return hardenedMessage(&p1.envelope, p1.from, p1.to, 3, data)
+}
+
+// Step2 P2 check the opened circuits, evaluate the others, return hardened child key and I with its mac for P1
+func (p2 *HardenedP2Context) Step2(msg *tss.Message) (*TssKey, *tss.Message, error) {
+	if p2.RoundNumber != 2 {
+		return nil, nil, fmt.Errorf("round error")
+	}
+	var content HardenedStep3Data
+	if err := hardenedContent(&p2.envelope, msg, p2.from, p2.to, 3, &content); err != nil {
+		return nil, nil, err
+	}
+	if len(content.Opened) != len(p2.checked) || len(content.Evaluated) != p2.circuits-len(p2.checked) {
+		return nil, nil, fmt.Errorf("garbled circuit number error")
+	}
+	keys, err := p2.receiver.Decrypt(content.E)
+	if err != nil {
+		return nil, nil, err
+	}
+	checked := make([]bool, p2.circuits)
+	for i, o := range content.Opened {
+		if o == nil || o.Index != p2.checked[i] {
+			return nil, nil, fmt.Errorf("opened circuit index error")
+		}
+		if err := p2.checkOpening(o, keys); err != nil {
+			return nil, nil, err
+		}
+		checked[o.Index] = true
+	}
+	counts := make(map[string]int)
+	outputs := make(map[string][]bool)
+	i := 0
+	for j := 0; j < p2.circuits; j++ {
+		if checked[j] {
+			continue
+		}
+		g := content.Evaluated[i]
+		i++
+		if g == nil || g.Index != j {
+			return nil, nil, fmt.Errorf("evaluated circuit index error")
+		}
+		out, err := p2.evaluate(g, keys)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := string(packBits(out))
+		counts[key]++
+		outputs[key] = out
+	}
+	var output []bool
+	for key, count := range counts {
+		if 2*count > len(content.Evaluated) {
+			output = outputs[key]
+		}
+	}
+	if output == nil {
+		return nil, nil, fmt.Errorf("no majority output of the evaluated circuits")
+	}
+	// I | u2 | tag | u1
+	u1 := bitsInt(output[p1OutputBits+macBits:])
+	if !checkBinding(u1, p2.c2, p2.share1, p2.mask1) {
+		return nil, nil, fmt.Errorf("binding of the P1 input error")
+	}
+	p2.x2, p2.y, p2.r2 = nil, nil, nil
+	p2.RoundNumber = -1
+	intermediary := packBits(output[:intermediaryBits])
+	child, err := p2.tssKey.HardenedChildKey(p2.childIdx, intermediary)
+	if err != nil {
+		return nil, nil, err
+	}
+	reply, err := hardenedMessage(&p2.envelope, p2.to, p2.from, 4, HardenedStep4Data{
+		Intermediary: intermediary,
+		Binding:      bitsInt(output[intermediaryBits:p1OutputBits]),
+		Tag:          packBits(output[p1OutputBits : p1OutputBits+macBits]),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return child, reply, nil
+}
+
+// Step3 P1 check the mac of I, return hardened child key
+func (p1 *HardenedP1Context) Step3(msg *tss.Message) (*TssKey, error) {
+	if p1.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	var content HardenedStep4Data
+	if err := hardenedContent(&p1.envelope, msg, p1.to, p1.from, 4, &content); err != nil {
+		return nil, err
+	}
+	if len(content.Intermediary) != intermediaryBits/8 {
+		return nil, fmt.Errorf("intermediary length error")
+	}
+	if content.Binding == nil || content.Binding.Sign() < 0 || content.Binding.BitLen() > bindingBits {
+		return nil, fmt.Errorf("binding length error")
+	}
+	tag := packBits(macTag(p1.mac, append(unpackBits(content.Intermediary), intBits(content.Binding, bindingBits)...)))
+	if subtle.ConstantTimeCompare(tag, content.Tag) != 1 {
+		return nil, fmt.Errorf("intermediary mac error")
+	}
+	if !checkBinding(content.Binding, p1.c1, p1.share2, p1.mask2) {
+		return nil, fmt.Errorf("binding of the P2 input error")
+	}
+	p1.RoundNumber = -1
+	return p1.tssKey.HardenedChildKey(p1.childIdx, content.Intermediary)
+}
+
+// garble circuit j again from its seed
+func (p1 *HardenedP1Context) garble(j int) (*garble.Garbler, *HardenedGarbling, error) {
+	garbler, gc, err := garble.GarbleSeed(p1.circuit, p1.seeds[j])
+	if err != nil {
+		return nil, nil, err
+	}
+	pairs := garbler.EvaluatorLabelPairs()
+	translation := make([]byte, 0, 2*garble.LabelSize*len(pairs))
+	for w, pair := range pairs {
+		for bit, label := range pair {
+			masked := label.Xor(keyLabel(p1.keys[w][bit], j))
+			translation = append(translation, masked[:]...)
+		}
+	}
+	return garbler, &HardenedGarbling{
+		Index:            j,
+		Tables:           gc.Tables,
+		Decode:           gc.Decode,
+		LabelCommitments: labelCommitments(j, garbler),
+		Translation:      translation,
+	}, nil
+}
+
+// checkOpening garble the opened circuit again, compare with its commitment and the transferred keys
+func (p2 *HardenedP2Context) checkOpening(o *HardenedOpening, keys [][]byte) error {
+	if len(o.Translation) != 2*garble.LabelSize*p2.circuit.EvaluatorInputs {
+		return fmt.Errorf("opened circuit %d translation length error", o.Index)
+	}
+	garbler, gc, err := garble.GarbleSeed(p2.circuit, o.Seed)
+	if err != nil {
+		return err
+	}
+	g := &HardenedGarbling{
+		Index:            o.Index,
+		Tables:           gc.Tables,
+		Decode:           gc.Decode,
+		LabelCommitments: labelCommitments(o.Index, garbler),
+		Translation:      o.Translation,
+	}
+	if !bytes.Equal(g.commitment(p2.envelope.SessionId), p2.commitments[o.Index]) {
+		return fmt.Errorf("opened circuit %d commitment mismatch", o.Index)
+	}
+	for w, pair := range garbler.EvaluatorLabelPairs() {
+		bit := 0
+		if p2.y[w] {
+			bit = 1
+		}
+		if evaluatorLabel(o.Translation, keys[w], o.Index, w, p2.y[w]) != pair[bit] {
+			return fmt.Errorf("opened circuit %d oblivious transfer mismatch", o.Index)
+		}
+	}
+	return nil
+}
+
+// evaluate a circuit after the checks of its commitment and of the garbler labels
+func (p2 *HardenedP2Context) evaluate(g *HardenedGarbling, keys [][]byte) ([]bool, error) {
+	c := p2.circuit
+	if len(g.Translation) != 2*garble.LabelSize*c.EvaluatorInputs || len(g.LabelCommitments) != 2*sha256.Size*c.GarblerInputs {
+		return nil, fmt.Errorf("evaluated circuit %d length error", g.Index)
+	}
+	if !bytes.Equal(g.commitment(p2.envelope.SessionId), p2.commitments[g.Index]) {
+		return nil, fmt.Errorf("evaluated circuit %d commitment mismatch", g.Index)
+	}
+	garblerLabels, err := splitLabels(g.GarblerLabels, c.GarblerInputs)
+	if err != nil {
+		return nil, err
+	}
+	for w, label := range garblerLabels {
+		h := labelCommitment(g.Index, w, label)
+		commitments := g.LabelCommitments[2*sha256.Size*w:]
+		if !bytes.Equal(h, commitments[:sha256.Size]) && !bytes.Equal(h, commitments[sha256.Size:2*sha256.Size]) {
+			return nil, fmt.Errorf("evaluated circuit %d garbler label %d error", g.Index, w)
+		}
+	}
+	evaluatorLabels := make([]garble.Label, c.EvaluatorInputs)
+	for w := range evaluatorLabels {
+		evaluatorLabels[w] = evaluatorLabel(g.Translation, keys[w], g.Index, w, p2.y[w])
+	}
+	return garble.Evaluate(c, &garble.GarbledCircuit{Tables: g.Tables, Decode: g.Decode}, garblerLabels, evaluatorLabels)
+}
+
+// commitment binds a garbling to the session and its index before P2 chooses the circuits to open
+func (g *HardenedGarbling) commitment(sessionId string) []byte {
+	h := sha256.New()
+	h.Write(uint32Bytes(uint32(len(sessionId))))
+	h.Write([]byte(sessionId))
+	h.Write(uint32Bytes(uint32(g.Index)))
+	h.Write(g.Tables)
+	for _, d := range g.Decode {
+		if d {
+			h.Write([]byte{1})
+		} else {
+			h.Write([]byte{0})
+		}
+	}
+	h.Write(g.LabelCommitments)
+	h.Write(g.Translation)
+	return h.Sum(nil)
+}
+
+// labelCommitments hashes of both labels of every garbler input, sorted so the order hides the bit
+func labelCommitments(j int, garbler *garble.Garbler) []byte {
+	pairs := garbler.GarblerLabelPairs()
+	out := make([]byte, 0, 2*sha256.Size*len(pairs))
+	for w, pair := range pairs {
+		h0, h1 := labelCommitment(j, w, pair[0]), labelCommitment(j, w, pair[1])
+		if bytes.Compare(h0, h1) > 0 {
+			h0, h1 = h1, h0
+		}
+		out = append(append(out, h0...), h1...)
+	}
+	return out
+}
+
+func labelCommitment(j, w int, label garble.Label) []byte {
+	h := sha256.New()
+	h.Write(uint32Bytes(uint32(j)))
+	h.Write(uint32Bytes(uint32(w)))
+	h.Write(label[:])
+	return h.Sum(nil)
+}
+
+// keyLabel PRF(key, j), mask of the evaluator label in circuit j
+func keyLabel(key []byte, j int) garble.Label {
+	sum := sha256.Sum256(append(append([]byte{}, key...), uint32Bytes(uint32(j))...))
+	var label garble.Label
+	copy(label[:], sum[:])
+	return label
+}
+
+// evaluatorLabel label of evaluator input w in circuit j from the transferred key of bit
+func evaluatorLabel(translation, key []byte, j, w int, bit bool) garble.Label {
+	offset := 2 * garble.LabelSize * w
+	if bit {
+		offset += garble.LabelSize
+	}
+	var label garble.Label
+	copy(label[:], translation[offset:])
+	return label.Xor(keyLabel(key, j))
+}
+
+// probeMatrix public random 256 x probeBits matrix M, every nonzero sum of rows of [I | M] has weight
+// at least 40 except with probability below 2^-50 over the choice of M
+var probeMatrix = func() [256][probeBits / 8]byte {
+	var m [256][probeBits / 8]byte
+	for i := range m {
+		m[i] = sha512.Sum512(append([]byte("bip32/hardened probe resistant matrix"), uint32Bytes(uint32(i))...))
+	}
+	return m
+}()
+
+// encodeShare y = (x2 ^ M*z) | z for random z, 256 + probeBits evaluator input bits
+func encodeShare(x2 *big.Int) ([]bool, error) {
+	zBytes, err := randomBytes(probeBits / 8)
+	if err != nil {
+		return nil, err
+	}
+	z := unpackBits(zBytes)
+	y := append(intBits(x2, 256), z...)
+	for i := 0; i < 256; i++ {
+		for t, bit := range unpackBits(probeMatrix[i][:]) {
+			if bit && z[t] {
+				y[i] = !y[i]
+			}
+		}
+	}
+	return y, nil
+}
+
+// macTag Toeplitz mac of the bits of I | u2, tag_r = mask_r ^ sum_c key[r - c + p1OutputBits - 1] & out_c
+func macTag(mac []bool, out []bool) []bool {
+	tag := make([]bool, macBits)
+	for r := range tag {
+		tag[r] = mac[macKeyBits+r]
+		for c, bit := range out {
+			if bit && mac[r-c+p1OutputBits-1] {
+				tag[r] = !tag[r]
+			}
+		}
+	}
+	return tag
+}
+
+// checkBinding u*G = c*X + R, X is x*G of the peer input and R its mask
+func checkBinding(u, c *big.Int, X, R *curves.ECPoint) bool {
+	uG := curves.ScalarToPoint(X.Curve, u)
+	cX := X.ScalarMult(c)
+	if cX == nil {
+		// c = 0
+		return uG.Equals(R)
+	}
+	expected, err := cX.Add(R)
+	return err == nil && uG.Equals(expected)
+}
+
+// HardenedChildKey child of intermediary I = IL | IR computed by the hardened derivation
+func (tssKey *TssKey) HardenedChildKey(childIdx uint32, intermediary []byte) (*TssKey, error) {
+	if childIdx < HardenedKeyStart {
+		return nil, fmt.Errorf("not a hardened index")
+	}
+	if len(intermediary) != 64 {
+		return nil, fmt.Errorf("intermediary length error")
+	}
+	if !tssKey.standard {
+		return nil, fmt.Errorf("hardened derivation requires a bip32 key, use NewTssKeyBip32")
+	}
+	return tssKey.childKey(childIdx, intermediary)
+}
+
+// hardenedCircuit HMAC-SHA512(chaincode, 0x00 | ser256(x1 + x2 mod N) | ser32(i)), output I bits big endian,
+// u2 = c1*x2 + r2, the mac of I | u2 and u1 = c2*x1 + r1, see the input layout
+func hardenedCircuit(tssKey *TssKey, childIdx uint32) *garble.Circuit {
+	N := tssKey.publicKey.Curve.Params().N
+	b := garble.NewBuilder(garblerInputs, evaluatorInputs)
+	x1, x2 := make(garble.Word, 256), make(garble.Word, 256)
+	for i := range x1 {
+		x1[i], x2[i] = b.GarblerInput(i), b.EvaluatorInput(i)
+		// x2 = y[:256] ^ M*y[256:]
+		for t, bit := range unpackBits(probeMatrix[i][:]) {
+			if bit {
+				x2[i] = b.Xor(x2[i], b.EvaluatorInput(256+t))
+			}
+		}
+	}
+	// k = x1 + x2 mod N, inputs are reduced first as the binding holds mod N
+	sum := b.AddWord(reduceWord(b, x1, N), reduceWord(b, x2, N))
+	diff, borrow := b.SubBorrow(sum, garble.ConstBigWord(N, 257))
+	k := b.Mux(borrow, diff, sum)[:256]
+
+	// inner block 0x00 | k | i | padding, length (128 + 37)*8
+	data := make([]garble.Word, 128)
+	for m := range data {
+		data[m] = garble.ConstWord(0, 8)
+	}
+	for m := 0; m < 32; m++ {
+		data[1+m] = k[8*(31-m) : 8*(32-m)]
+	}
+	for m := 0; m < 4; m++ {
+		data[33+m] = garble.ConstWord(uint64(childIdx>>uint(8*(3-m))), 8)
+	}
+	data[37] = garble.ConstWord(0x80, 8)
+	data[126] = garble.ConstWord((128+37)*8>>8, 8)
+	data[127] = garble.ConstWord((128+37)*8&0xff, 8)
+	var block [16]garble.Word
+	for t := range block {
+		block[t] = make(garble.Word, 0, 64)
+		for m := 7; m >= 0; m-- {
+			block[t] = append(block[t], data[8*t+m]...)
+		}
+	}
+	ipad, opad := hmacPadStates(tssKey.chaincode)
+	inner := b.SHA512Compress(constState(ipad), block)
+
+	// outer block inner hash | padding, length (128 + 64)*8
+	var outer [16]garble.Word
+	copy(outer[:], inner[:])
+	outer[8] = garble.ConstWord(0x8000000000000000, 64)
+	for t := 9; t < 15; t++ {
+		outer[t] = garble.ConstWord(0, 64)
+	}
+	outer[15] = garble.ConstWord((128+64)*8, 64)
+	I := b.SHA512Compress(constState(opad), outer)
+
+	outputs := make([]garble.Wire, 0, p1OutputBits+macBits+bindingBits)
+	for _, w := range I {
+		for i := 63; i >= 0; i-- {
+			outputs = append(outputs, w[i])
+		}
+	}
+	c1, r2 := inputWord(b.GarblerInput, garblerChallenge, challengeBits), inputWord(b.EvaluatorInput, evaluatorMask, maskBits)
+	outputs = append(outputs, bindingWord(b, c1, x2, r2)...)
+	// mac of I | u2 for P1, see macTag
+	for r := 0; r < macBits; r++ {
+		tag := b.GarblerInput(garblerMac + macKeyBits + r)
+		for c := 0; c < p1OutputBits; c++ {
+			tag = b.Xor(tag, b.And(b.GarblerInput(garblerMac+r-c+p1OutputBits-1), outputs[c]))
+		}
+		outputs = append(outputs, tag)
+	}
+	c2, r1 := inputWord(b.EvaluatorInput, evaluatorChallenge, challengeBits), inputWord(b.GarblerInput, garblerMask, maskBits)
+	outputs = append(outputs, bindingWord(b, c2, x1, r1)...)
+	return b.Circuit(outputs)
+}
+
+// reduceWord x mod N of 256 bits x, N > 2^255, return 257 bits
+func reduceWord(b *garble.Builder, x garble.Word, N *big.Int) garble.Word {
+	x = append(append(garble.Word{}, x...), garble.Zero)
+	diff, borrow := b.SubBorrow(x, garble.ConstBigWord(N, 257))
+	return append(b.Mux(borrow, diff, x)[:256], garble.Zero)
+}
+
+// bindingWord u = c*x + r over the integers, bindingBits little endian
+func bindingWord(b *garble.Builder, c, x, r garble.Word) garble.Word {
+	u := append(append(garble.Word{}, r...), garble.Zero)
+	for i, bit := range c {
+		term := garble.ConstWord(0, bindingBits)
+		for j, w := range x {
+			term[i+j] = b.And(bit, w)
+		}
+		u = b.AddWord(u, term)
+	}
+	return u
+}
+
+func inputWord(input func(int) garble.Wire, offset, n int) garble.Word {
+	w := make(garble.Word, n)
+	for i := range w {
+		w[i] = input(offset + i)
+	}
+	return w
+}
+
+// hmacPadStates SHA-512 state after the ipad and opad blocks of key
+func hmacPadStates(key []byte) ([8]uint64, [8]uint64) {
+	if len(key) > sha512.BlockSize {
+		sum := sha512.Sum512(key)
+		key = sum[:]
+	}
+	ipad, opad := make([]byte, sha512.BlockSize), make([]byte, sha512.BlockSize)
+	copy(ipad, key)
+	copy(opad, key)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+	return garble.SHA512Compress(garble.SHA512IV, ipad), garble.SHA512Compress(garble.SHA512IV, opad)
+}
+
+func constState(state [8]uint64) [8]garble.Word {
+	var out [8]garble.Word
+	for i, v := range state {
+		out[i] = garble.ConstWord(v, 64)
+	}
+	return out
+}
+
+// intBits n bits of k, little endian
+func intBits(k *big.Int, n int) []bool {
+	bits := make([]bool, n)
+	for i := range bits {
+		bits[i] = k.Bit(i) == 1
+	}
+	return bits
+}
+
+// bitsInt little endian bits to integer
+func bitsInt(bits []bool) *big.Int {
+	k := new(big.Int)
+	for i, bit := range bits {
+		if bit {
+			k.SetBit(k, i, 1)
+		}
+	}
+	return k
+}
+
+// packBits big endian bits to bytes
+func packBits(bits []bool) []byte {
+	out := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			out[i/8] |= 0x80 >> uint(i%8)
+		}
+	}
+	return out
+}
+
+// unpackBits bytes to big endian bits
+func unpackBits(b []byte) []bool {
+	out := make([]bool, 8*len(b))
+	for i := range out {
+		out[i] = b[i/8]&(0x80>>uint(i%8)) != 0
+	}
+	return out
+}
+
+// randomInt uniform below 2^bits
+func randomInt(bits int) (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	return b, err
+}
+
+func joinLabels(labels []garble.Label) []byte {
+	out := make([]byte, 0, len(labels)*garble.LabelSize)
+	for _, l := range labels {
+		out = append(out, l[:]...)
+	}
+	return out
+}
+
+func splitLabels(in []byte, n int) ([]garble.Label, error) {
+	if len(in) != n*garble.LabelSize {
+		return nil, fmt.Errorf("labels length error")
+	}
+	labels := make([]garble.Label, n)
+	for i := range labels {
+		copy(labels[i][:], in[i*garble.LabelSize:])
+	}
+	return labels, nil
+}
+
+func hardenedMessage(envelope *tss.Envelope, from, to, round int, content interface{}) (*tss.Message, error) {
+	bytes, err := envelope.MarshalData(content)
+	if err != nil {
+		return nil, err
+	}
+	return envelope.NewMessage(from, to, round, bytes), nil
+}
+
+func hardenedContent(envelope *tss.Envelope, msg *tss.Message, from, to, round int, content interface{}) error {
+	if msg == nil || msg.From != from || msg.To != to {
+		return fmt.Errorf("message mismatch")
+	}
+	if err := envelope.CheckMessage(msg, round); err != nil {
+		return err
+	}
+	return tss.UnmarshalData([]byte(msg.Data), content)
+}
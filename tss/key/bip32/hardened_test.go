@@ -0,0 +1,270 @@
+package bip32
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/stretchr/testify/require"
+)
+
+// bip32 test vector 1, master key
+const (
+	vector1Key       = "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"
+	vector1ChainCode = "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508"
+)
+
+func TestHardenedChildKey(t *testing.T) {
+	curve := secp256k1.S256()
+	x, _ := new(big.Int).SetString(vector1Key, 16)
+	shares, sharePubKeyMap := hardenedShares(t, x, 2, 3)
+
+	// m/0H
+	child1, child3 := deriveHardened(t, shares[1], shares[3], sharePubKeyMap, HardenedKeyStart)
+	xpub, err := child1.Xpub()
+	require.NoError(t, err)
+	require.Equal(t, "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw", xpub)
+	xpub3, _ := child3.Xpub()
+	require.Equal(t, xpub, xpub3)
+
+	// m/0H/1/2H mixes unhardened and hardened levels of bip32
+	child1, err = child1.NewChildKey(1)
+	require.NoError(t, err)
+	child3, err = child3.NewChildKey(1)
+	require.NoError(t, err)
+	xpub, _ = child1.Xpub()
+	require.Equal(t, "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ", xpub)
+	childIdx := HardenedKeyStart + 2
+	p1, p2 := newHardenedPair(t, child1, child3, sharePubKeyMap, childIdx)
+	I := runHardened(t, p1, p2)
+	child1, err = p1.tssKey.HardenedChildKey(childIdx, I)
+	require.NoError(t, err)
+	xpub, _ = child1.Xpub()
+	require.Equal(t, "xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5", xpub)
+
+	// the third holder derives from the intermediary
+	parent2, err := shares[2].HardenedChildKey(HardenedKeyStart, mustIntermediary(t, shares[1], shares[3], sharePubKeyMap, HardenedKeyStart))
+	require.NoError(t, err)
+	parent2, err = parent2.NewChildKey(1)
+	require.NoError(t, err)
+	child2, err := parent2.HardenedChildKey(childIdx, I)
+	require.NoError(t, err)
+	require.True(t, child2.PublicKey().Equals(child1.PublicKey()))
+	xList := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	key := vss.CalLagrangian(curve, big.NewInt(1), child1.ShareI(), xList)
+	key.Add(key, vss.CalLagrangian(curve, big.NewInt(2), child2.ShareI(), xList))
+	require.True(t, curves.ScalarToPoint(curve, key).Equals(child1.PublicKey()))
+
+	// message of another session
+	p1, _ = newHardenedPair(t, shares[1], shares[3], sharePubKeyMap, childIdx)
+	msg1, err := p1.Step1()
+	require.NoError(t, err)
+	other, err := NewHardenedP2("other", shares[3], sharePubKeyMap, 1, 3, childIdx)
+	require.NoError(t, err)
+	require.NoError(t, other.SetCutAndChoose(5, 3))
+	_, err = other.Step1(msg1)
+	require.Error(t, err)
+	_, err = NewHardenedP1("", shares[1], sharePubKeyMap, 1, 3, childIdx)
+	require.Error(t, err)
+
+	// non-hardened index
+	_, err = NewHardenedP1("hardened", shares[1], sharePubKeyMap, 1, 3, 44)
+	require.Error(t, err)
+	_, err = shares[1].HardenedChildKey(44, I)
+	require.Error(t, err)
+	require.Error(t, p1.SetCutAndChoose(4, 4))
+}
+
+func TestHardenedKeyCheck(t *testing.T) {
+	curve := secp256k1.S256()
+	x := crypto.RandomNum(curve.N)
+	childIdx := HardenedKeyStart + 1
+
+	// lambda over 2 shares is not the key of a 3/n sharing
+	shares, sharePubKeyMap := hardenedShares(t, x, 3, 3)
+	_, err := NewHardenedP1("hardened", shares[1], sharePubKeyMap, 1, 3, childIdx)
+	require.Error(t, err)
+	_, err = NewHardenedP2("hardened", shares[3], sharePubKeyMap, 1, 3, childIdx)
+	require.Error(t, err)
+
+	shares, sharePubKeyMap = hardenedShares(t, x, 2, 3)
+	// share publicKey of another share
+	_, err = NewHardenedP1("hardened", shares[2], sharePubKeyMap, 1, 3, childIdx)
+	require.Error(t, err)
+	_, err = NewHardenedP1("hardened", shares[1], map[int]*curves.ECPoint{1: sharePubKeyMap[1]}, 1, 3, childIdx)
+	require.Error(t, err)
+	// label derivation of NewTssKey is not bip32
+	labelKey, err := NewTssKey(shares[1].ShareI(), shares[1].PublicKey(), vector1ChainCode)
+	require.NoError(t, err)
+	_, err = NewHardenedP1("hardened", labelKey, sharePubKeyMap, 1, 3, childIdx)
+	require.Error(t, err)
+	_, err = labelKey.HardenedChildKey(childIdx, make([]byte, 64))
+	require.Error(t, err)
+
+	// share publicKeys of a child are those of dkg plus the offset
+	child1, err := shares[1].NewChildKey(5)
+	require.NoError(t, err)
+	child3, err := shares[3].NewChildKey(5)
+	require.NoError(t, err)
+	_, err = NewHardenedP1("hardened", child1, sharePubKeyMap, 1, 3, childIdx)
+	require.NoError(t, err)
+	_, err = NewHardenedP2("hardened", child3, sharePubKeyMap, 1, 3, childIdx)
+	require.NoError(t, err)
+}
+
+func TestHardenedCheating(t *testing.T) {
+	curve := secp256k1.S256()
+	x := crypto.RandomNum(curve.N)
+	shares, sharePubKeyMap := hardenedShares(t, x, 2, 3)
+	childIdx := HardenedKeyStart + 7
+
+	// run P1 and P2 until P2 evaluates, tamper changes the step3 data of the garbler
+	run := func(tamper func(data *HardenedStep3Data)) (*HardenedP1Context, *tss.Message, error) {
+		p1, p2 := newHardenedPair(t, shares[1], shares[3], sharePubKeyMap, childIdx)
+		msg1, err := p1.Step1()
+		require.NoError(t, err)
+		msg2, err := p2.Step1(msg1)
+		require.NoError(t, err)
+		msg3, err := p1.Step2(msg2)
+		require.NoError(t, err)
+		var data HardenedStep3Data
+		require.NoError(t, tss.UnmarshalData([]byte(msg3.Data), &data))
+		tamper(&data)
+		msg3, err = hardenedMessage(&p1.envelope, 1, 3, 3, data)
+		require.NoError(t, err)
+		_, msg4, err := p2.Step2(msg3)
+		return p1, msg4, err
+	}
+
+	// honest run
+	p1, msg4, err := run(func(data *HardenedStep3Data) {})
+	require.NoError(t, err)
+	// P2 returns another intermediary
+	var out HardenedStep4Data
+	require.NoError(t, tss.UnmarshalData([]byte(msg4.Data), &out))
+	out.Intermediary[0] ^= 1
+	msg4, err = hardenedMessage(&p1.envelope, 3, 1, 4, out)
+	require.NoError(t, err)
+	_, err = p1.Step3(msg4)
+	require.EqualError(t, err, "intermediary mac error")
+
+	// evaluated circuit differs from its commitment
+	_, _, err = run(func(data *HardenedStep3Data) { data.Evaluated[0].Tables[0] ^= 1 })
+	require.Error(t, err)
+	// opened seed differs from the commitment
+	_, _, err = run(func(data *HardenedStep3Data) { data.Opened[0].Seed[0] ^= 1 })
+	require.Error(t, err)
+	// garbler label that is neither label of the wire
+	_, _, err = run(func(data *HardenedStep3Data) { data.Evaluated[1].GarblerLabels[3] ^= 1 })
+	require.Error(t, err)
+	// wrong keys transferred by OT are caught by the opened circuits
+	_, _, err = run(func(data *HardenedStep3Data) {
+		data.E[5][0][0] ^= 1
+		data.E[5][1][0] ^= 1
+	})
+	require.Error(t, err)
+	// P2 opens the circuits it chose
+	_, _, err = run(func(data *HardenedStep3Data) {
+		data.Opened[0], data.Evaluated[0] = &HardenedOpening{Index: data.Evaluated[0].Index}, &HardenedGarbling{Index: data.Opened[0].Index}
+	})
+	require.Error(t, err)
+
+	// P1 garbles with another x1
+	p1, p2 := newHardenedPair(t, shares[1], shares[3], sharePubKeyMap, childIdx)
+	p1.x1 = new(big.Int).Add(p1.x1, big.NewInt(1))
+	msg1, err := p1.Step1()
+	require.NoError(t, err)
+	msg2, err := p2.Step1(msg1)
+	require.NoError(t, err)
+	msg3, err := p1.Step2(msg2)
+	require.NoError(t, err)
+	_, _, err = p2.Step2(msg3)
+	require.EqualError(t, err, "binding of the P1 input error")
+
+	// P2 evaluates with another x2, the intermediary has a valid mac
+	p1, p2 = newHardenedPair(t, shares[1], shares[3], sharePubKeyMap, childIdx)
+	p2.x2 = new(big.Int).Add(p2.x2, big.NewInt(1))
+	msg1, err = p1.Step1()
+	require.NoError(t, err)
+	msg2, err = p2.Step1(msg1)
+	require.NoError(t, err)
+	msg3, err = p1.Step2(msg2)
+	require.NoError(t, err)
+	_, msg4, err = p2.Step2(msg3)
+	require.NoError(t, err)
+	_, err = p1.Step3(msg4)
+	require.EqualError(t, err, "binding of the P2 input error")
+}
+
+// hardenedShares threshold/total shares of x with bip32 derivation and their share publicKeys
+func hardenedShares(t *testing.T, x *big.Int, threshold, total int) (map[int]*TssKey, map[int]*curves.ECPoint) {
+	curve := secp256k1.S256()
+	X := curves.ScalarToPoint(curve, x)
+	coefficients := make([]*big.Int, threshold-1)
+	for i := range coefficients {
+		coefficients[i] = crypto.RandomNum(curve.N)
+	}
+	shares := make(map[int]*TssKey, total)
+	sharePubKeyMap := make(map[int]*curves.ECPoint, total)
+	for i := 1; i <= total; i++ {
+		// f(i) = x + a1*i + a2*i^2 ...
+		share, power := new(big.Int).Set(x), big.NewInt(1)
+		for _, a := range coefficients {
+			power.Mul(power, big.NewInt(int64(i)))
+			share.Add(share, new(big.Int).Mul(a, power))
+		}
+		share.Mod(share, curve.N)
+		key, err := NewTssKeyBip32(share, X, vector1ChainCode)
+		require.NoError(t, err)
+		shares[i] = key
+		sharePubKeyMap[i] = curves.ScalarToPoint(curve, share)
+	}
+	return shares, sharePubKeyMap
+}
+
+// newHardenedPair P1 and P2 with a small cut-and-choose, the default one garbles 125 circuits
+func newHardenedPair(t *testing.T, share1, share3 *TssKey, sharePubKeyMap map[int]*curves.ECPoint, childIdx uint32) (*HardenedP1Context, *HardenedP2Context) {
+	p1, err := NewHardenedP1("hardened", share1, sharePubKeyMap, 1, 3, childIdx)
+	require.NoError(t, err)
+	require.NoError(t, p1.SetCutAndChoose(5, 3))
+	p2, err := NewHardenedP2("hardened", share3, sharePubKeyMap, 1, 3, childIdx)
+	require.NoError(t, err)
+	require.NoError(t, p2.SetCutAndChoose(5, 3))
+	return p1, p2
+}
+
+// runHardened return the intermediary both agree on
+func runHardened(t *testing.T, p1 *HardenedP1Context, p2 *HardenedP2Context) []byte {
+	msg1, err := p1.Step1()
+	require.NoError(t, err)
+	msg2, err := p2.Step1(msg1)
+	require.NoError(t, err)
+	msg3, err := p1.Step2(msg2)
+	require.NoError(t, err)
+	child3, msg4, err := p2.Step2(msg3)
+	require.NoError(t, err)
+	child1, err := p1.Step3(msg4)
+	require.NoError(t, err)
+	require.True(t, child1.PublicKey().Equals(child3.PublicKey()))
+	var out HardenedStep4Data
+	require.NoError(t, tss.UnmarshalData([]byte(msg4.Data), &out))
+	return out.Intermediary
+}
+
+func deriveHardened(t *testing.T, share1, share3 *TssKey, sharePubKeyMap map[int]*curves.ECPoint, childIdx uint32) (*TssKey, *TssKey) {
+	I := mustIntermediary(t, share1, share3, sharePubKeyMap, childIdx)
+	child1, err := share1.HardenedChildKey(childIdx, I)
+	require.NoError(t, err)
+	child3, err := share3.HardenedChildKey(childIdx, I)
+	require.NoError(t, err)
+	return child1, child3
+}
+
+func mustIntermediary(t *testing.T, share1, share3 *TssKey, sharePubKeyMap map[int]*curves.ECPoint, childIdx uint32) []byte {
+	p1, p2 := newHardenedPair(t, share1, share3, sharePubKeyMap, childIdx)
+	return runHardened(t, p1, p2)
+}
@@ -2,6 +2,7 @@ package bip32
 
 import (
 	"bytes"
+	"crypto/elliptic"
 	"crypto/hmac"
 	"crypto/sha512"
 	"encoding/binary"
@@ -9,18 +10,25 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 	"github.com/okx/threshold-lib/crypto/curves"
 )
 
 var label = []byte("Key share derivation:\n")
 
-// support secp256k1 derived, not support ed25519
+// support secp256k1 derived, ed25519 derived with NewTssKeyEd25519, child shares sign with Ed25519Sign.
+// NewTssKey children are keyed by label and not compatible with wallets, NewTssKeyBip32 derives standard bip32 children
 type TssKey struct {
 	shareI       *big.Int        // key share
 	publicKey    *curves.ECPoint // publicKey
 	chaincode    []byte
 	offsetSonPri *big.Int // child private key share offset, accumulative
+	depth        uint8
+	parentFP     [4]byte // hash160(parent publicKey)[:4]
+	childNumber  uint32
+	ed25519      bool // ed25519 derivation, encoded publicKey and IL mod L
+	standard     bool // bip32 CKDpub, HMAC keyed by chaincode over the compressed publicKey
 }
 
 // NewTssKey shareI is optional
@@ -41,24 +49,82 @@ func NewTssKey(shareI *big.Int, publicKey *curves.ECPoint, chaincode string) (*T
 	return tssKey, nil
 }
 
-// NewChildKey like bip32 non-hardened derivation
-func (tssKey *TssKey) NewChildKey(childIdx uint32) (*TssKey, error) {
-	if childIdx >= uint32(0x80000000) { // 2^31
-		return nil, fmt.Errorf("hardened derivation is unsupported")
+// NewTssKeyEd25519 ed25519 derivation, HMAC input is the 32 bytes encoded publicKey and IL is reduced mod L,
+// child keys differ from NewTssKey on the same ed25519 key
+func NewTssKeyEd25519(shareI *big.Int, publicKey *curves.ECPoint, chaincode string) (*TssKey, error) {
+	if publicKey == nil || !isEd25519(publicKey.Curve) {
+		return nil, fmt.Errorf("parameter error")
 	}
-	curve := tssKey.publicKey.Curve
-	intermediary, err := calPrivateOffset(tssKey.publicKey.X.Bytes(), tssKey.chaincode, childIdx)
+	tssKey, err := NewTssKey(shareI, publicKey, chaincode)
+	if err != nil {
+		return nil, err
+	}
+	tssKey.ed25519 = true
+	return tssKey, nil
+}
+
+// NewTssKeyBip32 secp256k1 key with standard bip32 derivation, children and xpub match wallets on the same publicKey and chaincode,
+// child keys differ from NewTssKey on the same key
+func NewTssKeyBip32(shareI *big.Int, publicKey *curves.ECPoint, chaincode string) (*TssKey, error) {
+	if publicKey == nil || publicKey.Curve != secp256k1.S256() {
+		return nil, fmt.Errorf("parameter error")
+	}
+	tssKey, err := NewTssKey(shareI, publicKey, chaincode)
 	if err != nil {
 		return nil, err
 	}
+	// dkg chaincode is hex of a sum of random numbers, it is taken mod 2^256 as 32 bytes
+	if n := len(tssKey.chaincode); n > 32 {
+		tssKey.chaincode = tssKey.chaincode[n-32:]
+	}
+	tssKey.chaincode = append(make([]byte, 32-len(tssKey.chaincode)), tssKey.chaincode...)
+	tssKey.standard = true
+	return tssKey, nil
+}
 
-	// Validate key
-	err = validatePrivateKey(intermediary[:32])
+// NewChildKey like bip32 non-hardened derivation, standard bip32 CKDpub for NewTssKeyBip32 keys
+func (tssKey *TssKey) NewChildKey(childIdx uint32) (*TssKey, error) {
+	if childIdx >= HardenedKeyStart {
+		return nil, fmt.Errorf("hardened derivation is unsupported")
+	}
+	var intermediary []byte
+	var err error
+	if tssKey.standard {
+		intermediary, err = calBip32Offset(tssKey.compressedPublicKey(), tssKey.chaincode, childIdx)
+	} else {
+		intermediary, err = calPrivateOffset(tssKey.publicKeyBytes(), tssKey.chaincode, childIdx)
+	}
 	if err != nil {
 		return nil, err
 	}
+	return tssKey.childKey(childIdx, intermediary)
+}
 
+// childKey child of intermediary I = IL | IR, offset IL, chaincode IR
+func (tssKey *TssKey) childKey(childIdx uint32, intermediary []byte) (*TssKey, error) {
+	if tssKey.depth == 255 {
+		return nil, fmt.Errorf("max depth exceeded")
+	}
+	curve := tssKey.publicKey.Curve
 	offset := new(big.Int).SetBytes(intermediary[:32])
+	if tssKey.ed25519 {
+		// ed25519 order is about 2^252, IL is reduced
+		offset.Mod(offset, curve.Params().N)
+		if offset.Sign() == 0 {
+			return nil, fmt.Errorf("Invalid private key")
+		}
+	} else {
+		// Validate key, ed25519 keys of NewTssKey keep the secp256k1 bound of earlier versions
+		N := curve.Params().N
+		if isEd25519(curve) {
+			N = secp256k1.S256().N
+		}
+		err := validatePrivateKey(intermediary[:32], N)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	point := curves.ScalarToPoint(curve, offset)
 	ecPoint, err := tssKey.publicKey.Add(point)
 	if err != nil {
@@ -76,7 +142,12 @@ func (tssKey *TssKey) NewChildKey(childIdx uint32) (*TssKey, error) {
 		publicKey:    ecPoint,
 		chaincode:    intermediary[32:],
 		offsetSonPri: offsetSonPri,
+		depth:        tssKey.depth + 1,
+		childNumber:  childIdx,
+		ed25519:      tssKey.ed25519,
+		standard:     tssKey.standard,
 	}
+	copy(tss.parentFP[:], tssKey.Fingerprint())
 	return tss, nil
 }
 
@@ -95,6 +166,11 @@ func (tssKey *TssKey) PublicKey() *curves.ECPoint {
 	return tssKey.publicKey
 }
 
+// Bip32 standard bip32 derivation, keys of NewTssKeyBip32 and NewTssKeyFromXpub
+func (tssKey *TssKey) Bip32() bool {
+	return tssKey.standard
+}
+
 // calPrivateOffset HMAC-SHA512(label | chaincode | publicKey | childIdx)
 func calPrivateOffset(publicKey, chaincode []byte, childIdx uint32) ([]byte, error) {
 	hash := hmac.New(sha512.New, label)
@@ -109,9 +185,39 @@ func calPrivateOffset(publicKey, chaincode []byte, childIdx uint32) ([]byte, err
 	return hash.Sum(nil), nil
 }
 
-func validatePrivateKey(key []byte) error {
+// calBip32Offset HMAC-SHA512(chaincode, serP(publicKey) | childIdx)
+func calBip32Offset(publicKey, chaincode []byte, childIdx uint32) ([]byte, error) {
+	hash := hmac.New(sha512.New, chaincode)
+	_, err := hash.Write(append(append([]byte{}, publicKey...), uint32Bytes(childIdx)...))
+	if err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// compressedPublicKey SEC1 compressed publicKey, ed25519 uses the 32 bytes encoded publicKey
+func (tssKey *TssKey) compressedPublicKey() []byte {
+	if isEd25519(tssKey.publicKey.Curve) {
+		return edwards.NewPublicKey(tssKey.publicKey.X, tssKey.publicKey.Y).Serialize()
+	}
+	return elliptic.MarshalCompressed(tssKey.publicKey.Curve, tssKey.publicKey.X, tssKey.publicKey.Y)
+}
+
+// publicKeyBytes x coordinate, ed25519 derivation uses the 32 bytes encoded publicKey
+func (tssKey *TssKey) publicKeyBytes() []byte {
+	if tssKey.ed25519 {
+		return edwards.NewPublicKey(tssKey.publicKey.X, tssKey.publicKey.Y).Serialize()
+	}
+	return tssKey.publicKey.X.Bytes()
+}
+
+func isEd25519(curve elliptic.Curve) bool {
+	return curves.GetCurveName(curve) == curves.Ed25519
+}
+
+func validatePrivateKey(key []byte, N *big.Int) error {
 	if fmt.Sprintf("%x", key) == "0000000000000000000000000000000000000000000000000000000000000000" || //if the key is zero
-		bytes.Compare(key, secp256k1.S256().N.Bytes()) >= 0 || //or is outside of the curve
+		bytes.Compare(key, N.FillBytes(make([]byte, 32))) >= 0 || //or is outside of the curve
 		len(key) != 32 { //or is too short
 		return fmt.Errorf("Invalid private key")
 	}
@@ -1,14 +1,18 @@
 package bip32
 
 import (
+	"crypto/elliptic"
 	"encoding/hex"
 	"fmt"
 	"math/big"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
 	"github.com/okx/threshold-lib/crypto"
 	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/ripemd160"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTssKey(t *testing.T) {
@@ -45,3 +49,153 @@ func TestTssKey_cmp(t *testing.T) {
 	fmt.Println(tssKey.publicKey)
 	fmt.Println(X_new)
 }
+
+func TestDerivePathXpub(t *testing.T) {
+	curve := secp256k1.S256()
+	// bip32 test vector 2, master key
+	x, _ := new(big.Int).SetString("4b03d6fc340455b363f51020ad3ecca4f0850280cf436c70c727923f6db46c3e", 16)
+	X := curves.ScalarToPoint(curve, x)
+	tssKey, err := NewTssKeyBip32(x, X, "60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	require.NoError(t, err)
+	xpub, err := tssKey.Xpub()
+	require.NoError(t, err)
+	require.Equal(t, "xpub661MyMwAqRbcFW31YEwpkMuc5THy2PSt5bDMsktWQcFF8syAmRUapSCGu8ED9W6oDMSgv6Zz8idoc4a6mr8BDzTJY47LJhkJ8UB7WEGuduB", xpub)
+	require.Equal(t, "bd16bee5", hex.EncodeToString(tssKey.Fingerprint()))
+
+	keys, err := tssKey.DerivePath("m/0/1/5")
+	require.NoError(t, err)
+	require.Equal(t, 3, len(keys))
+	// m/0
+	xpub, err = keys[0].Xpub()
+	require.NoError(t, err)
+	require.Equal(t, "xpub69H7F5d8KSRgmmdJg2KhpAK8SR3DjMwAdkxj3ZuxV27CprR9LgpeyGmXUbC6wb7ERfvrnKZjXoUmmDznezpbZb7ap6r1D3tgFxHmwMkQTPH", xpub)
+	require.True(t, curves.ScalarToPoint(curve, keys[0].ShareI()).Equals(keys[0].PublicKey()))
+	child, _ := tssKey.NewChildKey(0)
+	child, _ = child.NewChildKey(1)
+	require.True(t, child.PublicKey().Equals(keys[1].PublicKey()))
+	require.Equal(t, uint8(3), keys[2].Depth())
+	require.Equal(t, uint32(5), keys[2].ChildNumber())
+
+	// watch-only key follows the mpc key
+	xpub, err = keys[1].Xpub()
+	require.NoError(t, err)
+	watch, err := NewTssKeyFromXpub(xpub)
+	require.NoError(t, err)
+	require.Nil(t, watch.ShareI())
+	require.Equal(t, uint8(2), watch.Depth())
+	watchChild, err := watch.NewChildKey(5)
+	require.NoError(t, err)
+	require.True(t, watchChild.PublicKey().Equals(keys[2].PublicKey()))
+	childXpub, _ := keys[2].Xpub()
+	watchXpub, _ := watchChild.Xpub()
+	require.Equal(t, childXpub, watchXpub)
+
+	// bip32 test vector 1, public derivation of m/0H/1/2H/2/1000000000
+	watch, err = NewTssKeyFromXpub("xpub6D4BDPcP2GT577Vvch3R8wDkScZWzQzMMUm3PWbmWvVJrZwQY4VUNgqFJPMM3No2dFDFGTsxxpG5uJh7n7epu4trkrX7x7DogT5Uv6fcLW5")
+	require.NoError(t, err)
+	keys, err = watch.DerivePath("m/2/1000000000")
+	require.NoError(t, err)
+	xpub, _ = keys[0].Xpub()
+	require.Equal(t, "xpub6FHa3pjLCk84BayeJxFW2SP4XRrFd1JYnxeLeU8EqN3vDfZmbqBqaGJAyiLjTAwm6ZLRQUMv1ZACTj37sR62cfN7fe5JnJ7dh8zL4fiyLHV", xpub)
+	xpub, _ = keys[1].Xpub()
+	require.Equal(t, "xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy", xpub)
+
+	// label derivation of NewTssKey is not bip32, no xpub
+	labelKey, err := NewTssKey(x, X, "60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	require.NoError(t, err)
+	_, err = labelKey.Xpub()
+	require.Error(t, err)
+	labelChild, err := labelKey.NewChildKey(0)
+	require.NoError(t, err)
+	require.False(t, labelChild.PublicKey().Equals(child.PublicKey()))
+	_, err = NewTssKeyBip32(x, curves.ScalarToPoint(elliptic.P256(), x), "60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	require.Error(t, err)
+	// a dkg chaincode is taken mod 2^256 and left padded
+	long, err := NewTssKeyBip32(x, X, "01"+"60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	require.NoError(t, err)
+	longXpub, _ := long.Xpub()
+	masterXpub, _ := tssKey.Xpub()
+	require.Equal(t, masterXpub, longXpub)
+	short, err := NewTssKeyBip32(x, X, "1b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	require.NoError(t, err)
+	padded, err := NewTssKeyBip32(x, X, "000000001b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689")
+	require.NoError(t, err)
+	shortXpub, _ := short.Xpub()
+	paddedXpub, _ := padded.Xpub()
+	require.Equal(t, paddedXpub, shortXpub)
+
+	_, err = tssKey.DerivePath("m/0'/1")
+	require.Error(t, err)
+	_, err = tssKey.DerivePath("0/1")
+	require.Error(t, err)
+	_, err = NewTssKeyFromXpub(xpub[:len(xpub)-1] + "1")
+	require.Error(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	x := big.NewInt(7)
+	chaincode := hex.EncodeToString([]byte("chaincode"))
+	for _, curve := range []elliptic.Curve{secp256k1.S256(), elliptic.P256(), edwards.Edwards()} {
+		X := curves.ScalarToPoint(curve, x)
+		tssKey, err := NewTssKey(x, X, chaincode)
+		require.NoError(t, err)
+		child, err := tssKey.NewChildKey(1)
+		require.NoError(t, err)
+		require.Equal(t, tssKey.Fingerprint(), child.parentFP[:])
+	}
+	// ed25519 fingerprint is over the encoded publicKey
+	X := curves.ScalarToPoint(edwards.Edwards(), x)
+	tssKey, _ := NewTssKey(x, X, chaincode)
+	require.Equal(t, ripemd160.Hash160(edwards.NewPublicKey(X.X, X.Y).Serialize())[:4], tssKey.Fingerprint())
+	X = curves.ScalarToPoint(elliptic.P256(), x)
+	tssKey, _ = NewTssKey(x, X, chaincode)
+	require.Equal(t, ripemd160.Hash160(elliptic.MarshalCompressed(elliptic.P256(), X.X, X.Y))[:4], tssKey.Fingerprint())
+}
+
+func TestTssKeyEd25519(t *testing.T) {
+	curve := edwards.Edwards()
+	x := crypto.RandomNum(curve.N)
+	X := curves.ScalarToPoint(curve, x)
+	chaincode := hex.EncodeToString([]byte("chaincode"))
+
+	// NewTssKey keeps the x coordinate derivation of existing keys
+	tssKey, err := NewTssKey(x, X, chaincode)
+	require.NoError(t, err)
+	child, err := tssKey.NewChildKey(7)
+	require.NoError(t, err)
+	intermediary, err := calPrivateOffset(X.X.Bytes(), []byte("chaincode"), 7)
+	require.NoError(t, err)
+	offset := new(big.Int).Mod(new(big.Int).SetBytes(intermediary[:32]), curve.N)
+	require.Equal(t, 0, offset.Cmp(child.PrivateKeyOffset()))
+	require.True(t, curves.ScalarToPoint(curve, child.ShareI()).Equals(child.PublicKey()))
+
+	// NewTssKeyEd25519 derives from the encoded publicKey
+	tssKey, err = NewTssKeyEd25519(x, X, chaincode)
+	require.NoError(t, err)
+	edChild, err := tssKey.NewChildKey(7)
+	require.NoError(t, err)
+	intermediary, err = calPrivateOffset(edwards.NewPublicKey(X.X, X.Y).Serialize(), []byte("chaincode"), 7)
+	require.NoError(t, err)
+	offset = new(big.Int).Mod(new(big.Int).SetBytes(intermediary[:32]), curve.N)
+	require.Equal(t, 0, offset.Cmp(edChild.PrivateKeyOffset()))
+	require.True(t, curves.ScalarToPoint(curve, edChild.ShareI()).Equals(edChild.PublicKey()))
+	require.False(t, edChild.PublicKey().Equals(child.PublicKey()))
+	grandChild, err := edChild.NewChildKey(1)
+	require.NoError(t, err)
+	require.True(t, curves.ScalarToPoint(curve, grandChild.ShareI()).Equals(grandChild.PublicKey()))
+
+	// secp256k1 keys are not ed25519 keys
+	secpX := curves.ScalarToPoint(secp256k1.S256(), x)
+	_, err = NewTssKeyEd25519(x, secpX, chaincode)
+	require.Error(t, err)
+}
+
+func TestValidatePrivateKey(t *testing.T) {
+	// IL between the P-256 and secp256k1 orders is invalid on P-256 only
+	N := elliptic.P256().Params().N
+	key := new(big.Int).Add(N, big.NewInt(1)).FillBytes(make([]byte, 32))
+	require.Error(t, validatePrivateKey(key, N))
+	require.NoError(t, validatePrivateKey(key, secp256k1.S256().N))
+	require.NoError(t, validatePrivateKey(new(big.Int).Sub(N, big.NewInt(1)).FillBytes(make([]byte, 32)), N))
+	require.Error(t, validatePrivateKey(make([]byte, 32), N))
+}
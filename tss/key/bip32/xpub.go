@@ -0,0 +1,107 @@
+package bip32
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/base58"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/ripemd160"
+)
+
+// XpubVersion mainnet extended public key version
+const XpubVersion = uint32(0x0488B21E)
+
+// xpub layout: version(4) | depth(1) | parent fingerprint(4) | child number(4) | chaincode(32) | publicKey(33)
+const xpubSize = 78
+
+// DerivePath path like "m/0/1/5" relative to tssKey, return the key of each level, unhardened only,
+// levels follow NewChildKey, standard bip32 for NewTssKeyBip32 keys
+func (tssKey *TssKey) DerivePath(path string) ([]*TssKey, error) {
+	elements := strings.Split(path, "/")
+	if elements[0] != "m" {
+		return nil, fmt.Errorf("path must start with m")
+	}
+	keys := make([]*TssKey, 0, len(elements)-1)
+	key := tssKey
+	for _, element := range elements[1:] {
+		if strings.HasSuffix(element, "'") || strings.HasSuffix(element, "h") {
+			return nil, fmt.Errorf("hardened derivation is unsupported, use NewHardenedP1")
+		}
+		idx, err := strconv.ParseUint(element, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path element %q", element)
+		}
+		key, err = key.NewChildKey(uint32(idx))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Fingerprint hash160(compressed publicKey)[:4], the encoded publicKey for ed25519
+func (tssKey *TssKey) Fingerprint() []byte {
+	return ripemd160.Hash160(tssKey.compressedPublicKey())[:4]
+}
+
+// Depth 0 for the root key
+func (tssKey *TssKey) Depth() uint8 {
+	return tssKey.depth
+}
+
+// ChildNumber index of this key in its parent
+func (tssKey *TssKey) ChildNumber() uint32 {
+	return tssKey.childNumber
+}
+
+// Xpub base58check extended public key of a NewTssKeyBip32 key, wallets importing it derive the same children
+func (tssKey *TssKey) Xpub() (string, error) {
+	if !tssKey.standard {
+		return "", fmt.Errorf("xpub requires bip32 derivation, use NewTssKeyBip32")
+	}
+	data := make([]byte, 0, xpubSize)
+	data = append(data, uint32Bytes(XpubVersion)...)
+	data = append(data, tssKey.depth)
+	data = append(data, tssKey.parentFP[:]...)
+	data = append(data, uint32Bytes(tssKey.childNumber)...)
+	data = append(data, tssKey.chaincode...)
+	data = append(data, tssKey.compressedPublicKey()...)
+	return base58.CheckEncode(data), nil
+}
+
+// NewTssKeyFromXpub watch-only TssKey with bip32 derivation, shareI is nil
+func NewTssKeyFromXpub(xpub string) (*TssKey, error) {
+	data, err := base58.CheckDecode(xpub)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != xpubSize {
+		return nil, fmt.Errorf("invalid xpub length")
+	}
+	if binary.BigEndian.Uint32(data[:4]) != XpubVersion {
+		return nil, fmt.Errorf("invalid xpub version")
+	}
+	publicKey, err := secp256k1.ParsePubKey(data[45:])
+	if err != nil {
+		return nil, err
+	}
+	tssKey := &TssKey{
+		publicKey:    &curves.ECPoint{Curve: secp256k1.S256(), X: publicKey.X, Y: publicKey.Y},
+		chaincode:    append([]byte{}, data[13:45]...),
+		offsetSonPri: big.NewInt(0),
+		depth:        data[4],
+		childNumber:  binary.BigEndian.Uint32(data[9:13]),
+		standard:     true,
+	}
+	copy(tssKey.parentFP[:], data[5:9])
+	if tssKey.depth == 0 && (tssKey.parentFP != [4]byte{} || tssKey.childNumber != 0) {
+		return nil, fmt.Errorf("invalid xpub root")
+	}
+	return tssKey, nil
+}
@@ -0,0 +1,153 @@
+package dkg
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// Optional complaint round after DKGStep3 fails with an invalid share:
+// accuser broadcast complaint, accused reveal the share publicly, every participant judges the culprit.
+// The revealed share is public, dkg must be restarted without the culprit.
+
+const BlameNoReveal = "no share revealed"
+
+// rounds of complaint and reveal messages, after DKGStep3
+const (
+	complaintRound = 4
+	revealRound    = 5
+)
+
+// ComplaintData accuser broadcast complaint with the offending message
+type ComplaintData struct {
+	Accused  int
+	Reason   string
+	Evidence *tss.Message
+}
+
+// RevealData accused reveal the share sent to accuser
+type RevealData struct {
+	Accuser int
+	Share   *vss.Share
+}
+
+// DKGComplain broadcast complaint when DKGStep3 return BlameError for an invalid share
+func (info *SetupInfo) DKGComplain(blame *tss.BlameError) (map[int]*tss.Message, error) {
+	if info.RoundNumber != 3 {
+		return nil, fmt.Errorf("round error")
+	}
+	if blame == nil || blame.Reason != tss.BlameShare || blame.Culprit == info.DeviceNumber {
+		return nil, fmt.Errorf("complaint only for invalid share")
+	}
+	if _, ok := info.verifierMap[blame.Culprit]; !ok {
+		return nil, fmt.Errorf("verifiers of participant %d unknown", blame.Culprit)
+	}
+	content := ComplaintData{
+		Accused:  blame.Culprit,
+		Reason:   blame.Reason,
+		Evidence: blame.Evidence,
+	}
+	bytes, err := info.envelope.MarshalData(content)
+	if err != nil {
+		return nil, err
+	}
+	// broadcast, including the accused
+	out := make(map[int]*tss.Message, info.Total-1)
+	for _, id := range info.Ids() {
+		if id == info.DeviceNumber {
+			continue
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, complaintRound, bytes)
+	}
+	return out, nil
+}
+
+// DKGRevealShare accused answer the complaint, broadcast the share sent to accuser
+func (info *SetupInfo) DKGRevealShare(complaint *tss.Message) (map[int]*tss.Message, error) {
+	if info.RoundNumber < 2 || info.secretShares == nil {
+		return nil, fmt.Errorf("round error")
+	}
+	content, err := info.parseComplaint(complaint)
+	if err != nil {
+		return nil, err
+	}
+	if content.Accused != info.DeviceNumber || complaint.To != info.DeviceNumber {
+		return nil, fmt.Errorf("complaint is not for participant %d", info.DeviceNumber)
+	}
+	if complaint.From < 1 || complaint.From > info.Total {
+		return nil, fmt.Errorf("invalid accuser %d", complaint.From)
+	}
+	reveal := RevealData{
+		Accuser: complaint.From,
+		Share:   info.secretShares[complaint.From-1],
+	}
+	bytes, err := info.envelope.MarshalData(reveal)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int]*tss.Message, info.Total-1)
+	for _, id := range info.Ids() {
+		if id == info.DeviceNumber {
+			continue
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, revealRound, bytes)
+	}
+	return out, nil
+}
+
+// DKGJudge check the revealed share against the accused verifiers, reveal is nil if the accused did not answer.
+// Return BlameError of the accused if the share is invalid, otherwise of the accuser
+func (info *SetupInfo) DKGJudge(complaint, reveal *tss.Message) (*tss.BlameError, error) {
+	content, err := info.parseComplaint(complaint)
+	if err != nil {
+		return nil, err
+	}
+	accuser, accused := complaint.From, content.Accused
+	verifiers, ok := info.verifierMap[accused]
+	if !ok {
+		return nil, fmt.Errorf("verifiers of participant %d unknown", accused)
+	}
+	if reveal == nil {
+		return &tss.BlameError{Culprit: accused, Reason: BlameNoReveal, Evidence: complaint}, nil
+	}
+	if reveal.From != accused {
+		return nil, fmt.Errorf("reveal is not from participant %d", accused)
+	}
+	if err := info.envelope.CheckMessage(reveal, revealRound); err != nil {
+		return nil, err
+	}
+	var data RevealData
+	err = tss.UnmarshalData([]byte(reveal.Data), &data)
+	if err != nil || data.Accuser != accuser || data.Share == nil || data.Share.Id == nil || data.Share.Y == nil ||
+		data.Share.Id.Cmp(big.NewInt(int64(accuser))) != 0 {
+		return tss.NewBlameError(tss.BlameMessage, reveal), nil
+	}
+	feldman, err := vss.NewFeldman(info.Threshold, info.Total, info.curve)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := feldman.Verify(data.Share, verifiers); !ok || err != nil {
+		return tss.NewBlameError(tss.BlameShare, reveal), nil
+	}
+	return tss.NewBlameError(tss.BlameComplaint, complaint), nil
+}
+
+func (info *SetupInfo) parseComplaint(complaint *tss.Message) (*ComplaintData, error) {
+	if complaint == nil {
+		return nil, fmt.Errorf("complaint is nil")
+	}
+	if err := info.envelope.CheckMessage(complaint, complaintRound); err != nil {
+		return nil, err
+	}
+	var content ComplaintData
+	err := tss.UnmarshalData([]byte(complaint.Data), &content)
+	if err != nil {
+		return nil, err
+	}
+	if content.Accused == complaint.From {
+		return nil, fmt.Errorf("invalid complaint")
+	}
+	return &content, nil
+}
@@ -8,13 +8,18 @@ import (
 	"github.com/okx/threshold-lib/crypto/commitment"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
 )
 
+// protocol id of the message envelope
+const protocol = "dkg"
+
 type SetupInfo struct {
 	DeviceNumber int // device id， start 1
-	Threshold    int //  2/n, fixed 2
+	Threshold    int // t/n, any t shares recover the key
 	Total        int // number of participants
 	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
 
 	ui        *big.Int
 	shareI    *big.Int // key share
@@ -26,22 +31,54 @@ type SetupInfo struct {
 	secretShares  []*vss.Share
 	deC           *commitment.Witness
 	commitmentMap map[int]commitment.Commitment
+	verifierMap   map[int][]*curves.ECPoint // opened verifiers of all participants, for complaint
 }
 
+// NewSetUp 2/n dkg
+//
+// Deprecated: messages are not bound to a session, use NewSetUpWithSession.
 func NewSetUp(deviceNumber, total int, curve elliptic.Curve) *SetupInfo {
+	return newSetUp("", deviceNumber, 2, total, curve)
+}
+
+// NewSetUpWithThreshold t/n dkg, all participants must use the same threshold
+//
+// Deprecated: messages are not bound to a session, use NewSetUpWithSession.
+func NewSetUpWithThreshold(deviceNumber, threshold, total int, curve elliptic.Curve) *SetupInfo {
+	return newSetUp("", deviceNumber, threshold, total, curve)
+}
+
+// NewSetUpWithSession t/n dkg, sessionId binds the messages to this run, all participants use the same threshold and unique sessionId
+func NewSetUpWithSession(sessionId string, deviceNumber, threshold, total int, curve elliptic.Curve) *SetupInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewSetUp session id error"))
+	}
+	return newSetUp(sessionId, deviceNumber, threshold, total, curve)
+}
+
+func newSetUp(sessionId string, deviceNumber, threshold, total int, curve elliptic.Curve) *SetupInfo {
 	if total < 2 || deviceNumber > total || deviceNumber <= 0 {
 		panic(fmt.Errorf("NewSetUp params error"))
 	}
+	if threshold < 2 || threshold > total {
+		panic(fmt.Errorf("NewSetUp threshold error"))
+	}
 	info := &SetupInfo{
 		DeviceNumber: deviceNumber,
-		Threshold:    2,
+		Threshold:    threshold,
 		Total:        total,
 		RoundNumber:  1,
+		envelope:     tss.Envelope{Protocol: protocol, SessionId: sessionId},
 		curve:        curve,
 	}
 	return info
 }
 
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *SetupInfo) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
 func (info *SetupInfo) Ids() []int {
 	var ids []int
 	for i := 1; i <= info.Total; i++ {
@@ -49,3 +86,15 @@ func (info *SetupInfo) Ids() []int {
 	}
 	return ids
 }
+
+// checkSender sender must be another participant, a second message from the same sender is blamed
+func (info *SetupInfo) checkSender(msg *tss.Message, received map[int]bool) error {
+	if msg.From < 1 || msg.From > info.Total || msg.From == info.DeviceNumber {
+		return fmt.Errorf("unknown participant %d", msg.From)
+	}
+	if received[msg.From] {
+		return tss.NewBlameError(tss.BlameDuplicate, msg)
+	}
+	received[msg.From] = true
+	return nil
+}
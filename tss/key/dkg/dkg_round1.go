@@ -1,7 +1,6 @@
 package dkg
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -53,15 +52,11 @@ func (info *SetupInfo) DKGStep1() (map[int]*tss.Message, error) {
 		// each message send p2p, not broadcast
 		// step1: verifiers commitment
 		content := tss.KeyStep1Data{C: &hashCommitment.C}
-		bytes, err := json.Marshal(content)
+		bytes, err := info.envelope.MarshalData(content)
 		if err != nil {
 			return nil, err
 		}
-		message := &tss.Message{
-			From: info.DeviceNumber,
-			To:   id,
-			Data: string(bytes),
-		}
+		message := info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
 		out[id] = message
 	}
 	return out, nil
@@ -1,7 +1,6 @@
 package dkg
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/okx/threshold-lib/crypto/commitment"
 	"github.com/okx/threshold-lib/crypto/curves"
@@ -18,15 +17,22 @@ func (info *SetupInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, erro
 		return nil, fmt.Errorf("messages number error")
 	}
 	info.commitmentMap = make(map[int]commitment.Commitment, len(msgs))
+	received := make(map[int]bool, len(msgs))
 	for _, msg := range msgs {
 		if msg.To != info.DeviceNumber {
 			return nil, fmt.Errorf("message sending error")
 		}
-		var content tss.KeyStep1Data
-		err := json.Unmarshal([]byte(msg.Data), &content)
-		if err != nil {
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
+		if err := info.checkSender(msg, received); err != nil {
 			return nil, err
 		}
+		var content tss.KeyStep1Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil || content.C == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
 		info.commitmentMap[msg.From] = *content.C
 	}
 
@@ -49,15 +55,11 @@ func (info *SetupInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, erro
 			Share:   info.secretShares[id-1],
 			Proof:   proof,
 		}
-		bytes, err := json.Marshal(content)
+		bytes, err := info.envelope.MarshalData(content)
 		if err != nil {
 			return nil, err
 		}
-		message := &tss.Message{
-			From: info.DeviceNumber,
-			To:   id,
-			Data: string(bytes),
-		}
+		message := info.envelope.NewMessage(info.DeviceNumber, id, 2, bytes)
 		out[id] = message
 	}
 	return out, nil
@@ -3,7 +3,6 @@ package dkg
 import (
 	"crypto/elliptic"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -32,51 +31,68 @@ func (info *SetupInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error)
 
 	verifiers := make(map[int][]*curves.ECPoint, len(msgs))
 	verifiers[info.DeviceNumber] = info.verifiers
+	info.verifierMap = verifiers
 	chaincode := info.chaincode
-	xi := info.secretShares[info.DeviceNumber-1]
+	xi := new(big.Int).Set(info.secretShares[info.DeviceNumber-1].Y)
+	contents := make(map[int]*tss.KeyStep2Data, len(msgs))
+	// open all commitments first, verifiers are kept for complaint
+	received := make(map[int]bool, len(msgs))
 	for _, msg := range msgs {
 		if msg.To != info.DeviceNumber {
 			return nil, fmt.Errorf("message sending error")
 		}
-		var data tss.KeyStep2Data
-		err := json.Unmarshal([]byte(msg.Data), &data)
-		if err != nil {
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
 			return nil, err
 		}
+		if err := info.checkSender(msg, received); err != nil {
+			return nil, err
+		}
+		var data tss.KeyStep2Data
+		err := tss.UnmarshalData([]byte(msg.Data), &data)
+		if err != nil || data.Witness == nil || data.Share == nil || data.Share.Id == nil || data.Share.Y == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		commit, ok := info.commitmentMap[msg.From]
+		if !ok {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
 		// check verifiers commitment
 		hashCommit := commitment.HashCommitment{}
-		hashCommit.C = info.commitmentMap[msg.From]
+		hashCommit.C = commit
 		hashCommit.Msg = *data.Witness
 		ok, D := hashCommit.Open()
 		if !ok {
-			return nil, fmt.Errorf("commitment DeCommit fail")
+			return nil, tss.NewBlameError(tss.BlameCommitment, msg)
 		}
-		//  actual chaincode = sum(chaincode)
-		chaincode = new(big.Int).Add(chaincode, D[0])
 		verifiers[msg.From], err = UnmarshalVerifiers(curve, D[1:], info.Threshold)
 		if err != nil {
-			return nil, err
+			return nil, tss.NewBlameError(tss.BlameCommitment, msg)
 		}
+		//  actual chaincode = sum(chaincode)
+		chaincode = new(big.Int).Add(chaincode, D[0])
+		contents[msg.From] = &data
+	}
 
+	for _, msg := range msgs {
+		data := contents[msg.From]
 		// feldman verify
-		if ok, err := feldman.Verify(data.Share, verifiers[msg.From]); !ok {
-			if err != nil {
-				return nil, err
-			} else {
-				return nil, fmt.Errorf("invalid share for participant  ")
-			}
+		if data.Share.Id.Cmp(big.NewInt(int64(info.DeviceNumber))) != 0 {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
 		}
-		xi.Y = new(big.Int).Add(xi.Y, data.Share.Y)
+		if ok, err := feldman.Verify(data.Share, verifiers[msg.From]); !ok || err != nil {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
+		}
+		xi = new(big.Int).Add(xi, data.Share.Y)
 
 		ujPoint := verifiers[msg.From][0]
 		point, err := curves.NewECPoint(curve, ujPoint.X, ujPoint.Y)
 		if err != nil {
-			return nil, err
+			return nil, tss.NewBlameError(tss.BlameCommitment, msg)
 		}
 		// schnorr verify for ui
 		verify := schnorr.Verify(data.Proof, point)
 		if !verify {
-			return nil, fmt.Errorf("schnorr verify fail")
+			return nil, tss.NewBlameError(tss.BlameProof, msg)
 		}
 	}
 
@@ -92,7 +108,7 @@ func (info *SetupInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error)
 		}
 	}
 
-	sharePubKeyMap := make(map[int]*curves.ECPoint, info.Threshold)
+	sharePubKeyMap := make(map[int]*curves.ECPoint, info.Total)
 	for k := 1; k <= info.Total; k++ {
 		Yi := v[0]
 		tmp := big.NewInt(1)
@@ -108,11 +124,12 @@ func (info *SetupInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error)
 		sharePubKeyMap[k] = Yi
 	}
 	// check share publicKey
-	xiG := curves.ScalarToPoint(curve, xi.Y)
+	xi = new(big.Int).Mod(xi, curve.Params().N)
+	xiG := curves.ScalarToPoint(curve, xi)
 	if !sharePubKeyMap[info.DeviceNumber].Equals(xiG) {
 		return nil, fmt.Errorf("public key calculation error")
 	}
-	info.shareI = xi.Y
+	info.shareI = xi
 	info.publicKey = v[0]
 
 	content := &tss.KeyStep3Data{
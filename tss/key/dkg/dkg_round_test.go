@@ -1,11 +1,18 @@
 package dkg
 
 import (
+	"crypto/elliptic"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"testing"
 
+	"github.com/decred/dcrd/dcrec/edwards/v2"
 	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
 	"github.com/okx/threshold-lib/tss"
+	"github.com/stretchr/testify/require"
 )
 
 func TestKeyGen(t *testing.T) {
@@ -39,6 +46,89 @@ func TestKeyGen(t *testing.T) {
 	fmt.Println("setUp3", p3SaveData, p3SaveData.PublicKey)
 
 }
+func TestKeyGenWireBinary(t *testing.T) {
+	curve := edwards.Edwards()
+	setUp1 := NewSetUp(1, 3, curve)
+	setUp2 := NewSetUp(2, 3, curve)
+	setUp3 := NewSetUp(3, 3, curve)
+	// participant 3 keeps json, the format is told apart on decode
+	require.NoError(t, setUp1.SetWireFormat(tss.WireBinary))
+	require.NoError(t, setUp2.SetWireFormat(tss.WireBinary))
+	require.Error(t, setUp3.SetWireFormat(tss.WireFormat(5)))
+
+	msgs1_1, err := setUp1.DKGStep1()
+	require.NoError(t, err)
+	msgs2_1, err := setUp2.DKGStep1()
+	require.NoError(t, err)
+	msgs3_1, err := setUp3.DKGStep1()
+	require.NoError(t, err)
+	require.NotEqual(t, byte('{'), msgs1_1[2].Data[0])
+	require.Equal(t, byte('{'), msgs3_1[1].Data[0])
+
+	msgs1_2, err := setUp1.DKGStep2([]*tss.Message{msgs2_1[1], msgs3_1[1]})
+	require.NoError(t, err)
+	msgs2_2, err := setUp2.DKGStep2([]*tss.Message{msgs1_1[2], msgs3_1[2]})
+	require.NoError(t, err)
+	msgs3_2, err := setUp3.DKGStep2([]*tss.Message{msgs1_1[3], msgs2_1[3]})
+	require.NoError(t, err)
+
+	// a payload with trailing data is rejected
+	msg := *msgs1_2[2]
+	msg.Data += "\x00"
+	_, err = setUp2.DKGStep3([]*tss.Message{&msg, msgs3_2[2]})
+	var blame *tss.BlameError
+	require.ErrorAs(t, err, &blame)
+	require.Equal(t, 1, blame.Culprit)
+
+	p1SaveData, err := setUp1.DKGStep3([]*tss.Message{msgs2_2[1], msgs3_2[1]})
+	require.NoError(t, err)
+	p2SaveData, err := setUp2.DKGStep3([]*tss.Message{msgs1_2[2], msgs3_2[2]})
+	require.NoError(t, err)
+	p3SaveData, err := setUp3.DKGStep3([]*tss.Message{msgs1_2[3], msgs2_2[3]})
+	require.NoError(t, err)
+	require.True(t, p1SaveData.PublicKey.Equals(p2SaveData.PublicKey))
+	require.True(t, p1SaveData.PublicKey.Equals(p3SaveData.PublicKey))
+}
+
+func TestKeyGenReplay(t *testing.T) {
+	curve := secp256k1.S256()
+	newSession := func(sessionId string) []*SetupInfo {
+		setUps := make([]*SetupInfo, 2)
+		for i := range setUps {
+			setUps[i] = NewSetUpWithSession(sessionId, i+1, 2, 2, curve)
+		}
+		return setUps
+	}
+	a, b := newSession("a"), newSession("b")
+	msgsA1, err := a[0].DKGStep1()
+	require.NoError(t, err)
+	msgsA2, err := a[1].DKGStep1()
+	require.NoError(t, err)
+	_, err = b[0].DKGStep1()
+	require.NoError(t, err)
+
+	// message of session a is rejected by session b
+	_, err = b[0].DKGStep2([]*tss.Message{msgsA2[1]})
+	require.Error(t, err)
+
+	msgs, err := a[0].DKGStep2([]*tss.Message{msgsA2[1]})
+	require.NoError(t, err)
+	// round 2 message fed into step2
+	_, err = a[1].DKGStep2([]*tss.Message{msgs[2]})
+	require.Error(t, err)
+	_, err = a[1].DKGStep2([]*tss.Message{msgsA1[2]})
+	require.NoError(t, err)
+
+	// the session id survives export
+	key := make([]byte, 32)
+	sealed, err := a[1].Export(key)
+	require.NoError(t, err)
+	restored, err := ImportSetUp(key, sealed, tss.NewReplayList())
+	require.NoError(t, err)
+	_, err = restored.DKGStep3([]*tss.Message{msgs[2]})
+	require.NoError(t, err)
+}
+
 func TestKeyGen2_4(t *testing.T) {
 	curve := secp256k1.S256() // edwards.Edwards()
 	setUp1 := NewSetUp(1, 4, curve)
@@ -87,4 +177,264 @@ func TestKeyGen2_4(t *testing.T) {
 	fmt.Println("setUp2", p2SaveData, p2SaveData.PublicKey)
 	fmt.Println("setUp3", p3SaveData, p3SaveData.PublicKey)
 	fmt.Println("setUp4", p4SaveData, p4SaveData.PublicKey)
-}
\ No newline at end of file
+}
+
+func TestKeyGen3_5(t *testing.T) {
+	testThresholdKeyGen(t, secp256k1.S256(), 3, 5)
+	testThresholdKeyGen(t, edwards.Edwards(), 3, 5)
+}
+
+func TestKeyGen4_7(t *testing.T) {
+	testThresholdKeyGen(t, secp256k1.S256(), 4, 7)
+}
+
+func TestNewSetUpThreshold(t *testing.T) {
+	curve := secp256k1.S256()
+	require.Panics(t, func() { NewSetUpWithSession("keygen", 1, 1, 3, curve) })
+	require.Panics(t, func() { NewSetUpWithSession("keygen", 1, 4, 3, curve) })
+	require.Equal(t, 2, NewSetUp(1, 3, curve).Threshold)
+	require.Equal(t, 2, NewSetUpWithSession("keygen", 1, 2, 3, curve).Threshold)
+}
+
+func testThresholdKeyGen(t *testing.T, curve elliptic.Curve, threshold, total int) {
+	saveData := runKeyGen(t, curve, threshold, total)
+	publicKey := saveData[0].PublicKey
+	for _, data := range saveData {
+		require.True(t, data.PublicKey.Equals(publicKey))
+		require.Equal(t, total, len(data.SharePubKeyMap))
+		for id, sharePub := range data.SharePubKeyMap {
+			require.True(t, sharePub.Equals(saveData[id-1].SharePubKeyMap[id]))
+		}
+		require.True(t, curves.ScalarToPoint(curve, data.ShareI).Equals(data.SharePubKeyMap[data.Id]))
+	}
+
+	// any t shares recover the private key
+	for start := 0; start+threshold <= total; start++ {
+		shares := make([]*vss.Share, threshold)
+		for i := 0; i < threshold; i++ {
+			data := saveData[start+i]
+			shares[i] = &vss.Share{Id: big.NewInt(int64(data.Id)), Y: data.ShareI}
+		}
+		secret := vss.RecoverSecret(curve, shares)
+		require.True(t, curves.ScalarToPoint(curve, secret).Equals(publicKey))
+
+		// t-1 shares do not
+		secret = vss.RecoverSecret(curve, shares[1:])
+		require.False(t, curves.ScalarToPoint(curve, secret).Equals(publicKey))
+	}
+}
+
+func runKeyGen(t *testing.T, curve elliptic.Curve, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*SetupInfo, total)
+	for i := 0; i < total; i++ {
+		setUps[i] = NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+
+	msgs1 := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		msgs1[i] = msgs
+	}
+	msgs2 := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collectMessages(msgs1, i+1))
+		require.NoError(t, err)
+		msgs2[i] = msgs
+	}
+	saveData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collectMessages(msgs2, i+1))
+		require.NoError(t, err)
+		saveData[i] = data
+	}
+	return saveData
+}
+
+// collectMessages pick up the messages sent to id
+func collectMessages(out []map[int]*tss.Message, id int) []*tss.Message {
+	var msgs []*tss.Message
+	for i, msgMap := range out {
+		if i+1 == id {
+			continue
+		}
+		msgs = append(msgs, msgMap[id])
+	}
+	return msgs
+}
+
+func TestKeyGenSession(t *testing.T) {
+	for _, curve := range []elliptic.Curve{secp256k1.S256(), edwards.Edwards()} {
+		key := make([]byte, 32)
+		replay := tss.NewReplayList()
+		total := 3
+		setUps := make([]*SetupInfo, total)
+		for i := range setUps {
+			setUps[i] = NewSetUpWithSession("keygen", i+1, 2, total, curve)
+		}
+		// every participant is restored from sealed state between rounds
+		restore := func() {
+			for i, setUp := range setUps {
+				sealed, err := setUp.Export(key)
+				require.NoError(t, err)
+				setUps[i], err = ImportSetUp(key, sealed, replay)
+				require.NoError(t, err)
+				_, err = ImportSetUp(key, sealed, replay)
+				require.Error(t, err)
+			}
+		}
+		msgs1 := make([]map[int]*tss.Message, total)
+		for i, setUp := range setUps {
+			msgs, err := setUp.DKGStep1()
+			require.NoError(t, err)
+			msgs1[i] = msgs
+		}
+		restore()
+		msgs2 := make([]map[int]*tss.Message, total)
+		for i, setUp := range setUps {
+			msgs, err := setUp.DKGStep2(collectMessages(msgs1, i+1))
+			require.NoError(t, err)
+			msgs2[i] = msgs
+		}
+		restore()
+		shares := make([]*vss.Share, total)
+		var publicKey *curves.ECPoint
+		for i, setUp := range setUps {
+			data, err := setUp.DKGStep3(collectMessages(msgs2, i+1))
+			require.NoError(t, err)
+			shares[i] = &vss.Share{Id: big.NewInt(int64(data.Id)), Y: data.ShareI}
+			publicKey = data.PublicKey
+		}
+		secret := vss.RecoverSecret(curve, shares[:2])
+		require.True(t, curves.ScalarToPoint(curve, secret).Equals(publicKey))
+	}
+}
+
+func TestKeyGenBlame(t *testing.T) {
+	curve := secp256k1.S256()
+	// participant 2 sends an invalid share to participant 1
+	setUps, msgs2 := runKeyGenStep2(t, curve, 2, 3, func(setUps []*SetupInfo) {
+		setUps[1].secretShares[0] = &vss.Share{Id: big.NewInt(1), Y: big.NewInt(1)}
+	})
+
+	_, err := setUps[0].DKGStep3(collectMessages(msgs2, 1))
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 2, blame.Culprit)
+	require.Equal(t, tss.BlameShare, blame.Reason)
+	require.Equal(t, msgs2[1][1], blame.Evidence)
+
+	_, err = setUps[2].DKGStep3(collectMessages(msgs2, 3))
+	require.NoError(t, err)
+
+	// complaint round, participant 2 reveals the invalid share
+	complaints, err := setUps[0].DKGComplain(blame)
+	require.NoError(t, err)
+	reveals, err := setUps[1].DKGRevealShare(complaints[2])
+	require.NoError(t, err)
+	judge, err := setUps[2].DKGJudge(complaints[3], reveals[3])
+	require.NoError(t, err)
+	require.Equal(t, 2, judge.Culprit)
+	judge, err = setUps[0].DKGJudge(complaints[3], reveals[1])
+	require.NoError(t, err)
+	require.Equal(t, 2, judge.Culprit)
+	// no answer
+	judge, err = setUps[2].DKGJudge(complaints[3], nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, judge.Culprit)
+}
+
+func TestKeyGenFalseComplaint(t *testing.T) {
+	curve := secp256k1.S256()
+	setUps, msgs2 := runKeyGenStep2(t, curve, 2, 3, nil)
+	// share from participant 2 is corrupted in transit
+	var data tss.KeyStep2Data
+	require.NoError(t, json.Unmarshal([]byte(msgs2[1][1].Data), &data))
+	data.Share.Y = new(big.Int).Add(data.Share.Y, big.NewInt(1))
+	bytes, _ := json.Marshal(data)
+	msgs2[1][1].Data = string(bytes)
+
+	_, err := setUps[0].DKGStep3(collectMessages(msgs2, 1))
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 2, blame.Culprit)
+
+	// participant 2 reveals the correct share, the complaint is not justified
+	complaints, err := setUps[0].DKGComplain(blame)
+	require.NoError(t, err)
+	reveals, err := setUps[1].DKGRevealShare(complaints[2])
+	require.NoError(t, err)
+	_, err = setUps[2].DKGStep3(collectMessages(msgs2, 3))
+	require.NoError(t, err)
+	judge, err := setUps[2].DKGJudge(complaints[3], reveals[3])
+	require.NoError(t, err)
+	require.Equal(t, 1, judge.Culprit)
+	require.Equal(t, tss.BlameComplaint, judge.Reason)
+}
+
+func TestKeyGenBlameCommitment(t *testing.T) {
+	curve := secp256k1.S256()
+	setUps, msgs2 := runKeyGenStep2(t, curve, 2, 3, nil)
+	var data tss.KeyStep2Data
+	require.NoError(t, json.Unmarshal([]byte(msgs2[2][1].Data), &data))
+	data.Witness = &[]*big.Int{big.NewInt(1)}
+	bytes, _ := json.Marshal(data)
+	msgs2[2][1].Data = string(bytes)
+
+	_, err := setUps[0].DKGStep3(collectMessages(msgs2, 1))
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 3, blame.Culprit)
+	require.Equal(t, tss.BlameCommitment, blame.Reason)
+}
+
+// runKeyGenStep2 run dkg until step2, tamper is called before step2
+func runKeyGenStep2(t *testing.T, curve elliptic.Curve, threshold, total int, tamper func([]*SetupInfo)) ([]*SetupInfo, []map[int]*tss.Message) {
+	setUps := make([]*SetupInfo, total)
+	for i := 0; i < total; i++ {
+		setUps[i] = NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	msgs1 := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		msgs1[i] = msgs
+	}
+	if tamper != nil {
+		tamper(setUps)
+	}
+	msgs2 := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collectMessages(msgs1, i+1))
+		require.NoError(t, err)
+		msgs2[i] = msgs
+	}
+	return setUps, msgs2
+}
+
+func TestKeyGenDuplicateSender(t *testing.T) {
+	curve := secp256k1.S256()
+	setUp1 := NewSetUp(1, 3, curve)
+	setUp2 := NewSetUp(2, 3, curve)
+	_, err := setUp1.DKGStep1()
+	require.NoError(t, err)
+	msgs2_1, err := setUp2.DKGStep1()
+	require.NoError(t, err)
+	// two step1 messages of participant 2 pass the count check
+	_, err = setUp1.DKGStep2([]*tss.Message{msgs2_1[1], msgs2_1[1]})
+	var blame *tss.BlameError
+	require.ErrorAs(t, err, &blame)
+	require.Equal(t, 2, blame.Culprit)
+	require.Equal(t, tss.BlameDuplicate, blame.Reason)
+
+	setUps, msgs2 := runKeyGenStep2(t, curve, 2, 3, nil)
+	_, err = setUps[0].DKGStep3([]*tss.Message{msgs2[1][1], msgs2[1][1]})
+	require.ErrorAs(t, err, &blame)
+	require.Equal(t, 2, blame.Culprit)
+	require.Equal(t, tss.BlameDuplicate, blame.Reason)
+
+	msg := *msgs2[1][1]
+	msg.From = 4
+	_, err = setUps[0].DKGStep3([]*tss.Message{msgs2[2][1], &msg})
+	require.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package dkg
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/commitment"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+const sessionKind = "dkg/setup"
+
+type setupState struct {
+	DeviceNumber  int
+	Threshold     int
+	Total         int
+	RoundNumber   int
+	Curve         string
+	Ui            *big.Int
+	ShareI        *big.Int
+	PublicKey     *curves.ECPoint
+	Chaincode     *big.Int
+	Verifiers     []*curves.ECPoint
+	SecretShares  []*vss.Share
+	DeC           *commitment.Witness
+	CommitmentMap map[int]commitment.Commitment
+	VerifierMap   map[int][]*curves.ECPoint
+	SessionId     string
+	Format        tss.WireFormat
+}
+
+// Export seal dkg state with key after DKGStep1, the in-memory state is cleared,
+// dkg continues only from ImportSetUp
+func (info *SetupInfo) Export(key []byte) ([]byte, error) {
+	if info.RoundNumber < 2 || info.ui == nil {
+		return nil, fmt.Errorf("no session state to export")
+	}
+	curveName := curves.GetCurveName(info.curve)
+	if curveName == "" {
+		return nil, fmt.Errorf("curve not supported")
+	}
+	state := &setupState{
+		DeviceNumber:  info.DeviceNumber,
+		Threshold:     info.Threshold,
+		Total:         info.Total,
+		RoundNumber:   info.RoundNumber,
+		Curve:         curveName,
+		Ui:            info.ui,
+		ShareI:        info.shareI,
+		PublicKey:     info.publicKey,
+		Chaincode:     info.chaincode,
+		Verifiers:     info.verifiers,
+		SecretShares:  info.secretShares,
+		DeC:           info.deC,
+		CommitmentMap: info.commitmentMap,
+		VerifierMap:   info.verifierMap,
+		SessionId:     info.envelope.SessionId,
+		Format:        info.envelope.Format,
+	}
+	sealed, err := tss.SealSession(key, sessionKind, state)
+	if err != nil {
+		return nil, err
+	}
+	info.RoundNumber = -1
+	info.ui = nil
+	info.shareI = nil
+	info.secretShares = nil
+	return sealed, nil
+}
+
+// ImportSetUp restore dkg state, each sealed state is imported once
+func ImportSetUp(key, sealed []byte, replay tss.ReplayStore) (*SetupInfo, error) {
+	var state setupState
+	err := tss.OpenSession(key, sessionKind, sealed, replay, &state)
+	if err != nil {
+		return nil, err
+	}
+	curve, ok := curves.GetCurveByName(state.Curve)
+	if !ok || state.Ui == nil || state.Format.Check() != nil {
+		return nil, fmt.Errorf("dkg session state error")
+	}
+	return &SetupInfo{
+		DeviceNumber:  state.DeviceNumber,
+		Threshold:     state.Threshold,
+		Total:         state.Total,
+		RoundNumber:   state.RoundNumber,
+		ui:            state.Ui,
+		shareI:        state.ShareI,
+		publicKey:     state.PublicKey,
+		curve:         curve,
+		chaincode:     state.Chaincode,
+		verifiers:     state.Verifiers,
+		secretShares:  state.SecretShares,
+		deC:           state.DeC,
+		commitmentMap: state.CommitmentMap,
+		verifierMap:   state.VerifierMap,
+		envelope:      tss.Envelope{Protocol: protocol, SessionId: state.SessionId, Format: state.Format},
+	}, nil
+}
@@ -0,0 +1,49 @@
+package enroll
+
+import (
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// Recovery re-create a lost ShareI for an existing index, the process is the same as enrollment.
+// Helpers run NewHelper with newId = lost index, pieces mask each ci so no helper learns the share.
+// The lost device keeps its public key data, recovered share must match the stored SharePubKeyMap entry.
+
+// NewRecovery sharePubKeyMap and chaincode are the stored key data of the lost device
+//
+// Deprecated: messages are not bound to a session, use NewRecoveryWithSession.
+func NewRecovery(id, threshold int, helpers []int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *MemberInfo {
+	return newRecovery("", id, threshold, helpers, publicKey, sharePubKeyMap, chaincode)
+}
+
+// NewRecoveryWithSession sharePubKeyMap and chaincode are the stored key data of the lost device
+func NewRecoveryWithSession(sessionId string, id, threshold int, helpers []int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *MemberInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewRecovery params error"))
+	}
+	return newRecovery(sessionId, id, threshold, helpers, publicKey, sharePubKeyMap, chaincode)
+}
+
+func newRecovery(sessionId string, id, threshold int, helpers []int, publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *MemberInfo {
+	if sharePubKeyMap == nil || sharePubKeyMap[id] == nil {
+		panic(fmt.Errorf("NewRecovery params error, share publicKey unknown"))
+	}
+	info := newMember(sessionId, id, threshold, helpers, publicKey)
+	info.sharePubKeyMap = sharePubKeyMap
+	info.chaincode = chaincode
+	return info
+}
+
+// checkStored helpers information must be the same as the stored key data
+func checkStored(sharePubKeyMap map[int]*curves.ECPoint, chaincode string, content *Step2Data) error {
+	if content.ChainCode != chaincode || len(content.SharePubKeyMap) != len(sharePubKeyMap) {
+		return fmt.Errorf("helpers information do not match stored key data")
+	}
+	for id, X := range sharePubKeyMap {
+		if !content.SharePubKeyMap[id].Equals(X) {
+			return fmt.Errorf("share publicKey of participant %d do not match stored key data", id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package enroll
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// Enrollment issue a key share for a new index without changing publicKey.
+// t helpers hold ci = lambda_i(newId)*xi, sum(ci) = f(newId). Each helper splits ci into t random pieces,
+// helper j only sends sigma_j = sum(pieces for j) to the new member, no helper learns f(newId).
+// New member checks f(newId)*G against the group feldman commitments, i.e. interpolation of SharePubKeyMap.
+
+// protocol id of the message envelope
+const protocol = "enroll"
+
+// HelperInfo existing key share holder
+type HelperInfo struct {
+	DeviceNumber int
+	Threshold    int
+	RoundNumber  int
+	NewId        int
+
+	curve          elliptic.Curve
+	helpers        []int // exactly threshold helpers
+	ci             *big.Int
+	sharePubKeyMap map[int]*curves.ECPoint
+	chaincode      string
+	pieces         map[int]*big.Int
+	envelope       tss.Envelope // protocol and session of the messages
+}
+
+// NewHelper helpers must be exactly threshold existing participants, newId is the index of the new share
+//
+// Deprecated: messages are not bound to a session, use NewHelperWithSession.
+func NewHelper(deviceNumber, threshold int, helpers []int, newId int, ShareI *big.Int, sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *HelperInfo {
+	return newHelper("", deviceNumber, threshold, helpers, newId, ShareI, sharePubKeyMap, chaincode)
+}
+
+// NewHelperWithSession helpers must be exactly threshold existing participants, newId is the index of the new share,
+// helpers and the new member use the same unique sessionId
+func NewHelperWithSession(sessionId string, deviceNumber, threshold int, helpers []int, newId int, ShareI *big.Int, sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *HelperInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewHelper params error"))
+	}
+	return newHelper(sessionId, deviceNumber, threshold, helpers, newId, ShareI, sharePubKeyMap, chaincode)
+}
+
+func newHelper(sessionId string, deviceNumber, threshold int, helpers []int, newId int, ShareI *big.Int, sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *HelperInfo {
+	if threshold < 2 || len(helpers) != threshold || newId <= 0 || ShareI == nil || len(sharePubKeyMap) == 0 {
+		panic(fmt.Errorf("NewHelper params error"))
+	}
+	seen := make(map[int]bool, len(helpers))
+	for _, id := range helpers {
+		if id <= 0 || id == newId || seen[id] || sharePubKeyMap[id] == nil {
+			panic(fmt.Errorf("NewHelper helpers error"))
+		}
+		seen[id] = true
+	}
+	if !seen[deviceNumber] {
+		panic(fmt.Errorf("NewHelper params error, not a helper"))
+	}
+	curve := sharePubKeyMap[deviceNumber].Curve
+	return &HelperInfo{
+		DeviceNumber:   deviceNumber,
+		Threshold:      threshold,
+		RoundNumber:    1,
+		NewId:          newId,
+		curve:          curve,
+		helpers:        helpers,
+		ci:             lagrangianAt(curve, deviceNumber, ShareI, helpers, newId),
+		sharePubKeyMap: sharePubKeyMap,
+		chaincode:      chaincode,
+		envelope:       tss.Envelope{Protocol: protocol, SessionId: sessionId},
+	}
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *HelperInfo) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+// MemberInfo new key share holder
+type MemberInfo struct {
+	Id          int
+	Threshold   int
+	RoundNumber int
+
+	helpers   []int
+	publicKey *curves.ECPoint
+	envelope  tss.Envelope // protocol and session of the messages
+
+	// recovery only, stored public key data of the lost share
+	sharePubKeyMap map[int]*curves.ECPoint
+	chaincode      string
+}
+
+// NewMember publicKey is the known group publicKey, received SharePubKeyMap is checked against it
+//
+// Deprecated: messages are not bound to a session, use NewMemberWithSession.
+func NewMember(id, threshold int, helpers []int, publicKey *curves.ECPoint) *MemberInfo {
+	return newMember("", id, threshold, helpers, publicKey)
+}
+
+// NewMemberWithSession publicKey is the known group publicKey, received SharePubKeyMap is checked against it
+func NewMemberWithSession(sessionId string, id, threshold int, helpers []int, publicKey *curves.ECPoint) *MemberInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewMember params error"))
+	}
+	return newMember(sessionId, id, threshold, helpers, publicKey)
+}
+
+func newMember(sessionId string, id, threshold int, helpers []int, publicKey *curves.ECPoint) *MemberInfo {
+	if threshold < 2 || len(helpers) != threshold || id <= 0 || publicKey == nil {
+		panic(fmt.Errorf("NewMember params error"))
+	}
+	for _, h := range helpers {
+		if h == id {
+			panic(fmt.Errorf("NewMember helpers error"))
+		}
+	}
+	return &MemberInfo{
+		Id:          id,
+		Threshold:   threshold,
+		RoundNumber: 1,
+		helpers:     helpers,
+		publicKey:   publicKey,
+		envelope:    tss.Envelope{Protocol: protocol, SessionId: sessionId},
+	}
+}
+
+// lagrangianAt lagrangian interpolation at x instead of 0
+func lagrangianAt(curve elliptic.Curve, id int, y *big.Int, ids []int, x int) *big.Int {
+	xList := make([]*big.Int, len(ids))
+	for i, j := range ids {
+		xList[i] = big.NewInt(int64(j))
+	}
+	return vss.CalLagrangianAt(curve, big.NewInt(int64(id)), y, xList, big.NewInt(int64(x)))
+}
+
+// interpolatePoint f(x)*G = sum(lambda_i(x) * Xi)
+func interpolatePoint(curve elliptic.Curve, sharePubKeyMap map[int]*curves.ECPoint, ids []int, x int) (*curves.ECPoint, error) {
+	var sum *curves.ECPoint
+	for _, id := range ids {
+		lambda := lagrangianAt(curve, id, big.NewInt(1), ids, x)
+		// lambda is zero when x is another id of ids
+		if lambda.Sign() == 0 {
+			continue
+		}
+		point := sharePubKeyMap[id].ScalarMult(lambda)
+		if sum == nil {
+			sum = point
+			continue
+		}
+		var err error
+		sum, err = sum.Add(point)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sum == nil {
+		return nil, fmt.Errorf("interpolation error")
+	}
+	return sum, nil
+}
+
+// SharePubKey return f(x)*G from any threshold entries of sharePubKeyMap,
+// existing holders use it to add the enrolled participant to SharePubKeyMap
+func SharePubKey(sharePubKeyMap map[int]*curves.ECPoint, helpers []int, x int) (*curves.ECPoint, error) {
+	if len(helpers) == 0 {
+		return nil, fmt.Errorf("helpers is empty")
+	}
+	for _, id := range helpers {
+		if sharePubKeyMap[id] == nil {
+			return nil, fmt.Errorf("share publicKey of participant %d unknown", id)
+		}
+	}
+	return interpolatePoint(sharePubKeyMap[helpers[0]].Curve, sharePubKeyMap, helpers, x)
+}
+
+// verifySharePubKeyMap all entries lie on the same degree t-1 polynomial and f(0)*G = publicKey
+func verifySharePubKeyMap(publicKey *curves.ECPoint, sharePubKeyMap map[int]*curves.ECPoint, helpers []int) error {
+	for _, id := range helpers {
+		if sharePubKeyMap[id] == nil || !sharePubKeyMap[id].IsOnCurve() {
+			return fmt.Errorf("share publicKey of participant %d unknown", id)
+		}
+	}
+	curve := publicKey.Curve
+	point, err := interpolatePoint(curve, sharePubKeyMap, helpers, 0)
+	if err != nil {
+		return err
+	}
+	if !point.Equals(publicKey) {
+		return fmt.Errorf("share publicKey do not match publicKey")
+	}
+	for id, X := range sharePubKeyMap {
+		point, err = interpolatePoint(curve, sharePubKeyMap, helpers, id)
+		if err != nil {
+			return err
+		}
+		if !point.Equals(X) {
+			return fmt.Errorf("share publicKey of participant %d is not consistent", id)
+		}
+	}
+	return nil
+}
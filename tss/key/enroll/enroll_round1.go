@@ -0,0 +1,46 @@
+package enroll
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step1Data struct {
+	Piece *big.Int // random additive piece of ci
+}
+
+// EnrollStep1 split ci = sum(pieces) randomly, p2p send one piece to each other helper
+func (info *HelperInfo) EnrollStep1() (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	q := info.curve.Params().N
+	info.pieces = make(map[int]*big.Int, len(info.helpers))
+	rest := new(big.Int).Set(info.ci)
+	for _, id := range info.helpers {
+		if id == info.DeviceNumber {
+			continue
+		}
+		piece := crypto.RandomNum(q)
+		info.pieces[id] = piece
+		rest = rest.Sub(rest, piece)
+	}
+	info.pieces[info.DeviceNumber] = rest.Mod(rest, q)
+	info.RoundNumber = 2
+
+	out := make(map[int]*tss.Message, len(info.helpers)-1)
+	for _, id := range info.helpers {
+		if id == info.DeviceNumber {
+			continue
+		}
+		bytes, err := info.envelope.MarshalData(Step1Data{Piece: info.pieces[id]})
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package enroll
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type Step2Data struct {
+	Sigma          *big.Int // sum of received pieces
+	SharePubKeyMap map[int]*curves.ECPoint
+	ChainCode      string
+}
+
+// EnrollStep2 sigma_j = sum(pieces for j), send to the new member
+func (info *HelperInfo) EnrollStep2(msgs []*tss.Message) (*tss.Message, error) {
+	if info.RoundNumber != 2 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != (len(info.helpers) - 1) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	q := info.curve.Params().N
+	sigma := new(big.Int).Set(info.pieces[info.DeviceNumber])
+	received := make(map[int]bool, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.DeviceNumber {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if !isHelper(info.helpers, msg.From) || msg.From == info.DeviceNumber || received[msg.From] {
+			return nil, fmt.Errorf("unknown or duplicate helper %d", msg.From)
+		}
+		received[msg.From] = true
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
+		var content Step1Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil || content.Piece == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		sigma = sigma.Add(sigma, content.Piece)
+	}
+	info.pieces = nil
+	info.RoundNumber = -1
+
+	content := Step2Data{
+		Sigma:          sigma.Mod(sigma, q),
+		SharePubKeyMap: info.sharePubKeyMap,
+		ChainCode:      info.chaincode,
+	}
+	bytes, err := info.envelope.MarshalData(content)
+	if err != nil {
+		return nil, err
+	}
+	return info.envelope.NewMessage(info.DeviceNumber, info.NewId, 2, bytes), nil
+}
+
+func isHelper(helpers []int, id int) bool {
+	for _, h := range helpers {
+		if h == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package enroll
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// EnrollStep3 new member sum sigma from all helpers, verify against share publicKeys, return key share information
+func (info *MemberInfo) EnrollStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	if len(msgs) != len(info.helpers) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	curve := info.publicKey.Curve
+	q := curve.Params().N
+	xi := big.NewInt(0)
+	contents := make(map[int]*Step2Data, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.Id {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if !isHelper(info.helpers, msg.From) {
+			return nil, fmt.Errorf("unknown helper %d", msg.From)
+		}
+		if _, ok := contents[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, helper %d", msg.From)
+		}
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, err
+		}
+		var content Step2Data
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil || content.Sigma == nil || content.SharePubKeyMap == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		contents[msg.From] = &content
+		xi = xi.Add(xi, content.Sigma)
+	}
+	xi.Mod(xi, q)
+
+	// all helpers agree on share publicKeys and chaincode
+	reference := contents[info.helpers[0]]
+	for _, content := range contents {
+		if content.ChainCode != reference.ChainCode || len(content.SharePubKeyMap) != len(reference.SharePubKeyMap) {
+			return nil, fmt.Errorf("helpers information inconsistent")
+		}
+		for id, X := range reference.SharePubKeyMap {
+			if !content.SharePubKeyMap[id].Equals(X) {
+				return nil, fmt.Errorf("helpers information inconsistent")
+			}
+		}
+	}
+	if info.sharePubKeyMap != nil {
+		err := checkStored(info.sharePubKeyMap, info.chaincode, reference)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := verifySharePubKeyMap(info.publicKey, reference.SharePubKeyMap, info.helpers)
+	if err != nil {
+		return nil, err
+	}
+	// f(id)*G from group feldman commitments
+	Xi, err := interpolatePoint(curve, reference.SharePubKeyMap, info.helpers, info.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !curves.ScalarToPoint(curve, xi).Equals(Xi) {
+		return nil, fmt.Errorf("enrolled share verify fail")
+	}
+	info.RoundNumber = -1
+
+	sharePubKeyMap := make(map[int]*curves.ECPoint, len(reference.SharePubKeyMap)+1)
+	for id, X := range reference.SharePubKeyMap {
+		sharePubKeyMap[id] = X
+	}
+	sharePubKeyMap[info.Id] = Xi
+	return &tss.KeyStep3Data{
+		Id:             info.Id,
+		ShareI:         xi,
+		PublicKey:      info.publicKey,
+		ChainCode:      reference.ChainCode,
+		SharePubKeyMap: sharePubKeyMap,
+	}, nil
+}
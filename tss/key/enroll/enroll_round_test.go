@@ -0,0 +1,173 @@
+package enroll
+
+import (
+	"crypto/elliptic"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnroll(t *testing.T) {
+	for _, curve := range []elliptic.Curve{secp256k1.S256(), edwards.Edwards()} {
+		keyData := keyGenThreshold(t, curve, 2, 3)
+		// 1 and 3 help device 4 join
+		data4 := enroll(t, keyData, []int{1, 3}, 4)
+		require.True(t, data4.PublicKey.Equals(keyData[0].PublicKey))
+		require.Equal(t, keyData[0].ChainCode, data4.ChainCode)
+
+		// the new share works with any other share
+		shares := []*vss.Share{
+			{Id: big.NewInt(2), Y: keyData[1].ShareI},
+			{Id: big.NewInt(4), Y: data4.ShareI},
+		}
+		secret := vss.RecoverSecret(curve, shares)
+		require.True(t, curves.ScalarToPoint(curve, secret).Equals(data4.PublicKey))
+
+		// existing holders compute the same share publicKey
+		X4, err := SharePubKey(keyData[1].SharePubKeyMap, []int{1, 2}, 4)
+		require.NoError(t, err)
+		require.True(t, X4.Equals(data4.SharePubKeyMap[4]))
+	}
+
+	keyData := keyGenThreshold(t, secp256k1.S256(), 3, 5)
+	data7 := enroll(t, keyData, []int{2, 3, 5}, 7)
+	shares := []*vss.Share{
+		{Id: big.NewInt(1), Y: keyData[0].ShareI},
+		{Id: big.NewInt(4), Y: keyData[3].ShareI},
+		{Id: big.NewInt(7), Y: data7.ShareI},
+	}
+	secret := vss.RecoverSecret(secp256k1.S256(), shares)
+	require.True(t, curves.ScalarToPoint(secp256k1.S256(), secret).Equals(data7.PublicKey))
+}
+
+func TestEnrollWrongSigma(t *testing.T) {
+	keyData := keyGenThreshold(t, secp256k1.S256(), 2, 3)
+	helpers := []int{1, 2}
+	msgs := runHelpers(t, keyData, helpers, 4)
+	msgs[1].Data = `{"Sigma":1,"SharePubKeyMap":` + mapJson(t, keyData[1]) + `,"ChainCode":"` + keyData[1].ChainCode + `"}`
+
+	member := NewMemberWithSession("enroll", 4, 2, helpers, keyData[0].PublicKey)
+	_, err := member.EnrollStep3(msgs)
+	require.Error(t, err)
+}
+
+func TestEnrollOtherSession(t *testing.T) {
+	keyData := keyGenThreshold(t, secp256k1.S256(), 2, 3)
+	helpers := []int{1, 2}
+	msgs := runHelpers(t, keyData, helpers, 4)
+
+	member := NewMemberWithSession("other", 4, 2, helpers, keyData[0].PublicKey)
+	_, err := member.EnrollStep3(msgs)
+	require.Error(t, err)
+	require.Panics(t, func() { NewMemberWithSession("", 4, 2, helpers, keyData[0].PublicKey) })
+}
+
+func TestRecovery(t *testing.T) {
+	for _, curve := range []elliptic.Curve{secp256k1.S256(), edwards.Edwards()} {
+		keyData := keyGenThreshold(t, curve, 2, 3)
+		// device 2 lost ShareI, 1 and 3 recover it
+		lost := keyData[1]
+		msgs := runHelpers(t, keyData, []int{1, 3}, 2)
+		member := NewRecoveryWithSession("enroll", 2, 2, []int{1, 3}, lost.PublicKey, lost.SharePubKeyMap, lost.ChainCode)
+		data, err := member.EnrollStep3(msgs)
+		require.NoError(t, err)
+		require.Equal(t, 0, data.ShareI.Cmp(lost.ShareI))
+		require.True(t, data.SharePubKeyMap[2].Equals(lost.SharePubKeyMap[2]))
+	}
+}
+
+func TestRecoveryStoredMismatch(t *testing.T) {
+	keyData := keyGenThreshold(t, secp256k1.S256(), 2, 3)
+	other := keyGenThreshold(t, secp256k1.S256(), 2, 3)
+	msgs := runHelpers(t, keyData, []int{1, 3}, 2)
+	// stored key data of another key
+	member := NewRecoveryWithSession("enroll", 2, 2, []int{1, 3}, keyData[1].PublicKey, other[1].SharePubKeyMap, keyData[1].ChainCode)
+	_, err := member.EnrollStep3(msgs)
+	require.Error(t, err)
+}
+
+func enroll(t *testing.T, keyData []*tss.KeyStep3Data, helpers []int, newId int) *tss.KeyStep3Data {
+	msgs := runHelpers(t, keyData, helpers, newId)
+	member := NewMemberWithSession("enroll", newId, len(helpers), helpers, keyData[0].PublicKey)
+	data, err := member.EnrollStep3(msgs)
+	require.NoError(t, err)
+	require.Equal(t, newId, data.Id)
+	return data
+}
+
+// runHelpers return the messages sent to newId, one per helper
+func runHelpers(t *testing.T, keyData []*tss.KeyStep3Data, helpers []int, newId int) []*tss.Message {
+	infos := make([]*HelperInfo, len(helpers))
+	for i, id := range helpers {
+		data := keyData[id-1]
+		infos[i] = NewHelperWithSession("enroll", id, len(helpers), helpers, newId, data.ShareI, data.SharePubKeyMap, data.ChainCode)
+	}
+	out := make(map[int]map[int]*tss.Message, len(helpers))
+	for i, info := range infos {
+		msgs, err := info.EnrollStep1()
+		require.NoError(t, err)
+		out[helpers[i]] = msgs
+	}
+	var result []*tss.Message
+	for i, info := range infos {
+		var msgs []*tss.Message
+		for from, msgMap := range out {
+			if from != helpers[i] {
+				msgs = append(msgs, msgMap[helpers[i]])
+			}
+		}
+		msg, err := info.EnrollStep2(msgs)
+		require.NoError(t, err)
+		result = append(result, msg)
+	}
+	return result
+}
+
+func mapJson(t *testing.T, data *tss.KeyStep3Data) string {
+	bytes, err := json.Marshal(data.SharePubKeyMap)
+	require.NoError(t, err)
+	return string(bytes)
+}
+
+func keyGenThreshold(t *testing.T, curve elliptic.Curve, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	out := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	collect := func(out []map[int]*tss.Message, id int) []*tss.Message {
+		var msgs []*tss.Message
+		for i, msgMap := range out {
+			if i+1 != id {
+				msgs = append(msgs, msgMap[id])
+			}
+		}
+		return msgs
+	}
+	next := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out, i+1))
+		require.NoError(t, err)
+		next[i] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(next, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
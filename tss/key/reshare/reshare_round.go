@@ -0,0 +1,109 @@
+package reshare
+
+import (
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// reshareProtocol id of the reshare message envelope
+const reshareProtocol = "reshare"
+
+// ReshareInfo move the key from old (t, n) committee to new (t', n') committee,
+// publicKey and chaincode no longer change. Old and new committee may overlap or be different devices.
+// Old contributors in devoteList share wi = lambda_i*xi to new committee, new share = sum(shares).
+// After reshare, old key shares must be deleted.
+type ReshareInfo struct {
+	OldId        int // id in old committee, 0 if not an old contributor
+	NewId        int // id in new committee, 0 if not a new member
+	NewThreshold int
+	NewTotal     int
+	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
+
+	curve      elliptic.Curve
+	devoteList []int // old contributors, at least old threshold
+	wi         *big.Int
+	publicKey  *curves.ECPoint
+
+	// old contributor only
+	sharePubKeyMap map[int]*curves.ECPoint
+	chaincode      string
+}
+
+// NewReshare oldId/newId is 0 if the device is not in the old/new committee.
+// shareI, sharePubKeyMap and chaincode from old key data are only required for old contributors
+//
+// Deprecated: messages are not bound to a session, use NewReshareWithSession.
+func NewReshare(oldId, newId int, devoteList []int, newThreshold, newTotal int, shareI *big.Int, publicKey *curves.ECPoint,
+	sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *ReshareInfo {
+	return newReshare("", oldId, newId, devoteList, newThreshold, newTotal, shareI, publicKey, sharePubKeyMap, chaincode)
+}
+
+// NewReshareWithSession oldId/newId is 0 if the device is not in the old/new committee.
+// shareI, sharePubKeyMap and chaincode from old key data are only required for old contributors,
+// old and new committee use the same unique sessionId
+func NewReshareWithSession(sessionId string, oldId, newId int, devoteList []int, newThreshold, newTotal int, shareI *big.Int, publicKey *curves.ECPoint,
+	sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *ReshareInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewReshare params error"))
+	}
+	return newReshare(sessionId, oldId, newId, devoteList, newThreshold, newTotal, shareI, publicKey, sharePubKeyMap, chaincode)
+}
+
+func newReshare(sessionId string, oldId, newId int, devoteList []int, newThreshold, newTotal int, shareI *big.Int, publicKey *curves.ECPoint,
+	sharePubKeyMap map[int]*curves.ECPoint, chaincode string) *ReshareInfo {
+	if publicKey == nil || newThreshold < 2 || newTotal < newThreshold || newId < 0 || newId > newTotal {
+		panic(fmt.Errorf("NewReshare params error"))
+	}
+	if oldId == 0 && newId == 0 {
+		panic(fmt.Errorf("NewReshare params error, neither old nor new member"))
+	}
+	seen := make(map[int]bool, len(devoteList))
+	xList := make([]*big.Int, len(devoteList))
+	for i, id := range devoteList {
+		if id <= 0 || seen[id] {
+			panic(fmt.Errorf("NewReshare devoteList error"))
+		}
+		seen[id] = true
+		xList[i] = big.NewInt(int64(id))
+	}
+	curve := publicKey.Curve
+	info := &ReshareInfo{
+		OldId:        oldId,
+		NewId:        newId,
+		NewThreshold: newThreshold,
+		NewTotal:     newTotal,
+		RoundNumber:  1,
+		envelope:     tss.Envelope{Protocol: reshareProtocol, SessionId: sessionId},
+		curve:        curve,
+		devoteList:   devoteList,
+		publicKey:    publicKey,
+	}
+	if oldId != 0 {
+		if !seen[oldId] || shareI == nil || sharePubKeyMap == nil || chaincode == "" {
+			panic(fmt.Errorf("NewReshare params error, old contributor"))
+		}
+		info.wi = vss.CalLagrangian(curve, big.NewInt(int64(oldId)), shareI, xList)
+		info.sharePubKeyMap = sharePubKeyMap
+		info.chaincode = chaincode
+	}
+	return info
+}
+
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *ReshareInfo) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
+func (info *ReshareInfo) NewIds() []int {
+	var ids []int
+	for i := 1; i <= info.NewTotal; i++ {
+		ids = append(ids, i)
+	}
+	return ids
+}
@@ -0,0 +1,51 @@
+package reshare
+
+import (
+	"fmt"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+type ReshareData struct {
+	Verifiers      []*curves.ECPoint // feldman verifiers of wi
+	Share          *vss.Share        // secret share for new member
+	ChainCode      string
+	SharePubKeyMap map[int]*curves.ECPoint // old committee ShareI*G
+}
+
+// ReshareStep1 old contributor p2p send shares of wi to every new member, including itself
+func (info *ReshareInfo) ReshareStep1() (map[int]*tss.Message, error) {
+	if info.RoundNumber != 1 {
+		return nil, fmt.Errorf("round error")
+	}
+	if info.OldId == 0 {
+		return nil, fmt.Errorf("not an old contributor")
+	}
+	feldman, err := vss.NewFeldman(info.NewThreshold, info.NewTotal, info.curve)
+	if err != nil {
+		return nil, err
+	}
+	verifiers, shares, err := feldman.Evaluate(info.wi)
+	if err != nil {
+		return nil, err
+	}
+	info.RoundNumber = 2
+
+	out := make(map[int]*tss.Message, info.NewTotal)
+	for _, id := range info.NewIds() {
+		content := ReshareData{
+			Verifiers:      verifiers,
+			Share:          shares[id-1],
+			ChainCode:      info.chaincode,
+			SharePubKeyMap: info.sharePubKeyMap,
+		}
+		bytes, err := info.envelope.MarshalData(content)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = info.envelope.NewMessage(info.OldId, id, 1, bytes)
+	}
+	return out, nil
+}
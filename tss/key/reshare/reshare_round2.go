@@ -0,0 +1,159 @@
+package reshare
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+)
+
+// ReshareStep2 new member receive shares from all old contributors, return new key share information
+func (info *ReshareInfo) ReshareStep2(msgs []*tss.Message) (*tss.KeyStep3Data, error) {
+	round := 1
+	if info.OldId != 0 {
+		round = 2
+	}
+	if info.RoundNumber != round {
+		return nil, fmt.Errorf("round error")
+	}
+	if info.NewId == 0 {
+		return nil, fmt.Errorf("not a new member")
+	}
+	if len(msgs) != len(info.devoteList) {
+		return nil, fmt.Errorf("messages number error")
+	}
+	curve := info.curve
+	q := curve.Params().N
+	feldman, err := vss.NewFeldman(info.NewThreshold, info.NewTotal, curve)
+	if err != nil {
+		return nil, err
+	}
+	xList := make([]*big.Int, len(info.devoteList))
+	for i, id := range info.devoteList {
+		xList[i] = big.NewInt(int64(id))
+	}
+
+	contents := make(map[int]*ReshareData, len(msgs))
+	for _, msg := range msgs {
+		if msg.To != info.NewId {
+			return nil, fmt.Errorf("message sending error")
+		}
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
+		if !info.isContributor(msg.From) {
+			return nil, fmt.Errorf("unknown contributor %d", msg.From)
+		}
+		if _, ok := contents[msg.From]; ok {
+			return nil, fmt.Errorf("duplicate message, contributor %d", msg.From)
+		}
+		var content ReshareData
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
+		if err != nil || content.Share == nil || content.Share.Id == nil || content.Share.Y == nil ||
+			len(content.Verifiers) != info.NewThreshold || content.SharePubKeyMap == nil {
+			return nil, tss.NewBlameError(tss.BlameMessage, msg)
+		}
+		for _, verifier := range content.Verifiers {
+			if verifier == nil || curves.GetCurveName(verifier.Curve) != curves.GetCurveName(curve) {
+				return nil, tss.NewBlameError(tss.BlameMessage, msg)
+			}
+		}
+		contents[msg.From] = &content
+	}
+
+	// all contributors agree on old share publicKeys and chaincode
+	reference := contents[info.devoteList[0]]
+	for _, content := range contents {
+		if content.ChainCode != reference.ChainCode {
+			return nil, fmt.Errorf("chaincode inconsistent")
+		}
+		for _, id := range info.devoteList {
+			if !content.SharePubKeyMap[id].Equals(reference.SharePubKeyMap[id]) {
+				return nil, fmt.Errorf("old share publicKey inconsistent")
+			}
+		}
+	}
+	// sum(lambda_i * Xi) = publicKey
+	lambdaXMap := make(map[int]*curves.ECPoint, len(info.devoteList))
+	sum := curves.ScalarToPoint(curve, big.NewInt(0))
+	for _, id := range info.devoteList {
+		lambda := vss.CalLagrangian(curve, big.NewInt(int64(id)), big.NewInt(1), xList)
+		lambdaXMap[id] = reference.SharePubKeyMap[id].ScalarMult(lambda)
+		sum, err = sum.Add(lambdaXMap[id])
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !sum.Equals(info.publicKey) {
+		return nil, fmt.Errorf("old share publicKey do not match publicKey")
+	}
+
+	xi := big.NewInt(0)
+	v := make([]*curves.ECPoint, info.NewThreshold)
+	for _, msg := range msgs {
+		content := contents[msg.From]
+		// contributor must share its own wi
+		if !content.Verifiers[0].Equals(lambdaXMap[msg.From]) {
+			return nil, tss.NewBlameError(tss.BlameCommitment, msg)
+		}
+		if content.Share.Id.Cmp(big.NewInt(int64(info.NewId))) != 0 {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
+		}
+		if ok, err := feldman.Verify(content.Share, content.Verifiers); !ok || err != nil {
+			return nil, tss.NewBlameError(tss.BlameShare, msg)
+		}
+		xi = new(big.Int).Add(xi, content.Share.Y)
+		for j := 0; j < info.NewThreshold; j++ {
+			if v[j] == nil {
+				v[j] = content.Verifiers[j]
+				continue
+			}
+			v[j], err = v[j].Add(content.Verifiers[j])
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	xi = new(big.Int).Mod(xi, q)
+	if !v[0].Equals(info.publicKey) {
+		return nil, fmt.Errorf("public key recalculation error")
+	}
+
+	sharePubKeyMap := make(map[int]*curves.ECPoint, info.NewTotal)
+	for k := 1; k <= info.NewTotal; k++ {
+		Yi := v[0]
+		tmp := big.NewInt(1)
+		for i := 1; i < info.NewThreshold; i++ {
+			tmp = tmp.Mul(tmp, big.NewInt(int64(k)))
+			Yi, err = Yi.Add(v[i].ScalarMult(tmp))
+			if err != nil {
+				return nil, err
+			}
+		}
+		sharePubKeyMap[k] = Yi
+	}
+	if !sharePubKeyMap[info.NewId].Equals(curves.ScalarToPoint(curve, xi)) {
+		return nil, fmt.Errorf("public key calculation error")
+	}
+	info.RoundNumber = -1
+
+	content := &tss.KeyStep3Data{
+		Id:             info.NewId,
+		ShareI:         xi,
+		PublicKey:      info.publicKey,
+		ChainCode:      reference.ChainCode,
+		SharePubKeyMap: sharePubKeyMap,
+	}
+	return content, nil
+}
+
+func (info *ReshareInfo) isContributor(id int) bool {
+	for _, i := range info.devoteList {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package reshare
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
+	"github.com/okx/threshold-lib/tss/key/dkg"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReshare(t *testing.T) {
+	for _, curve := range []elliptic.Curve{secp256k1.S256(), edwards.Edwards()} {
+		oldData := keyGenThreshold(t, curve, 2, 3)
+
+		// 2/3 -> 3/5, old 1 and 3 contribute, old 1 becomes new 1, old 3 leaves, new 2..5 are new devices
+		devoteList := []int{1, 3}
+		newData := reshare(t, oldData, devoteList, map[int]int{1: 1, 3: 0}, 3, 5)
+		checkKeyData(t, curve, oldData[0], newData, 3)
+
+		// 3/5 -> 2/2, completely different devices
+		devoteList = []int{2, 4, 5}
+		newData2 := reshare(t, newData, devoteList, map[int]int{2: 0, 4: 0, 5: 0}, 2, 2)
+		checkKeyData(t, curve, oldData[0], newData2, 2)
+	}
+}
+
+func TestReshareWrongContributor(t *testing.T) {
+	curve := secp256k1.S256()
+	oldData := keyGenThreshold(t, curve, 2, 3)
+	devoteList := []int{1, 2}
+	old1 := NewReshareWithSession("reshare", 1, 0, devoteList, 2, 3, oldData[0].ShareI, oldData[0].PublicKey, oldData[0].SharePubKeyMap, oldData[0].ChainCode)
+	// old 2 shares a random secret instead of its own share
+	old2 := NewReshareWithSession("reshare", 2, 0, devoteList, 2, 3, big.NewInt(12345), oldData[1].PublicKey, oldData[1].SharePubKeyMap, oldData[1].ChainCode)
+	msgs1, err := old1.ReshareStep1()
+	require.NoError(t, err)
+	msgs2, err := old2.ReshareStep1()
+	require.NoError(t, err)
+
+	newMember := NewReshareWithSession("reshare", 0, 1, devoteList, 2, 3, nil, oldData[0].PublicKey, nil, "")
+	_, err = newMember.ReshareStep2([]*tss.Message{msgs1[1], msgs2[1]})
+	blame, ok := err.(*tss.BlameError)
+	require.True(t, ok)
+	require.Equal(t, 2, blame.Culprit)
+}
+
+// reshare roles maps old id to new id, 0 if the old member leaves, other new ids are new devices
+func reshare(t *testing.T, oldData []*tss.KeyStep3Data, devoteList []int, roles map[int]int, newThreshold, newTotal int) []*tss.KeyStep3Data {
+	publicKey := oldData[0].PublicKey
+	infos := make(map[int]*ReshareInfo, newTotal) // new id -> info
+	var contributors []*ReshareInfo
+	for _, oldId := range devoteList {
+		data := oldData[oldId-1]
+		info := NewReshareWithSession("reshare", oldId, roles[oldId], devoteList, newThreshold, newTotal, data.ShareI, publicKey, data.SharePubKeyMap, data.ChainCode)
+		contributors = append(contributors, info)
+		if roles[oldId] != 0 {
+			infos[roles[oldId]] = info
+		}
+	}
+	for newId := 1; newId <= newTotal; newId++ {
+		if _, ok := infos[newId]; !ok {
+			infos[newId] = NewReshareWithSession("reshare", 0, newId, devoteList, newThreshold, newTotal, nil, publicKey, nil, "")
+		}
+	}
+
+	out := make([]map[int]*tss.Message, len(contributors))
+	for i, info := range contributors {
+		msgs, err := info.ReshareStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	newData := make([]*tss.KeyStep3Data, newTotal)
+	for newId := 1; newId <= newTotal; newId++ {
+		var msgs []*tss.Message
+		for _, msgMap := range out {
+			msgs = append(msgs, msgMap[newId])
+		}
+		data, err := infos[newId].ReshareStep2(msgs)
+		require.NoError(t, err)
+		newData[newId-1] = data
+	}
+	return newData
+}
+
+func checkKeyData(t *testing.T, curve elliptic.Curve, oldData *tss.KeyStep3Data, newData []*tss.KeyStep3Data, threshold int) {
+	for _, data := range newData {
+		require.True(t, data.PublicKey.Equals(oldData.PublicKey))
+		require.Equal(t, oldData.ChainCode, data.ChainCode)
+		require.True(t, curves.ScalarToPoint(curve, data.ShareI).Equals(data.SharePubKeyMap[data.Id]))
+	}
+	shares := make([]*vss.Share, threshold)
+	for i := 0; i < threshold; i++ {
+		shares[i] = &vss.Share{Id: big.NewInt(int64(newData[i].Id)), Y: newData[i].ShareI}
+	}
+	secret := vss.RecoverSecret(curve, shares)
+	require.True(t, curves.ScalarToPoint(curve, secret).Equals(oldData.PublicKey))
+	secret = vss.RecoverSecret(curve, shares[1:])
+	require.False(t, curves.ScalarToPoint(curve, secret).Equals(oldData.PublicKey))
+}
+
+func keyGenThreshold(t *testing.T, curve elliptic.Curve, threshold, total int) []*tss.KeyStep3Data {
+	setUps := make([]*dkg.SetupInfo, total)
+	for i := range setUps {
+		setUps[i] = dkg.NewSetUpWithSession("keygen", i+1, threshold, total, curve)
+	}
+	out := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep1()
+		require.NoError(t, err)
+		out[i] = msgs
+	}
+	collect := func(out []map[int]*tss.Message, id int) []*tss.Message {
+		var msgs []*tss.Message
+		for i, msgMap := range out {
+			if i+1 != id {
+				msgs = append(msgs, msgMap[id])
+			}
+		}
+		return msgs
+	}
+	next := make([]map[int]*tss.Message, total)
+	for i, setUp := range setUps {
+		msgs, err := setUp.DKGStep2(collect(out, i+1))
+		require.NoError(t, err)
+		next[i] = msgs
+	}
+	keyData := make([]*tss.KeyStep3Data, total)
+	for i, setUp := range setUps {
+		data, err := setUp.DKGStep3(collect(next, i+1))
+		require.NoError(t, err)
+		keyData[i] = data
+	}
+	return keyData
+}
@@ -8,13 +8,18 @@ import (
 	"github.com/okx/threshold-lib/crypto/commitment"
 	"github.com/okx/threshold-lib/crypto/curves"
 	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/tss"
 )
 
+// refreshProtocol id of the refresh message envelope
+const refreshProtocol = "refresh"
+
 type RefreshInfo struct {
 	DeviceNumber int
 	Threshold    int // 2/n
 	Total        int
 	RoundNumber  int
+	envelope     tss.Envelope // protocol and session of the messages
 
 	curve      elliptic.Curve
 	devoteList [2]int // 2 contributors reset the key share
@@ -29,7 +34,21 @@ type RefreshInfo struct {
 }
 
 // NewRefresh the process is consistent with dkg
+//
+// Deprecated: messages are not bound to a session, use NewRefreshWithSession.
 func NewRefresh(deviceNumber, total int, devoteList [2]int, ShareI *big.Int, PublicKey *curves.ECPoint) *RefreshInfo {
+	return newRefresh("", deviceNumber, total, devoteList, ShareI, PublicKey)
+}
+
+// NewRefreshWithSession the process is consistent with dkg, all participants use the same unique sessionId
+func NewRefreshWithSession(sessionId string, deviceNumber, total int, devoteList [2]int, ShareI *big.Int, PublicKey *curves.ECPoint) *RefreshInfo {
+	if sessionId == "" {
+		panic(fmt.Errorf("NewRefresh params error"))
+	}
+	return newRefresh(sessionId, deviceNumber, total, devoteList, ShareI, PublicKey)
+}
+
+func newRefresh(sessionId string, deviceNumber, total int, devoteList [2]int, ShareI *big.Int, PublicKey *curves.ECPoint) *RefreshInfo {
 	if total < 2 || deviceNumber > total || deviceNumber <= 0 {
 		panic(fmt.Errorf("NewRefresh params error"))
 	}
@@ -39,6 +58,7 @@ func NewRefresh(deviceNumber, total int, devoteList [2]int, ShareI *big.Int, Pub
 		Threshold:    2,
 		Total:        total,
 		RoundNumber:  1,
+		envelope:     tss.Envelope{Protocol: refreshProtocol, SessionId: sessionId},
 		devoteList:   devoteList,
 		publicKey:    PublicKey,
 		curve:        curve,
@@ -53,6 +73,11 @@ func NewRefresh(deviceNumber, total int, devoteList [2]int, ShareI *big.Int, Pub
 	return info
 }
 
+// SetWireFormat choose the encoding of outgoing payloads, json by default, incoming payloads are accepted in both formats
+func (info *RefreshInfo) SetWireFormat(format tss.WireFormat) error {
+	return info.envelope.SetWireFormat(format)
+}
+
 func (info *RefreshInfo) Ids() []int {
 	var ids []int
 	for i := 1; i <= info.Total; i++ {
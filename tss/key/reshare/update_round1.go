@@ -1,7 +1,6 @@
 package reshare
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -43,15 +42,11 @@ func (info *RefreshInfo) DKGStep1() (map[int]*tss.Message, error) {
 			continue
 		}
 		content := tss.KeyStep1Data{C: &hashCommitment.C}
-		bytes, err := json.Marshal(content)
+		bytes, err := info.envelope.MarshalData(content)
 		if err != nil {
 			return nil, err
 		}
-		message := &tss.Message{
-			From: info.DeviceNumber,
-			To:   id,
-			Data: string(bytes),
-		}
+		message := info.envelope.NewMessage(info.DeviceNumber, id, 1, bytes)
 		out[id] = message
 	}
 	return out, nil
@@ -1,7 +1,6 @@
 package reshare
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/okx/threshold-lib/crypto/commitment"
 	"github.com/okx/threshold-lib/crypto/curves"
@@ -22,8 +21,11 @@ func (info *RefreshInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, er
 		if msg.To != info.DeviceNumber {
 			return nil, fmt.Errorf("message sending error")
 		}
+		if err := info.envelope.CheckMessage(msg, 1); err != nil {
+			return nil, err
+		}
 		var content tss.KeyStep1Data
-		err := json.Unmarshal([]byte(msg.Data), &content)
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
 		if err != nil {
 			return nil, err
 		}
@@ -47,15 +49,11 @@ func (info *RefreshInfo) DKGStep2(msgs []*tss.Message) (map[int]*tss.Message, er
 			Share:   info.secretShares[id-1],
 			Proof:   proof,
 		}
-		bytes, err := json.Marshal(content)
+		bytes, err := info.envelope.MarshalData(content)
 		if err != nil {
 			return nil, err
 		}
-		message := &tss.Message{
-			From: info.DeviceNumber,
-			To:   id,
-			Data: string(bytes),
-		}
+		message := info.envelope.NewMessage(info.DeviceNumber, id, 2, bytes)
 		out[id] = message
 	}
 	return out, nil
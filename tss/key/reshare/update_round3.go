@@ -1,7 +1,6 @@
 package reshare
 
 import (
-	"encoding/json"
 	"fmt"
 	"math/big"
 
@@ -35,8 +34,11 @@ func (info *RefreshInfo) DKGStep3(msgs []*tss.Message) (*tss.KeyStep3Data, error
 		if msg.To != info.DeviceNumber {
 			return nil, fmt.Errorf("message sending error")
 		}
+		if err := info.envelope.CheckMessage(msg, 2); err != nil {
+			return nil, err
+		}
 		var content tss.KeyStep2Data
-		err := json.Unmarshal([]byte(msg.Data), &content)
+		err := tss.UnmarshalData([]byte(msg.Data), &content)
 		if err != nil {
 			return nil, err
 		}
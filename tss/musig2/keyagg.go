@@ -0,0 +1,217 @@
+package musig2
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	bip340 "github.com/okx/threshold-lib/tss/bip340/sign"
+)
+
+// MuSig2 n-of-n schnorr multi-signature https://github.com/bitcoin/bips/blob/master/bip-0327.mediawiki
+// Public keys are 33 bytes compressed, the aggregate key and signature are BIP-340.
+
+var (
+	curve = secp256k1.S256()
+)
+
+// KeyAggContext aggregate key Q, gacc and tacc track the tweaks
+type KeyAggContext struct {
+	Q       *curves.ECPoint
+	gacc    *big.Int
+	tacc    *big.Int
+	pubKeys [][]byte
+	pk2     []byte
+}
+
+// KeySort sort public keys lexicographically
+func KeySort(pubKeys [][]byte) [][]byte {
+	sorted := make([][]byte, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+// KeyAgg Q = sum(a_i * P_i), the order of pubKeys matters, use KeySort for an order independent key
+func KeyAgg(pubKeys [][]byte) (*KeyAggContext, error) {
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("public keys are empty")
+	}
+	ctx := &KeyAggContext{
+		gacc:    big.NewInt(1),
+		tacc:    big.NewInt(0),
+		pubKeys: pubKeys,
+		pk2:     secondKey(pubKeys),
+	}
+	var Q *curves.ECPoint
+	for i, pk := range pubKeys {
+		P, err := cpoint(pk)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %d: %v", i, err)
+		}
+		Q = addPoints(Q, scalarMult(P, ctx.coefficient(pk)))
+	}
+	if Q == nil {
+		return nil, fmt.Errorf("aggregate key is infinity")
+	}
+	ctx.Q = Q
+	return ctx, nil
+}
+
+// ApplyTweak plain tweak Q' = Q + t*G, x-only tweak Q' = g*Q + t*G, g = -1 if Q has odd Y
+func (ctx *KeyAggContext) ApplyTweak(tweak []byte, xOnly bool) (*KeyAggContext, error) {
+	if len(tweak) != 32 {
+		return nil, fmt.Errorf("tweak length error")
+	}
+	q := curve.N
+	t := new(big.Int).SetBytes(tweak)
+	if t.Cmp(q) >= 0 {
+		return nil, fmt.Errorf("tweak out of range")
+	}
+	g := big.NewInt(1)
+	Q := ctx.Q
+	if xOnly && Q.Y.Bit(0) == 1 {
+		g = big.NewInt(-1)
+		Q = negate(Q)
+	}
+	Q = addPoints(Q, scalarBaseMult(t))
+	if Q == nil {
+		return nil, fmt.Errorf("tweaked key is infinity")
+	}
+	gacc := new(big.Int).Mod(new(big.Int).Mul(g, ctx.gacc), q)
+	tacc := new(big.Int).Mul(g, ctx.tacc)
+	tacc.Add(tacc, t)
+	return &KeyAggContext{
+		Q:       Q,
+		gacc:    gacc,
+		tacc:    tacc.Mod(tacc, q),
+		pubKeys: ctx.pubKeys,
+		pk2:     ctx.pk2,
+	}, nil
+}
+
+// XOnlyPublicKey 32 bytes aggregate key for BIP-340 verify
+func (ctx *KeyAggContext) XOnlyPublicKey() []byte {
+	return ctx.Q.X.FillBytes(make([]byte, 32))
+}
+
+// PublicKey 33 bytes compressed aggregate key
+func (ctx *KeyAggContext) PublicKey() []byte {
+	return cbytes(ctx.Q)
+}
+
+// coefficient a_i = 1 for the second distinct key, otherwise hash_KeyAgg coefficient(L || pk)
+func (ctx *KeyAggContext) coefficient(pk []byte) *big.Int {
+	if bytes.Equal(pk, ctx.pk2) {
+		return big.NewInt(1)
+	}
+	var list []byte
+	for _, p := range ctx.pubKeys {
+		list = append(list, p...)
+	}
+	L := bip340.TaggedHash("KeyAgg list", list)
+	a := new(big.Int).SetBytes(bip340.TaggedHash("KeyAgg coefficient", L, pk))
+	return a.Mod(a, curve.N)
+}
+
+func (ctx *KeyAggContext) has(pk []byte) bool {
+	for _, p := range ctx.pubKeys {
+		if bytes.Equal(p, pk) {
+			return true
+		}
+	}
+	return false
+}
+
+// secondKey first key different from pubKeys[0], 33 zero bytes if all keys are the same
+func secondKey(pubKeys [][]byte) []byte {
+	for _, pk := range pubKeys[1:] {
+		if !bytes.Equal(pk, pubKeys[0]) {
+			return pk
+		}
+	}
+	return make([]byte, 33)
+}
+
+// cpoint 33 bytes compressed point
+func cpoint(b []byte) (*curves.ECPoint, error) {
+	if len(b) != 33 || (b[0] != 2 && b[0] != 3) {
+		return nil, fmt.Errorf("point encoding error")
+	}
+	P, err := bip340.LiftX(new(big.Int).SetBytes(b[1:]))
+	if err != nil {
+		return nil, err
+	}
+	if b[0] == 3 {
+		P = negate(P)
+	}
+	return P, nil
+}
+
+// cpointExt 33 zero bytes is infinity, returned as nil
+func cpointExt(b []byte) (*curves.ECPoint, error) {
+	if bytes.Equal(b, make([]byte, 33)) {
+		return nil, nil
+	}
+	return cpoint(b)
+}
+
+func cbytes(P *curves.ECPoint) []byte {
+	prefix := byte(2)
+	if P.Y.Bit(0) == 1 {
+		prefix = 3
+	}
+	return append([]byte{prefix}, P.X.FillBytes(make([]byte, 32))...)
+}
+
+// cbytesExt infinity is 33 zero bytes
+func cbytesExt(P *curves.ECPoint) []byte {
+	if P == nil {
+		return make([]byte, 33)
+	}
+	return cbytes(P)
+}
+
+// addPoints nil is infinity
+func addPoints(P1, P2 *curves.ECPoint) *curves.ECPoint {
+	if P1 == nil {
+		return P2
+	}
+	if P2 == nil {
+		return P1
+	}
+	x, y := curve.Add(P1.X, P1.Y, P2.X, P2.Y)
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil
+	}
+	return &curves.ECPoint{Curve: curve, X: x, Y: y}
+}
+
+// scalarMult nil for infinity
+func scalarMult(P *curves.ECPoint, k *big.Int) *curves.ECPoint {
+	if P == nil {
+		return nil
+	}
+	k = new(big.Int).Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil
+	}
+	return P.ScalarMult(k)
+}
+
+func scalarBaseMult(k *big.Int) *curves.ECPoint {
+	k = new(big.Int).Mod(k, curve.N)
+	if k.Sign() == 0 {
+		return nil
+	}
+	return curves.ScalarToPoint(curve, k)
+}
+
+func negate(P *curves.ECPoint) *curves.ECPoint {
+	return &curves.ECPoint{Curve: curve, X: P.X, Y: new(big.Int).Sub(curve.P, P.Y)}
+}
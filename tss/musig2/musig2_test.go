@@ -0,0 +1,429 @@
+package musig2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/okx/threshold-lib/crypto"
+	"github.com/okx/threshold-lib/crypto/curves"
+	bip340 "github.com/okx/threshold-lib/tss/bip340/sign"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// BIP-327 key_agg_vectors.json
+func TestKeyAggVectors(t *testing.T) {
+	pubKeys := [][]byte{
+		decode(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		decode(t, "03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"),
+		decode(t, "023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66"),
+		decode(t, "020000000000000000000000000000000000000000000000000000000000000005"),
+	}
+	valid := []struct {
+		indices  []int
+		expected string
+	}{
+		{[]int{0, 1, 2}, "90539EEDE565F5D054F32CC0C220126889ED1E5D193BAF15AEF344FE59D4610C"},
+		{[]int{2, 1, 0}, "6204DE8B083426DC6EAF9502D27024D53FC826BF7D2012148A0575435DF54B2B"},
+		{[]int{0, 0, 0}, "B436E3BAD62B8CD409969A224731C193D051162D8C5AE8B109306127DA3AA935"},
+		{[]int{0, 0, 1, 1}, "69BC22BFA5D106306E48A20679DE1D7389386124D07571D0D872686028C26A3E"},
+	}
+	for _, v := range valid {
+		var keys [][]byte
+		for _, i := range v.indices {
+			keys = append(keys, pubKeys[i])
+		}
+		ctx, err := KeyAgg(keys)
+		require.NoError(t, err)
+		require.Equal(t, v.expected, strings.ToUpper(hex.EncodeToString(ctx.XOnlyPublicKey())))
+	}
+	// not on curve
+	_, err := KeyAgg([][]byte{pubKeys[0], pubKeys[3]})
+	require.Error(t, err)
+}
+
+// BIP-327 key_sort_vectors.json
+func TestKeySortVectors(t *testing.T) {
+	pubKeys := [][]byte{
+		decode(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8"),
+		decode(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		decode(t, "03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"),
+		decode(t, "023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66"),
+		decode(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EFF"),
+		decode(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8"),
+	}
+	expected := [][]byte{
+		decode(t, "023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66"),
+		decode(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8"),
+		decode(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EB8"),
+		decode(t, "02DD308AFEC5777E13121FA72B9CC1B7CC0139715309B086C960E18FD969774EFF"),
+		decode(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		decode(t, "03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"),
+	}
+	require.Equal(t, expected, KeySort(pubKeys))
+}
+
+// BIP-327 nonce_gen_vectors.json inputs, expected values from the reference nonce_gen_internal
+func TestNonceGenVectors(t *testing.T) {
+	sk := new(big.Int).SetBytes(decode(t, "0202020202020202020202020202020202020202020202020202020202020202"))
+	pubKey := decode(t, "024D4B6CD1361032CA9BD2AEB9D900AA4D45D9EAD80AC9423374C451A7254D0766")
+	aggPubKey := decode(t, "0707070707070707070707070707070707070707070707070707070707070707")
+	extraIn := decode(t, "0808080808080808080808080808080808080808080808080808080808080808")
+	vectors := []struct {
+		random    []byte
+		sk        *big.Int
+		pubKey    []byte
+		aggPubKey []byte
+		message   []byte
+		extraIn   []byte
+		secNonce  string
+		pubNonce  string
+	}{
+		{
+			make([]byte, 32), sk, pubKey, aggPubKey,
+			decode(t, "0101010101010101010101010101010101010101010101010101010101010101"), extraIn,
+			"227243DCB40EF2A13A981DB188FA433717B506BDFA14B1AE47D5DC027C9C3B9EF2370B2AD206E724243215137C86365699361126991E6FEC816845F837BDDAC3",
+			"020A25526B002885996358B3EE5092F2F2F197393E59C06CDFC7A92A91931E20C3024C9FECC6795D5D761F96968D871A1F3BAC605F6ECC4E52E1EBF49E1FF9208AD0",
+		},
+		// empty message is not the same as no message
+		{
+			make([]byte, 32), sk, pubKey, aggPubKey, []byte{}, extraIn,
+			"CD0F47FE471D6788FF3243F47345EA0A179AEF69476BE8348322EF39C2723318870C2065AFB52DEDF02BF4FDBF6D2F442E608692F50C2374C08FFFE57042A61C",
+			"0283D01F92F2B6A8540867AD8C7E725E420BBE27D8A949B67F1602219A3218EDE3034EDB05E0FCC6A1AF733DA418D47F863C874ED150B0F92821BF38B9C1835958E5",
+		},
+		// 38 bytes message
+		{
+			make([]byte, 32), sk, pubKey, aggPubKey,
+			decode(t, "2626262626262626262626262626262626262626262626262626262626262626262626262626"), extraIn,
+			"011F8BC60EF061DEEF4D72A0A87200D9994B3F0CD9867910085C38D5366E3E6B9FF03BC0124E56B24069E91EC3F162378983F194E8BD0ED89BE3059649EAE262",
+			"036C9E0851CCC4C93589C870EF67ECAD52CF883FBAFAA27C1D980199B33407D7D3023AFDDECC096613B4A8B3288FC7A2918F5014674E9F8A80A24572D68CA5506AA8",
+		},
+		// optional arguments absent
+		{
+			decode(t, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"), nil,
+			decode(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"), nil, nil, nil,
+			"335426655698B8E042C0477E59531110F7150F753679E46B7B1CEC3DFF6E9A5C8E81557CE9BF2232CB3B360B04DB90F85CE236B5CA82847672A6287757A477A9",
+			"02BD937FFC8CA1C3809F72A913D5899BC34F8832319D8067FF2DC2B39D9CA165740296DE875DB2BC96223B34AD4340C3F004A52DEAA74694050334ADD326A2D81A73",
+		},
+	}
+	for _, v := range vectors {
+		secNonce, pubNonce, err := nonceGen(v.random, v.sk, v.pubKey, v.aggPubKey, v.message, v.extraIn)
+		require.NoError(t, err)
+		k := append(secNonce.k1.FillBytes(make([]byte, 32)), secNonce.k2.FillBytes(make([]byte, 32))...)
+		require.Equal(t, v.secNonce, strings.ToUpper(hex.EncodeToString(k)))
+		require.Equal(t, v.pubKey, secNonce.pubKey)
+		require.Equal(t, v.pubNonce, strings.ToUpper(hex.EncodeToString(pubNonce)))
+	}
+}
+
+// BIP-327 nonce_agg_vectors.json
+func TestNonceAggVectors(t *testing.T) {
+	pubNonces := [][]byte{
+		decode(t, "020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E66603BA47FBC1834437B3212E89A84D8425E7BF12E0245D98262268EBDCB385D50641"),
+		decode(t, "03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833"),
+		decode(t, "020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E6660279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"),
+		decode(t, "03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60379BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"),
+		decode(t, "04FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833"),
+		decode(t, "03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B831"),
+		decode(t, "03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A602FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30"),
+	}
+	valid := []struct {
+		indices  []int
+		expected string
+	}{
+		{[]int{0, 1}, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B024725377345BDE0E9C33AF3C43C0A29A9249F2F2956FA8CFEB55C8573D0262DC8"},
+		// sum of second points is infinity
+		{[]int{2, 3}, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B000000000000000000000000000000000000000000000000000000000000000000"},
+	}
+	for _, v := range valid {
+		aggNonce, err := NonceAgg([][]byte{pubNonces[v.indices[0]], pubNonces[v.indices[1]]})
+		require.NoError(t, err)
+		require.Equal(t, v.expected, strings.ToUpper(hex.EncodeToString(aggNonce)))
+	}
+	// invalid prefix, x not on curve, x exceeds field size
+	for _, i := range []int{4, 5, 6} {
+		_, err := NonceAgg([][]byte{pubNonces[0], pubNonces[i]})
+		require.Error(t, err)
+	}
+}
+
+// BIP-327 sign_verify_vectors.json
+func TestSignVerifyVectors(t *testing.T) {
+	sk := new(big.Int).SetBytes(decode(t, "7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671"))
+	pubKeys := [][]byte{
+		decode(t, "03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9"),
+		decode(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		decode(t, "02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA661"),
+		decode(t, "020000000000000000000000000000000000000000000000000000000000000007"),
+	}
+	secNonce := decode(t, "508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9")
+	pubNonces := [][]byte{
+		decode(t, "0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480"),
+		decode(t, "0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F817980279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798"),
+		decode(t, "032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE9303E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046"),
+		decode(t, "0237C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0387BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480"),
+	}
+	aggNonces := [][]byte{
+		decode(t, "028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9"),
+		decode(t, "000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"),
+		decode(t, "048465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9"),
+		decode(t, "028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61020000000000000000000000000000000000000000000000000000000000000009"),
+		decode(t, "028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD6102FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30"),
+	}
+	messages := [][]byte{
+		decode(t, "F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF"),
+		{},
+		decode(t, "2626262626262626262626262626262626262626262626262626262626262626262626262626"),
+	}
+	valid := []struct {
+		keyIndices   []int
+		nonceIndices []int
+		aggNonce     int
+		message      int
+		signer       int
+		expected     string
+	}{
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 0, 0, "012ABBCB52B3016AC03AD82395A1A415C48B93DEF78718E62A7A90052FE224FB"},
+		{[]int{1, 0, 2}, []int{1, 0, 2}, 0, 0, 1, "9FF2F7AAA856150CC8819254218D3ADEEB0535269051897724F9DB3789513A52"},
+		{[]int{1, 2, 0}, []int{1, 2, 0}, 0, 0, 2, "FA23C359F6FAC4E7796BB93BC9F0532A95468C539BA20FF86D7C76ED92227900"},
+		// aggregate nonce is infinity
+		{[]int{0, 1}, []int{0, 3}, 1, 0, 0, "AE386064B26105404798F75DE2EB9AF5EDA5387B064B83D049CB7C5E08879531"},
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 1, 0, "D7D63FFD644CCDA4E62BC2BC0B1D02DD32A1DC3030E155195810231D1037D82D"},
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, 2, 0, "E184351828DA5094A97C79CABDAAA0BFB87608C32E8829A4DF5340A6F243B78C"},
+	}
+	for _, v := range valid {
+		var keys, nonces [][]byte
+		for _, i := range v.keyIndices {
+			keys = append(keys, pubKeys[i])
+		}
+		for _, i := range v.nonceIndices {
+			nonces = append(nonces, pubNonces[i])
+		}
+		ctx, err := KeyAgg(keys)
+		require.NoError(t, err)
+		aggNonce, err := NonceAgg(nonces)
+		require.NoError(t, err)
+		require.Equal(t, aggNonces[v.aggNonce], aggNonce)
+		session, err := NewSession(ctx, aggNonce, messages[v.message])
+		require.NoError(t, err)
+		psig, err := session.Sign(vectorSecNonce(secNonce), sk)
+		require.NoError(t, err)
+		require.Equal(t, v.expected, strings.ToUpper(hex.EncodeToString(psig.FillBytes(make([]byte, 32)))))
+		require.True(t, session.PartialSigVerify(psig, nonces[v.signer], keys[v.signer]))
+		// wrong signature, wrong signer
+		require.False(t, session.PartialSigVerify(new(big.Int).Sub(curve.N, psig), nonces[v.signer], keys[v.signer]))
+		require.False(t, session.PartialSigVerify(psig, nonces[(v.signer+1)%len(keys)], keys[(v.signer+1)%len(keys)]))
+	}
+
+	ctx, err := KeyAgg([][]byte{pubKeys[1], pubKeys[2]})
+	require.NoError(t, err)
+	session, err := NewSession(ctx, aggNonces[0], messages[0])
+	require.NoError(t, err)
+	// signer is not in the keys
+	_, err = session.Sign(vectorSecNonce(secNonce), sk)
+	require.Error(t, err)
+	// invalid public key
+	_, err = KeyAgg([][]byte{pubKeys[1], pubKeys[0], pubKeys[3]})
+	require.Error(t, err)
+	// invalid aggregate nonce prefix, x not on curve, x exceeds field size
+	ctx, err = KeyAgg([][]byte{pubKeys[1], pubKeys[2], pubKeys[0]})
+	require.NoError(t, err)
+	for _, i := range []int{2, 3, 4} {
+		_, err = NewSession(ctx, aggNonces[i], messages[0])
+		require.Error(t, err)
+	}
+	// secret nonce of another key
+	session, err = NewSession(ctx, aggNonces[0], messages[0])
+	require.NoError(t, err)
+	wrongKey := vectorSecNonce(secNonce)
+	wrongKey.pubKey = pubKeys[1]
+	_, err = session.Sign(wrongKey, sk)
+	require.Error(t, err)
+}
+
+// BIP-327 tweak_vectors.json
+func TestTweakVectors(t *testing.T) {
+	sk := new(big.Int).SetBytes(decode(t, "7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671"))
+	pubKeys := [][]byte{
+		decode(t, "03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9"),
+		decode(t, "02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9"),
+		decode(t, "02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659"),
+	}
+	secNonce := decode(t, "508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9")
+	aggNonce := decode(t, "028465FCF0BBDBCF443AABCCE533D42B4B5A10966AC09A49655E8C42DAAB8FCD61037496A3CC86926D452CAFCFD55D25972CA1675D549310DE296BFF42F72EEEA8C9")
+	tweaks := [][]byte{
+		decode(t, "E8F791FF9225A2AF0102AFFF4A9A723D9612A682A25EBE79802B263CDFCD83BB"),
+		decode(t, "AE2EA797CC0FE72AC5B97B97F3C6957D7E4199A167A58EB08BCAFFDA70AC0455"),
+		decode(t, "F52ECBC565B3D8BEA2DFD5B75A4F457E54369809322E4120831626F290FA87E0"),
+		decode(t, "1969AD73CC177FA0B4FCED6DF1F7BF9907E665FDE9BA196A74FED0A3CF5AEF9D"),
+		decode(t, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141"),
+	}
+	message := decode(t, "F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF")
+	valid := []struct {
+		tweakIndices []int
+		xOnly        []bool
+		expected     string
+	}{
+		{[]int{0}, []bool{true}, "E28A5C66E61E178C2BA19DB77B6CF9F7E2F0F56C17918CD13135E60CC848FE91"},
+		{[]int{0}, []bool{false}, "38B0767798252F21BF5702C48028B095428320F73A4B14DB1E25DE58543D2D2D"},
+		{[]int{0, 1}, []bool{false, true}, "408A0A21C4A0F5DACAF9646AD6EB6FECD7F7A11F03ED1F48DFFF2185BC2C2408"},
+		{[]int{0, 1, 2, 3}, []bool{false, false, true, true}, "45ABD206E61E3DF2EC9E264A6FEC8292141A633C28586388235541F9ADE75435"},
+		{[]int{0, 1, 2, 3}, []bool{true, false, true, false}, "B255FDCAC27B40C7CE7848E2D3B7BF5EA0ED756DA81565AC804CCCA3E1D5D239"},
+	}
+	// key and nonce indices [1, 2, 0], signer 2
+	keys := [][]byte{pubKeys[1], pubKeys[2], pubKeys[0]}
+	for _, v := range valid {
+		ctx, err := KeyAgg(keys)
+		require.NoError(t, err)
+		for i, index := range v.tweakIndices {
+			ctx, err = ctx.ApplyTweak(tweaks[index], v.xOnly[i])
+			require.NoError(t, err)
+		}
+		session, err := NewSession(ctx, aggNonce, message)
+		require.NoError(t, err)
+		psig, err := session.Sign(vectorSecNonce(secNonce), sk)
+		require.NoError(t, err)
+		require.Equal(t, v.expected, strings.ToUpper(hex.EncodeToString(psig.FillBytes(make([]byte, 32)))))
+	}
+	// tweak out of range
+	ctx, err := KeyAgg(keys)
+	require.NoError(t, err)
+	_, err = ctx.ApplyTweak(tweaks[4], true)
+	require.Error(t, err)
+}
+
+// BIP-327 sig_agg_vectors.json
+func TestSigAggVectors(t *testing.T) {
+	pubKeys := [][]byte{
+		decode(t, "03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9"),
+		decode(t, "02D2DC6F5DF7C56ACF38C7FA0AE7A759AE30E19B37359DFDE015872324C7EF6E05"),
+	}
+	pubNonces := [][]byte{
+		decode(t, "036E5EE6E28824029FEA3E8A9DDD2C8483F5AF98F7177C3AF3CB6F47CAF8D94AE902DBA67E4A1F3680826172DA15AFB1A8CA85C7C5CC88900905C8DC8C328511B53E"),
+		decode(t, "03E4F798DA48A76EEC1C9CC5AB7A880FFBA201A5F064E627EC9CB0031D1D58FC5103E06180315C5A522B7EC7C08B69DCD721C313C940819296D0A7AB8E8795AC1F00"),
+	}
+	psigs := []*big.Int{
+		new(big.Int).SetBytes(decode(t, "B15D2CD3C3D22B04DAE438CE653F6B4ECF042F42CFDED7C41B64AAF9B4AF53FB")),
+		new(big.Int).SetBytes(decode(t, "6193D6AC61B354E9105BBDC8937A3454A6D705B6D57322A5A472A02CE99FCB64")),
+		new(big.Int).SetBytes(decode(t, "FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141")),
+	}
+	message := decode(t, "599C67EA410D005B9DA90817CF03ED3B1C868E4DA4EDF00A5880B0082C237869")
+
+	ctx, err := KeyAgg(pubKeys)
+	require.NoError(t, err)
+	aggNonce, err := NonceAgg(pubNonces)
+	require.NoError(t, err)
+	require.Equal(t, "0341432722C5CD0268D829C702CF0D1CBCE57033EED201FD335191385227C3210C03D377F2D258B64AADC0E16F26462323D701D286046A2EA93365656AFD9875982B",
+		strings.ToUpper(hex.EncodeToString(aggNonce)))
+	session, err := NewSession(ctx, aggNonce, message)
+	require.NoError(t, err)
+	signature, err := session.PartialSigAgg(psigs[:2])
+	require.NoError(t, err)
+	require.Equal(t, "041DA22223CE65C92C9A0D6C2CAC828AAF1EEE56304FEC371DDF91EBB2B9EF0912F1038025857FEDEB3FF696F8B99FA4BB2C5812F6095A2E0004EC99CE18DE1E",
+		strings.ToUpper(hex.EncodeToString(signature)))
+	require.True(t, bip340.Verify(ctx.XOnlyPublicKey(), message, signature))
+	// partial signature exceeds group size
+	_, err = session.PartialSigAgg([]*big.Int{psigs[0], psigs[2]})
+	require.Error(t, err)
+}
+
+// vectorSecNonce secnonce k1 || k2 || pk, a new copy for every signature
+func vectorSecNonce(secNonce []byte) *SecNonce {
+	return &SecNonce{
+		k1:     new(big.Int).SetBytes(secNonce[:32]),
+		k2:     new(big.Int).SetBytes(secNonce[32:64]),
+		pubKey: secNonce[64:],
+	}
+}
+
+func TestMuSig2(t *testing.T) {
+	hash := sha256.Sum256([]byte("hello"))
+	message := hash[:]
+	tweak := sha256.Sum256([]byte("tweak"))
+	for i := 0; i < 4; i++ {
+		sks, pubKeys := keys(3)
+		ctx, err := KeyAgg(KeySort(pubKeys))
+		require.NoError(t, err)
+		sign(t, ctx, sks, pubKeys, message)
+
+		plain, err := ctx.ApplyTweak(tweak[:], false)
+		require.NoError(t, err)
+		xOnly, err := plain.ApplyTweak(tweak[:], true)
+		require.NoError(t, err)
+		sign(t, xOnly, sks, pubKeys, message)
+	}
+}
+
+func TestMuSig2PartialSig(t *testing.T) {
+	sks, pubKeys := keys(2)
+	ctx, err := KeyAgg(pubKeys)
+	require.NoError(t, err)
+	secNonce1, pubNonce1, err := NonceGen(sks[0], pubKeys[0], ctx.XOnlyPublicKey(), []byte("m"), nil)
+	require.NoError(t, err)
+	_, pubNonce2, err := NonceGen(nil, pubKeys[1], nil, nil, nil)
+	require.NoError(t, err)
+	aggNonce, err := NonceAgg([][]byte{pubNonce1, pubNonce2})
+	require.NoError(t, err)
+	session, err := NewSession(ctx, aggNonce, []byte("m"))
+	require.NoError(t, err)
+
+	// nonce of another key
+	_, err = session.Sign(secNonce1, sks[1])
+	require.Error(t, err)
+	// nonce is single-use
+	_, err = session.Sign(secNonce1, sks[0])
+	require.Error(t, err)
+
+	secNonce1, pubNonce1, err = NonceGen(sks[0], pubKeys[0], nil, nil, nil)
+	require.NoError(t, err)
+	aggNonce, err = NonceAgg([][]byte{pubNonce1, pubNonce2})
+	require.NoError(t, err)
+	session, err = NewSession(ctx, aggNonce, []byte("m"))
+	require.NoError(t, err)
+	psig, err := session.Sign(secNonce1, sks[0])
+	require.NoError(t, err)
+	require.True(t, session.PartialSigVerify(psig, pubNonce1, pubKeys[0]))
+	require.False(t, session.PartialSigVerify(psig, pubNonce2, pubKeys[1]))
+	require.False(t, session.PartialSigVerify(new(big.Int).Add(psig, big.NewInt(1)), pubNonce1, pubKeys[0]))
+}
+
+func sign(t *testing.T, ctx *KeyAggContext, sks []*big.Int, pubKeys [][]byte, message []byte) {
+	secNonces := make([]*SecNonce, len(sks))
+	pubNonces := make([][]byte, len(sks))
+	for i, sk := range sks {
+		var err error
+		secNonces[i], pubNonces[i], err = NonceGen(sk, pubKeys[i], ctx.XOnlyPublicKey(), message, nil)
+		require.NoError(t, err)
+	}
+	aggNonce, err := NonceAgg(pubNonces)
+	require.NoError(t, err)
+	session, err := NewSession(ctx, aggNonce, message)
+	require.NoError(t, err)
+	psigs := make([]*big.Int, len(sks))
+	for i, sk := range sks {
+		psigs[i], err = session.Sign(secNonces[i], sk)
+		require.NoError(t, err)
+		require.True(t, session.PartialSigVerify(psigs[i], pubNonces[i], pubKeys[i]))
+	}
+	signature, err := session.PartialSigAgg(psigs)
+	require.NoError(t, err)
+	require.True(t, bip340.Verify(ctx.XOnlyPublicKey(), message, signature))
+}
+
+func keys(n int) ([]*big.Int, [][]byte) {
+	sks := make([]*big.Int, n)
+	pubKeys := make([][]byte, n)
+	for i := range sks {
+		sks[i] = crypto.RandomNum(curve.N)
+		pubKeys[i] = cbytes(curves.ScalarToPoint(curve, sks[i]))
+	}
+	return sks, pubKeys
+}
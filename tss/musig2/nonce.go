@@ -0,0 +1,121 @@
+package musig2
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	bip340 "github.com/okx/threshold-lib/tss/bip340/sign"
+)
+
+// SecNonce secret nonce k1, k2, single-use, must never be exported or reused
+type SecNonce struct {
+	k1     *big.Int
+	k2     *big.Int
+	pubKey []byte
+
+	lock sync.Mutex
+}
+
+// NonceGen sk, aggPubKey, message and extraIn are optional, pubNonce is 66 bytes cbytes(R1) || cbytes(R2)
+func NonceGen(sk *big.Int, pubKey, aggPubKey, message, extraIn []byte) (*SecNonce, []byte, error) {
+	random := make([]byte, 32)
+	_, err := rand.Read(random)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nonceGen(random, sk, pubKey, aggPubKey, message, extraIn)
+}
+
+func nonceGen(random []byte, sk *big.Int, pubKey, aggPubKey, message, extraIn []byte) (*SecNonce, []byte, error) {
+	if len(pubKey) != 33 {
+		return nil, nil, fmt.Errorf("public key length error")
+	}
+	if len(aggPubKey) != 0 && len(aggPubKey) != 32 {
+		return nil, nil, fmt.Errorf("aggregate public key length error")
+	}
+	// rand = sk xor hash_MuSig/aux(rand')
+	if sk != nil {
+		aux := bip340.TaggedHash("MuSig/aux", random)
+		skBytes := sk.FillBytes(make([]byte, 32))
+		random = make([]byte, 32)
+		for i := range random {
+			random[i] = skBytes[i] ^ aux[i]
+		}
+	}
+	var msgPrefixed []byte
+	if message == nil {
+		msgPrefixed = []byte{0}
+	} else {
+		msgPrefixed = append([]byte{1}, uint64Bytes(uint64(len(message)))...)
+		msgPrefixed = append(msgPrefixed, message...)
+	}
+	k := make([]*big.Int, 2)
+	for i := range k {
+		ki := new(big.Int).SetBytes(bip340.TaggedHash("MuSig/nonce",
+			random,
+			[]byte{byte(len(pubKey))}, pubKey,
+			[]byte{byte(len(aggPubKey))}, aggPubKey,
+			msgPrefixed,
+			uint32Bytes(uint32(len(extraIn))), extraIn,
+			[]byte{byte(i)},
+		))
+		k[i] = ki.Mod(ki, curve.N)
+		if k[i].Sign() == 0 {
+			return nil, nil, fmt.Errorf("nonce is zero")
+		}
+	}
+	pubNonce := append(cbytes(curves.ScalarToPoint(curve, k[0])), cbytes(curves.ScalarToPoint(curve, k[1]))...)
+	return &SecNonce{k1: k[0], k2: k[1], pubKey: pubKey}, pubNonce, nil
+}
+
+// NonceAgg aggNonce = cbytes_ext(sum(R1_i)) || cbytes_ext(sum(R2_i))
+func NonceAgg(pubNonces [][]byte) ([]byte, error) {
+	if len(pubNonces) == 0 {
+		return nil, fmt.Errorf("public nonces are empty")
+	}
+	var R1, R2 *curves.ECPoint
+	for i, pubNonce := range pubNonces {
+		if len(pubNonce) != 66 {
+			return nil, fmt.Errorf("invalid public nonce %d", i)
+		}
+		Ri1, err := cpoint(pubNonce[:33])
+		if err != nil {
+			return nil, fmt.Errorf("invalid public nonce %d", i)
+		}
+		Ri2, err := cpoint(pubNonce[33:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid public nonce %d", i)
+		}
+		R1 = addPoints(R1, Ri1)
+		R2 = addPoints(R2, Ri2)
+	}
+	return append(cbytesExt(R1), cbytesExt(R2)...), nil
+}
+
+// take return the nonce and clear it, a nonce signs only once
+func (secNonce *SecNonce) take() (*big.Int, *big.Int, error) {
+	secNonce.lock.Lock()
+	defer secNonce.lock.Unlock()
+	if secNonce.k1 == nil || secNonce.k2 == nil {
+		return nil, nil, fmt.Errorf("secret nonce already used")
+	}
+	k1, k2 := secNonce.k1, secNonce.k2
+	secNonce.k1, secNonce.k2 = nil, nil
+	return k1, k2, nil
+}
+
+func uint32Bytes(i uint32) []byte {
+	bytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(bytes, i)
+	return bytes
+}
+
+func uint64Bytes(i uint64) []byte {
+	bytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(bytes, i)
+	return bytes
+}
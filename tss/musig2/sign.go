@@ -0,0 +1,140 @@
+package musig2
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+	bip340 "github.com/okx/threshold-lib/tss/bip340/sign"
+)
+
+// Session signing session of one message with the aggregate nonce
+type Session struct {
+	ctx     *KeyAggContext
+	message []byte
+	b       *big.Int        // nonce coefficient
+	R       *curves.ECPoint // final nonce
+	e       *big.Int        // challenge
+}
+
+// NewSession b = hash_MuSig/noncecoef(aggNonce || Q.x || m), R = R1 + b*R2, e = hash_BIP0340/challenge(R.x || Q.x || m)
+func NewSession(ctx *KeyAggContext, aggNonce, message []byte) (*Session, error) {
+	if ctx == nil || len(aggNonce) != 66 {
+		return nil, fmt.Errorf("session params error")
+	}
+	R1, err := cpointExt(aggNonce[:33])
+	if err != nil {
+		return nil, err
+	}
+	R2, err := cpointExt(aggNonce[33:])
+	if err != nil {
+		return nil, err
+	}
+	q := curve.N
+	b := new(big.Int).SetBytes(bip340.TaggedHash("MuSig/noncecoef", aggNonce, ctx.XOnlyPublicKey(), message))
+	b.Mod(b, q)
+	R := addPoints(R1, scalarMult(R2, b))
+	if R == nil {
+		R = curves.ScalarToPoint(curve, big.NewInt(1))
+	}
+	e := new(big.Int).SetBytes(bip340.TaggedHash("BIP0340/challenge", R.X.FillBytes(make([]byte, 32)), ctx.XOnlyPublicKey(), message))
+	return &Session{
+		ctx:     ctx,
+		message: message,
+		b:       b,
+		R:       R,
+		e:       e.Mod(e, q),
+	}, nil
+}
+
+// Sign partial signature s = k1 + b*k2 + e*a*g*gacc*sk, secNonce is cleared
+func (s *Session) Sign(secNonce *SecNonce, sk *big.Int) (*big.Int, error) {
+	if secNonce == nil || sk == nil {
+		return nil, fmt.Errorf("sign params error")
+	}
+	k1, k2, err := secNonce.take()
+	if err != nil {
+		return nil, err
+	}
+	q := curve.N
+	if sk.Sign() <= 0 || sk.Cmp(q) >= 0 {
+		return nil, fmt.Errorf("secret key out of range")
+	}
+	if s.R.Y.Bit(0) == 1 {
+		k1 = new(big.Int).Sub(q, k1)
+		k2 = new(big.Int).Sub(q, k2)
+	}
+	pk := cbytes(curves.ScalarToPoint(curve, sk))
+	if !bytes.Equal(pk, secNonce.pubKey) {
+		return nil, fmt.Errorf("public key does not match nonce")
+	}
+	if !s.ctx.has(pk) {
+		return nil, fmt.Errorf("public key is not a signer")
+	}
+	d := new(big.Int).Mul(s.keyCoefficient(pk), sk)
+	sig := new(big.Int).Mul(s.b, k2)
+	sig.Add(sig, k1)
+	sig.Add(sig, d.Mul(d, s.e))
+	return sig.Mod(sig, q), nil
+}
+
+// PartialSigVerify s*G = Re + e*a*g*gacc*P, Re = R1 + b*R2 negated if R has odd Y
+func (s *Session) PartialSigVerify(psig *big.Int, pubNonce, pubKey []byte) bool {
+	q := curve.N
+	if psig == nil || psig.Sign() < 0 || psig.Cmp(q) >= 0 || len(pubNonce) != 66 || !s.ctx.has(pubKey) {
+		return false
+	}
+	R1, err := cpoint(pubNonce[:33])
+	if err != nil {
+		return false
+	}
+	R2, err := cpoint(pubNonce[33:])
+	if err != nil {
+		return false
+	}
+	P, err := cpoint(pubKey)
+	if err != nil {
+		return false
+	}
+	Re := addPoints(R1, scalarMult(R2, s.b))
+	if Re != nil && s.R.Y.Bit(0) == 1 {
+		Re = negate(Re)
+	}
+	coef := new(big.Int).Mul(s.e, s.keyCoefficient(pubKey))
+	expected := addPoints(Re, scalarMult(P, coef))
+	actual := scalarBaseMult(psig)
+	if expected == nil || actual == nil {
+		return expected == nil && actual == nil
+	}
+	return actual.Equals(expected)
+}
+
+// PartialSigAgg s = sum(s_i) + e*g*tacc, return 64 bytes BIP-340 signature R.x || s
+func (s *Session) PartialSigAgg(psigs []*big.Int) ([]byte, error) {
+	q := curve.N
+	sum := big.NewInt(0)
+	for i, psig := range psigs {
+		if psig == nil || psig.Sign() < 0 || psig.Cmp(q) >= 0 {
+			return nil, fmt.Errorf("invalid partial signature %d", i)
+		}
+		sum.Add(sum, psig)
+	}
+	t := new(big.Int).Mul(s.e, s.ctx.tacc)
+	if s.ctx.Q.Y.Bit(0) == 1 {
+		t.Neg(t)
+	}
+	sum.Add(sum, t)
+	sum.Mod(sum, q)
+	signature := append(s.R.X.FillBytes(make([]byte, 32)), sum.FillBytes(make([]byte, 32))...)
+	return signature, nil
+}
+
+// keyCoefficient a*g*gacc, g = -1 if Q has odd Y
+func (s *Session) keyCoefficient(pk []byte) *big.Int {
+	coef := new(big.Int).Mul(s.ctx.coefficient(pk), s.ctx.gacc)
+	if s.ctx.Q.Y.Bit(0) == 1 {
+		coef.Neg(coef)
+	}
+	return coef.Mod(coef, curve.N)
+}
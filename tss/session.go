@@ -0,0 +1,174 @@
+package tss
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SessionVersion version of exported session state, state of other versions can not be imported
+const SessionVersion byte = 1
+
+// Session state export for stateless servers: state is sealed with AES-256-GCM, version and kind are authenticated.
+// Every export carries a random id, import consumes the id in ReplayStore so a sealed state can be restored only once,
+// a restored nonce is never used twice. Exporting clears the nonce of the in-memory context.
+
+// ReplayStore ids of imported session state, implementations must be safe for concurrent use.
+// Use must return error if the id was used before, the store must survive restart as long as the sealed state exists,
+// e.g. FileReplayList, ReplayList is in-memory only.
+type ReplayStore interface {
+	Use(id string) error
+}
+
+// ReplayList in-memory ReplayStore
+type ReplayList struct {
+	lock sync.Mutex
+	used map[string]struct{}
+}
+
+func NewReplayList() *ReplayList {
+	return &ReplayList{used: make(map[string]struct{})}
+}
+
+func (s *ReplayList) Use(id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.used[id]; ok {
+		return fmt.Errorf("session state %s already imported", id)
+	}
+	s.used[id] = struct{}{}
+	return nil
+}
+
+// FileReplayList file-backed ReplayStore, ids are appended to the file and synced before Use returns
+type FileReplayList struct {
+	lock sync.Mutex
+	file *os.File
+	mem  *ReplayList
+}
+
+// NewFileReplayList load used ids from path, a missing file is an empty list
+func NewFileReplayList(path string) (*FileReplayList, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileReplayList{file: file, mem: NewReplayList()}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if id := scanner.Text(); id != "" {
+			s.mem.used[id] = struct{}{}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("replay list file %s corrupted: %v", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileReplayList) Use(id string) error {
+	if id == "" || strings.ContainsAny(id, "\r\n") {
+		return fmt.Errorf("invalid session state id")
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.mem.Use(id); err != nil {
+		return err
+	}
+	// the id is used in memory even if the write fails, the state can not be restored twice before restart
+	if _, err := s.file.WriteString(id + "\n"); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close close the file
+func (s *FileReplayList) Close() error {
+	return s.file.Close()
+}
+
+type sessionPayload struct {
+	Id    string
+	State json.RawMessage
+}
+
+// SealSession key is 32 bytes, kind names the context type, output is version | nonce | ciphertext
+func SealSession(key []byte, kind string, state interface{}) ([]byte, error) {
+	aead, err := newSessionAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(sessionPayload{Id: hex.EncodeToString(id), State: data})
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append([]byte{SessionVersion}, nonce...)
+	return aead.Seal(out, nonce, plaintext, sessionAD(SessionVersion, kind)), nil
+}
+
+// OpenSession decrypt sealed state of kind into state, the state id is consumed in replay
+func OpenSession(key []byte, kind string, sealed []byte, replay ReplayStore, state interface{}) error {
+	if replay == nil {
+		return fmt.Errorf("replay store is nil")
+	}
+	aead, err := newSessionAEAD(key)
+	if err != nil {
+		return err
+	}
+	if len(sealed) < 1+aead.NonceSize() {
+		return fmt.Errorf("session state length error")
+	}
+	if sealed[0] != SessionVersion {
+		return fmt.Errorf("session state version %d not supported", sealed[0])
+	}
+	nonce := sealed[1 : 1+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, sealed[1+aead.NonceSize():], sessionAD(sealed[0], kind))
+	if err != nil {
+		return fmt.Errorf("session state authentication fail")
+	}
+	var payload sessionPayload
+	err = json.Unmarshal(plaintext, &payload)
+	if err != nil || payload.Id == "" {
+		return fmt.Errorf("session state format error")
+	}
+	err = replay.Use(payload.Id)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload.State, state)
+}
+
+func newSessionAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session key must be 32 bytes")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func sessionAD(version byte, kind string) []byte {
+	return append([]byte{version}, []byte("threshold-lib session "+kind)...)
+}
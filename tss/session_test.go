@@ -0,0 +1,64 @@
+package tss
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testState struct {
+	Round int
+	Nonce string
+}
+
+func TestSession(t *testing.T) {
+	key := make([]byte, 32)
+	sealed, err := SealSession(key, "test", &testState{Round: 2, Nonce: "k"})
+	require.NoError(t, err)
+
+	replay := NewReplayList()
+	var state testState
+	require.NoError(t, OpenSession(key, "test", sealed, replay, &state))
+	require.Equal(t, testState{Round: 2, Nonce: "k"}, state)
+	// a sealed state is imported once
+	require.Error(t, OpenSession(key, "test", sealed, replay, &state))
+
+	// kind, key, version and ciphertext are authenticated
+	require.Error(t, OpenSession(key, "other", sealed, NewReplayList(), &state))
+	wrongKey := make([]byte, 32)
+	wrongKey[0] = 1
+	require.Error(t, OpenSession(wrongKey, "test", sealed, NewReplayList(), &state))
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+	require.Error(t, OpenSession(key, "test", tampered, NewReplayList(), &state))
+	tampered = append([]byte{}, sealed...)
+	tampered[0] = SessionVersion + 1
+	require.Error(t, OpenSession(key, "test", tampered, NewReplayList(), &state))
+
+	require.Error(t, OpenSession(key, "test", sealed, nil, &state))
+	_, err = SealSession(key[:16], "test", &state)
+	require.Error(t, err)
+}
+
+func TestFileReplayList(t *testing.T) {
+	key := make([]byte, 32)
+	sealed, err := SealSession(key, "test", &testState{Round: 2, Nonce: "k"})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "replay")
+	replay, err := NewFileReplayList(path)
+	require.NoError(t, err)
+	var state testState
+	require.NoError(t, OpenSession(key, "test", sealed, replay, &state))
+	require.Error(t, OpenSession(key, "test", sealed, replay, &state))
+	require.NoError(t, replay.Close())
+
+	// used ids survive restart
+	replay, err = NewFileReplayList(path)
+	require.NoError(t, err)
+	defer replay.Close()
+	require.Error(t, OpenSession(key, "test", sealed, replay, &state))
+	require.NoError(t, replay.Use("other"))
+	require.Error(t, replay.Use("a\nb"))
+}
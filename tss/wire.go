@@ -0,0 +1,488 @@
+package tss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"sort"
+
+	"github.com/okx/threshold-lib/crypto/curves"
+)
+
+// WireFormat encoding of round payloads in Message.Data
+type WireFormat int
+
+const (
+	WireJSON   WireFormat = iota // json, default
+	WireBinary                   // typed, versioned, length-prefixed binary, Message.Data holds raw bytes
+)
+
+// WireVersion version of the binary wire format
+const WireVersion = 1
+
+// wireMagic never starts a json document, so both formats are told apart on decode
+const wireMagic = "\x00tw"
+
+var (
+	bigIntType  = reflect.TypeOf(big.Int{})
+	ecPointType = reflect.TypeOf(curves.ECPoint{})
+)
+
+// Check whether format is a known wire format
+func (format WireFormat) Check() error {
+	if format != WireJSON && format != WireBinary {
+		return fmt.Errorf("unknown wire format %d", format)
+	}
+	return nil
+}
+
+// MarshalData encode a round payload with format, incoming payloads are accepted in both formats
+func MarshalData(format WireFormat, v interface{}) ([]byte, error) {
+	switch format {
+	case WireJSON:
+		return json.Marshal(v)
+	case WireBinary:
+		return Marshal(v)
+	}
+	return nil, format.Check()
+}
+
+// UnmarshalData strictly decode a round payload of either wire format,
+// unknown fields and trailing data are rejected
+func UnmarshalData(data []byte, v interface{}) error {
+	if bytes.HasPrefix(data, []byte(wireMagic)) {
+		return Unmarshal(data, v)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(v)
+	if err != nil {
+		return err
+	}
+	if _, err = decoder.Token(); err != io.EOF {
+		return fmt.Errorf("trailing data after payload")
+	}
+	return nil
+}
+
+// Marshal binary wire encoding: magic | version | type name | value.
+// Struct fields are tagged by position and length-prefixed, *big.Int is sign | magnitude,
+// map entries are sorted, so the encoding is canonical.
+func Marshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("marshal nil value")
+		}
+		rv = rv.Elem()
+	}
+	buf := append([]byte(wireMagic), WireVersion)
+	buf = appendBytes(buf, []byte(rv.Type().String()))
+	return encodeValue(buf, rv)
+}
+
+// Unmarshal strictly decode the binary wire encoding into v, which must be a pointer of the encoded type
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("unmarshal into non-pointer")
+	}
+	for rv.Elem().Kind() == reflect.Ptr {
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	rv = rv.Elem()
+	if !bytes.HasPrefix(data, []byte(wireMagic)) {
+		return fmt.Errorf("not a wire payload")
+	}
+	r := &wireReader{buf: data[len(wireMagic):]}
+	version, err := r.take(1)
+	if err != nil {
+		return err
+	}
+	if version[0] != WireVersion {
+		return fmt.Errorf("unsupported wire version %d", version[0])
+	}
+	name, err := r.next()
+	if err != nil {
+		return err
+	}
+	if string(name) != rv.Type().String() {
+		return fmt.Errorf("wire type %q, expected %s", name, rv.Type())
+	}
+	return decodeValue(r.buf, rv)
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], x)]...)
+}
+
+func appendBytes(buf, b []byte) []byte {
+	buf = appendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// appendValue append a length-prefixed value
+func appendValue(buf []byte, v reflect.Value) ([]byte, error) {
+	value, err := encodeValue(nil, v)
+	if err != nil {
+		return nil, err
+	}
+	return appendBytes(buf, value), nil
+}
+
+func encodeValue(buf []byte, v reflect.Value) ([]byte, error) {
+	if (v.Type() == bigIntType || v.Type() == ecPointType) && !v.CanAddr() {
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		v = c
+	}
+	switch v.Type() {
+	case bigIntType:
+		n := v.Addr().Interface().(*big.Int)
+		sign := byte(0)
+		if n.Sign() < 0 {
+			sign = 1
+		}
+		return append(append(buf, sign), n.Bytes()...), nil
+	case ecPointType:
+		p := v.Addr().Interface().(*curves.ECPoint)
+		name := curves.GetCurveName(p.Curve)
+		if name == "" || p.X == nil || p.Y == nil {
+			return nil, fmt.Errorf("marshal point error, curves are not supported")
+		}
+		buf = appendBytes(buf, []byte(name))
+		buf = appendBytes(buf, p.X.Bytes())
+		return appendBytes(buf, p.Y.Bytes()), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return appendUvarint(buf, v.Uint()), nil
+	case reflect.String:
+		return append(buf, v.String()...), nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return append(buf, 0), nil
+		}
+		return encodeValue(append(buf, 1), v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Array {
+				for i := 0; i < v.Len(); i++ {
+					buf = append(buf, byte(v.Index(i).Uint()))
+				}
+				return buf, nil
+			}
+			return append(buf, v.Bytes()...), nil
+		}
+		var err error
+		buf = appendUvarint(buf, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			buf, err = appendValue(buf, v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Map:
+		entries := make([][2][]byte, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := encodeValue(nil, iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			value, err := encodeValue(nil, iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, [2][]byte{key, value})
+		}
+		sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i][0], entries[j][0]) < 0 })
+		buf = appendUvarint(buf, uint64(len(entries)))
+		for _, entry := range entries {
+			buf = appendBytes(appendBytes(buf, entry[0]), entry[1])
+		}
+		return buf, nil
+	case reflect.Struct:
+		var err error
+		for _, tag := range structFields(v.Type()) {
+			buf = appendUvarint(buf, uint64(tag+1))
+			buf, err = appendValue(buf, v.Field(tag))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("wire type %s not supported", v.Type())
+}
+
+// structFields index of the encoded fields, exported and not ignored by json
+func structFields(t reflect.Type) []int {
+	fields := make([]int, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Tag.Get("json") == "-" {
+			continue
+		}
+		fields = append(fields, i)
+	}
+	return fields
+}
+
+type wireReader struct {
+	buf []byte
+}
+
+func (r *wireReader) uvarint() (uint64, error) {
+	x, n := binary.Uvarint(r.buf)
+	if n <= 0 || n != len(appendUvarint(nil, x)) {
+		return 0, fmt.Errorf("invalid varint")
+	}
+	r.buf = r.buf[n:]
+	return x, nil
+}
+
+func (r *wireReader) take(n uint64) ([]byte, error) {
+	if n > uint64(len(r.buf)) {
+		return nil, fmt.Errorf("unexpected end of payload")
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b, nil
+}
+
+// next read a length-prefixed value
+func (r *wireReader) next() ([]byte, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	return r.take(n)
+}
+
+// count read a number of elements, each takes at least one byte
+func (r *wireReader) count() (int, error) {
+	n, err := r.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(r.buf)) {
+		return 0, fmt.Errorf("invalid element count")
+	}
+	return int(n), nil
+}
+
+func decodeBigInt(data []byte) (*big.Int, error) {
+	if len(data) == 0 || data[0] > 1 || (len(data) > 1 && data[1] == 0) || (data[0] == 1 && len(data) == 1) {
+		return nil, fmt.Errorf("invalid big integer")
+	}
+	n := new(big.Int).SetBytes(data[1:])
+	if data[0] == 1 {
+		n.Neg(n)
+	}
+	return n, nil
+}
+
+// decodeValue decode data into v, data must be consumed entirely
+func decodeValue(data []byte, v reflect.Value) error {
+	r := &wireReader{buf: data}
+	switch v.Type() {
+	case bigIntType:
+		n, err := decodeBigInt(data)
+		if err != nil {
+			return err
+		}
+		v.Addr().Interface().(*big.Int).Set(n)
+		return nil
+	case ecPointType:
+		var fields [3][]byte
+		for i := range fields {
+			field, err := r.next()
+			if err != nil {
+				return err
+			}
+			fields[i] = field
+		}
+		if (len(fields[1]) > 0 && fields[1][0] == 0) || (len(fields[2]) > 0 && fields[2][0] == 0) {
+			return fmt.Errorf("invalid point encoding")
+		}
+		curve, ok := curves.GetCurveByName(string(fields[0]))
+		if !ok {
+			return fmt.Errorf("Curve type not supported")
+		}
+		p, err := curves.NewECPoint(curve, new(big.Int).SetBytes(fields[1]), new(big.Int).SetBytes(fields[2]))
+		if err != nil {
+			return err
+		}
+		point := v.Addr().Interface().(*curves.ECPoint)
+		point.Curve, point.X, point.Y = p.Curve, p.X, p.Y
+		return r.done()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		if len(data) != 1 || data[0] > 1 {
+			return fmt.Errorf("invalid bool")
+		}
+		v.SetBool(data[0] == 1)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, n := binary.Varint(data)
+		if n <= 0 || n != len(data) || n != len(appendVarint(nil, x)) || v.OverflowInt(x) {
+			return fmt.Errorf("invalid integer")
+		}
+		v.SetInt(x)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, err := r.uvarint()
+		if err != nil || v.OverflowUint(x) {
+			return fmt.Errorf("invalid integer")
+		}
+		v.SetUint(x)
+		return r.done()
+	case reflect.String:
+		v.SetString(string(data))
+		return nil
+	case reflect.Ptr:
+		if len(data) == 0 || data[0] > 1 || (data[0] == 0 && len(data) != 1) {
+			return fmt.Errorf("invalid pointer")
+		}
+		if data[0] == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		elem := reflect.New(v.Type().Elem())
+		err := decodeValue(data[1:], elem.Elem())
+		if err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Kind() == reflect.Array {
+				if len(data) != v.Len() {
+					return fmt.Errorf("invalid array length")
+				}
+				reflect.Copy(v, reflect.ValueOf(data))
+				return nil
+			}
+			v.SetBytes(append([]byte{}, data...))
+			return nil
+		}
+		n, err := r.count()
+		if err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Array {
+			if n != v.Len() {
+				return fmt.Errorf("invalid array length")
+			}
+		} else {
+			v.Set(reflect.MakeSlice(v.Type(), n, n))
+		}
+		for i := 0; i < n; i++ {
+			elem, err := r.next()
+			if err != nil {
+				return err
+			}
+			err = decodeValue(elem, v.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		return r.done()
+	case reflect.Map:
+		n, err := r.count()
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n)
+		var last []byte
+		for i := 0; i < n; i++ {
+			key, err := r.next()
+			if err != nil {
+				return err
+			}
+			if i > 0 && bytes.Compare(last, key) >= 0 {
+				return fmt.Errorf("map keys not in canonical order")
+			}
+			last = key
+			value, err := r.next()
+			if err != nil {
+				return err
+			}
+			k := reflect.New(v.Type().Key()).Elem()
+			if err = decodeValue(key, k); err != nil {
+				return err
+			}
+			e := reflect.New(v.Type().Elem()).Elem()
+			if err = decodeValue(value, e); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+		return r.done()
+	case reflect.Struct:
+		fields := structFields(v.Type())
+		known := make(map[uint64]int, len(fields))
+		for _, i := range fields {
+			known[uint64(i+1)] = i
+		}
+		var last uint64
+		for len(r.buf) > 0 {
+			tag, err := r.uvarint()
+			if err != nil {
+				return err
+			}
+			i, ok := known[tag]
+			if !ok {
+				return fmt.Errorf("unknown field %d in %s", tag, v.Type())
+			}
+			if tag <= last {
+				return fmt.Errorf("fields of %s not in order", v.Type())
+			}
+			last = tag
+			value, err := r.next()
+			if err != nil {
+				return err
+			}
+			err = decodeValue(value, v.Field(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("wire type %s not supported", v.Type())
+}
+
+func (r *wireReader) done() error {
+	if len(r.buf) != 0 {
+		return fmt.Errorf("trailing data after value")
+	}
+	return nil
+}
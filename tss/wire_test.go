@@ -0,0 +1,114 @@
+package tss
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/edwards/v2"
+	"github.com/decred/dcrd/dcrec/secp256k1/v2"
+	"github.com/okx/threshold-lib/crypto/curves"
+	"github.com/okx/threshold-lib/crypto/schnorr"
+	"github.com/okx/threshold-lib/crypto/vss"
+	"github.com/okx/threshold-lib/crypto/zkp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWireRoundTrip(t *testing.T) {
+	c := big.NewInt(-12345)
+	step1 := &KeyStep1Data{C: &c}
+	data, err := Marshal(step1)
+	require.NoError(t, err)
+	var step1Out KeyStep1Data
+	require.NoError(t, Unmarshal(data, &step1Out))
+	require.Equal(t, 0, (*step1Out.C).Cmp(c))
+
+	x := big.NewInt(7)
+	X := curves.ScalarToPoint(secp256k1.S256(), x)
+	proof, err := schnorr.Prove(x, X)
+	require.NoError(t, err)
+	step2 := &KeyStep2Data{
+		Witness: &[]*big.Int{big.NewInt(0), big.NewInt(1 << 40)},
+		Share:   &vss.Share{Id: big.NewInt(2), Y: big.NewInt(99)},
+		Proof:   proof,
+	}
+	data, err = Marshal(step2)
+	require.NoError(t, err)
+	step2Out := new(KeyStep2Data)
+	require.NoError(t, UnmarshalData(data, step2Out))
+	require.Equal(t, 0, (*step2Out.Witness)[1].Cmp(big.NewInt(1<<40)))
+	require.Equal(t, 0, step2Out.Share.Y.Cmp(big.NewInt(99)))
+	require.True(t, schnorr.Verify(step2Out.Proof, X))
+	again, err := Marshal(step2Out)
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	ed := curves.ScalarToPoint(edwards.Edwards(), x)
+	step3 := &KeyStep3Data{Id: 3, ShareI: x, PublicKey: ed, ChainCode: "abc", SharePubKeyMap: map[int]*curves.ECPoint{1: ed, 2: ed, 10: ed}}
+	data, err = Marshal(step3)
+	require.NoError(t, err)
+	var step3Out KeyStep3Data
+	require.NoError(t, Unmarshal(data, &step3Out))
+	require.Equal(t, 3, step3Out.Id)
+	require.Equal(t, "abc", step3Out.ChainCode)
+	require.Len(t, step3Out.SharePubKeyMap, 3)
+	require.True(t, step3Out.SharePubKeyMap[10].Equals(ed))
+	require.Equal(t, edwards.Edwards(), step3Out.PublicKey.Curve)
+
+	affG := &zkp.AffGProof{A: big.NewInt(1), E: big.NewInt(2), Bx: X, Y: X}
+	data, err = Marshal(affG)
+	require.NoError(t, err)
+	var affGOut zkp.AffGProof
+	require.NoError(t, Unmarshal(data, &affGOut))
+	require.Equal(t, 0, affGOut.E.Cmp(big.NewInt(2)))
+	require.Nil(t, affGOut.W)
+	require.True(t, affGOut.Bx.Equals(X))
+	jsonData, err := json.Marshal(affG)
+	require.NoError(t, err)
+	require.Less(t, len(data), len(jsonData))
+}
+
+func TestWireStrict(t *testing.T) {
+	step := &KeyStep3Data{Id: 1, ShareI: big.NewInt(5), ChainCode: "c"}
+	data, err := Marshal(step)
+	require.NoError(t, err)
+	var out KeyStep3Data
+
+	// trailing data
+	require.Error(t, Unmarshal(append(append([]byte{}, data...), 0), &out))
+	// unknown field
+	require.Error(t, Unmarshal(append(append([]byte{}, data...), 9, 0), &out))
+	// wrong type
+	var other KeyStep1Data
+	require.Error(t, Unmarshal(data, &other))
+	// wrong version
+	tampered := append([]byte{}, data...)
+	tampered[len(wireMagic)] = WireVersion + 1
+	require.Error(t, Unmarshal(tampered, &out))
+	// truncated
+	require.Error(t, Unmarshal(data[:len(data)-1], &out))
+
+	// json is strict too
+	require.NoError(t, UnmarshalData([]byte(`{"Id":1,"ChainCode":"c"}`), &out))
+	require.Error(t, UnmarshalData([]byte(`{"Id":1,"Unknown":2}`), &out))
+	require.Error(t, UnmarshalData([]byte(`{"Id":1}{}`), &out))
+
+	data, err = MarshalData(WireBinary, step)
+	require.NoError(t, err)
+	require.NoError(t, UnmarshalData(data, &out))
+	require.Equal(t, "c", out.ChainCode)
+	_, err = MarshalData(WireFormat(5), step)
+	require.Error(t, err)
+
+	// the format belongs to the run
+	envelope := &Envelope{Protocol: "dkg", SessionId: "s"}
+	require.Error(t, envelope.SetWireFormat(WireFormat(5)))
+	require.NoError(t, envelope.SetWireFormat(WireBinary))
+	data, err = envelope.MarshalData(step)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(data, []byte(wireMagic)))
+	data, err = (&Envelope{Protocol: "dkg", SessionId: "s"}).MarshalData(step)
+	require.NoError(t, err)
+	require.Equal(t, byte('{'), data[0])
+}